@@ -209,6 +209,14 @@ type ValidatePathMeta struct {
 	ErrorResponseCode int `bson:"error_response_code" json:"error_response_code"`
 }
 
+// ScopeMeta names the OAuth/OIDC scopes a client must present to reach an
+// endpoint, on top of whatever policy the session already carries.
+type ScopeMeta struct {
+	Path   string   `bson:"path" json:"path"`
+	Method string   `bson:"method" json:"method"`
+	Scopes []string `bson:"scopes" json:"scopes"`
+}
+
 type ExtendedPathsSet struct {
 	Ignored                 []EndPointMeta        `bson:"ignored" json:"ignored,omitempty"`
 	WhiteList               []EndPointMeta        `bson:"white_list" json:"white_list,omitempty"`
@@ -231,6 +239,8 @@ type ExtendedPathsSet struct {
 	DoNotTrackEndpoints     []TrackEndpointMeta   `bson:"do_not_track_endpoints" json:"do_not_track_endpoints,omitempty"`
 	ValidateJSON            []ValidatePathMeta    `bson:"validate_json" json:"validate_json,omitempty"`
 	Internal                []InternalMeta        `bson:"internal" json:"internal,omitempty"`
+	RequiredScopes          []ScopeMeta           `bson:"required_scopes" json:"required_scopes,omitempty"`
+	BypassCoProcess         []TrackEndpointMeta   `bson:"bypass_coprocess" json:"bypass_coprocess,omitempty"`
 }
 
 type VersionInfo struct {
@@ -279,6 +289,53 @@ type MiddlewareDefinition struct {
 	Path           string `bson:"path" json:"path"`
 	RequireSession bool   `bson:"require_session" json:"require_session"`
 	RawBodyOnly    bool   `bson:"raw_body_only" json:"raw_body_only"`
+
+	// EnableBodyTransformCache caches the hook's transformed body, keyed on a hash of
+	// the original request body, so identical bodies skip re-dispatching to the hook.
+	// It must be left disabled when the transform depends on anything other than the
+	// body (headers, query params, session, etc.), since those aren't part of the key.
+	EnableBodyTransformCache bool `bson:"enable_body_transform_cache" json:"enable_body_transform_cache"`
+	// BodyTransformCacheTTL is the cache entry lifetime, in seconds. Defaults to 60 when unset.
+	BodyTransformCacheTTL int64 `bson:"body_transform_cache_ttl" json:"body_transform_cache_ttl"`
+
+	// MaxBufferedBodyBytes caps how much of the request body the gateway will
+	// buffer in memory to hand to this hook. Bodies at or under the limit are
+	// read fully, as before, so the hook sees the complete body. Bodies over
+	// the limit - including chunked bodies with no advertised length, once
+	// the limit is crossed mid-read - are left unbuffered and streamed
+	// straight through to the upstream; the hook still runs, but sees an
+	// empty body rather than paying to hold a large payload in memory twice.
+	// Zero (the default) always buffers the full body, matching prior
+	// behaviour.
+	MaxBufferedBodyBytes int64 `bson:"max_buffered_body_bytes" json:"max_buffered_body_bytes"`
+
+	// MaxRequestBodySize, unlike MaxBufferedBodyBytes, rejects the request
+	// outright with a 413 as soon as its body is known to exceed the limit -
+	// before any of it is buffered for the hook - rather than letting it
+	// through with an empty body. Checked against Content-Length first where
+	// present, and against bytes actually read otherwise (so a chunked body
+	// with no advertised length is still caught the moment it crosses the
+	// limit). Zero (the default) never rejects on size.
+	MaxRequestBodySize int64 `bson:"max_request_body_size" json:"max_request_body_size"`
+
+	// HookTimeoutSeconds overrides config.CoProcessConfig.HookTimeout for
+	// this specific hook. Zero uses the gateway-wide default.
+	HookTimeoutSeconds int64 `bson:"hook_timeout_seconds" json:"hook_timeout_seconds"`
+}
+
+// JWTIntrospectionOptions configures how tyk.introspect() validates opaque
+// tokens against an external IdP's introspection endpoint (RFC 7662).
+type JWTIntrospectionOptions struct {
+	Enabled      bool   `bson:"enabled" json:"enabled"`
+	URL          string `bson:"url" json:"url"`
+	ClientID     string `bson:"client_id" json:"client_id"`
+	ClientSecret string `bson:"client_secret" json:"client_secret"`
+	// CacheTTL bounds how long an introspection result is cached, in seconds,
+	// when the IdP response doesn't carry its own expiry.
+	CacheTTL int64 `bson:"cache_ttl" json:"cache_ttl"`
+	// FailOpen allows a request through when the IdP is unreachable, instead of
+	// the default fail-closed (reject) behaviour.
+	FailOpen bool `bson:"fail_open" json:"fail_open"`
 }
 
 type MiddlewareIdExtractor struct {
@@ -293,9 +350,21 @@ type MiddlewareSection struct {
 	Post        []MiddlewareDefinition `bson:"post" json:"post"`
 	PostKeyAuth []MiddlewareDefinition `bson:"post_key_auth" json:"post_key_auth"`
 	AuthCheck   MiddlewareDefinition   `bson:"auth_check" json:"auth_check"`
-	Response    []MiddlewareDefinition `bson:"response" json:"response"`
-	Driver      MiddlewareDriver       `bson:"driver" json:"driver"`
-	IdExtractor MiddlewareIdExtractor  `bson:"id_extractor" json:"id_extractor"`
+	// AuthCheckHooks lets multiple auth hooks be chained for multi-factor
+	// auth, instead of the single AuthCheck hook. When non-empty, it takes
+	// precedence over AuthCheck entirely. The hooks run in order; whether all
+	// of them must succeed or just one is controlled by AuthCheckHooksPolicy.
+	AuthCheckHooks []MiddlewareDefinition `bson:"auth_check_hooks" json:"auth_check_hooks"`
+	// AuthCheckHooksPolicy is "and" (every hook in AuthCheckHooks must
+	// succeed, short-circuiting - and returning that hook's failure - on the
+	// first one that doesn't) or "or" (any hook succeeding is enough,
+	// short-circuiting on the first success; a hook that fails doesn't stop
+	// the next one from running). Defaults to "and" when AuthCheckHooks is
+	// set but this is empty.
+	AuthCheckHooksPolicy string                 `bson:"auth_check_hooks_policy" json:"auth_check_hooks_policy"`
+	Response             []MiddlewareDefinition `bson:"response" json:"response"`
+	Driver               MiddlewareDriver       `bson:"driver" json:"driver"`
+	IdExtractor          MiddlewareIdExtractor  `bson:"id_extractor" json:"id_extractor"`
 }
 
 type CacheOptions struct {
@@ -372,6 +441,13 @@ type APIDefinition struct {
 		AllowedAccessTypes     []osin.AccessRequestType    `bson:"allowed_access_types" json:"allowed_access_types"`
 		AllowedAuthorizeTypes  []osin.AuthorizeRequestType `bson:"allowed_authorize_types" json:"allowed_authorize_types"`
 		AuthorizeLoginRedirect string                      `bson:"auth_login_redirect" json:"auth_login_redirect"`
+		// AuthorizeHook, when Name is set, is a Go plugin symbol invoked
+		// before Tyk generates an authorization code. It can inspect and
+		// rewrite the authorize request's form values (e.g. to enforce
+		// PKCE, inject custom params) or reject the request outright. It
+		// cannot weaken config.Global().OauthRequirePKCE - that's enforced
+		// on the result regardless of what the hook does.
+		AuthorizeHook MiddlewareDefinition `bson:"authorize_hook" json:"authorize_hook"`
 	} `bson:"oauth_meta" json:"oauth_meta"`
 	Auth         AuthConfig            `bson:"auth" json:"auth"` // Deprecated: Use AuthConfigs instead.
 	AuthConfigs  map[string]AuthConfig `bson:"auth_configs" json:"auth_configs"`
@@ -383,36 +459,50 @@ type APIDefinition struct {
 		BodyUserRegexp     string `bson:"body_user_regexp" json:"body_user_regexp"`
 		BodyPasswordRegexp string `bson:"body_password_regexp" json:"body_password_regexp"`
 	} `bson:"basic_auth" json:"basic_auth"`
-	UseMutualTLSAuth           bool                 `bson:"use_mutual_tls_auth" json:"use_mutual_tls_auth"`
-	ClientCertificates         []string             `bson:"client_certificates" json:"client_certificates"`
-	UpstreamCertificates       map[string]string    `bson:"upstream_certificates" json:"upstream_certificates"`
-	PinnedPublicKeys           map[string]string    `bson:"pinned_public_keys" json:"pinned_public_keys"`
-	EnableJWT                  bool                 `bson:"enable_jwt" json:"enable_jwt"`
-	UseStandardAuth            bool                 `bson:"use_standard_auth" json:"use_standard_auth"`
-	UseGoPluginAuth            bool                 `bson:"use_go_plugin_auth" json:"use_go_plugin_auth"`
-	EnableCoProcessAuth        bool                 `bson:"enable_coprocess_auth" json:"enable_coprocess_auth"`
-	JWTSigningMethod           string               `bson:"jwt_signing_method" json:"jwt_signing_method"`
-	JWTSource                  string               `bson:"jwt_source" json:"jwt_source"`
-	JWTIdentityBaseField       string               `bson:"jwt_identit_base_field" json:"jwt_identity_base_field"`
-	JWTClientIDBaseField       string               `bson:"jwt_client_base_field" json:"jwt_client_base_field"`
-	JWTPolicyFieldName         string               `bson:"jwt_policy_field_name" json:"jwt_policy_field_name"`
-	JWTDefaultPolicies         []string             `bson:"jwt_default_policies" json:"jwt_default_policies"`
-	JWTIssuedAtValidationSkew  uint64               `bson:"jwt_issued_at_validation_skew" json:"jwt_issued_at_validation_skew"`
-	JWTExpiresAtValidationSkew uint64               `bson:"jwt_expires_at_validation_skew" json:"jwt_expires_at_validation_skew"`
-	JWTNotBeforeValidationSkew uint64               `bson:"jwt_not_before_validation_skew" json:"jwt_not_before_validation_skew"`
-	JWTSkipKid                 bool                 `bson:"jwt_skip_kid" json:"jwt_skip_kid"`
-	JWTScopeToPolicyMapping    map[string]string    `bson:"jwt_scope_to_policy_mapping" json:"jwt_scope_to_policy_mapping"`
-	JWTScopeClaimName          string               `bson:"jwt_scope_claim_name" json:"jwt_scope_claim_name"`
-	NotificationsDetails       NotificationsManager `bson:"notifications" json:"notifications"`
-	EnableSignatureChecking    bool                 `bson:"enable_signature_checking" json:"enable_signature_checking"`
-	HmacAllowedClockSkew       float64              `bson:"hmac_allowed_clock_skew" json:"hmac_allowed_clock_skew"`
-	HmacAllowedAlgorithms      []string             `bson:"hmac_allowed_algorithms" json:"hmac_allowed_algorithms"`
-	RequestSigning             RequestSigningMeta   `bson:"request_signing" json:"request_signing"`
-	BaseIdentityProvidedBy     AuthTypeEnum         `bson:"base_identity_provided_by" json:"base_identity_provided_by"`
-	VersionDefinition          struct {
-		Location  string `bson:"location" json:"location"`
-		Key       string `bson:"key" json:"key"`
-		StripPath bool   `bson:"strip_path" json:"strip_path"`
+	UseMutualTLSAuth           bool                    `bson:"use_mutual_tls_auth" json:"use_mutual_tls_auth"`
+	ClientCertificates         []string                `bson:"client_certificates" json:"client_certificates"`
+	UpstreamCertificates       map[string]string       `bson:"upstream_certificates" json:"upstream_certificates"`
+	PinnedPublicKeys           map[string]string       `bson:"pinned_public_keys" json:"pinned_public_keys"`
+	EnableJWT                  bool                    `bson:"enable_jwt" json:"enable_jwt"`
+	UseStandardAuth            bool                    `bson:"use_standard_auth" json:"use_standard_auth"`
+	UseGoPluginAuth            bool                    `bson:"use_go_plugin_auth" json:"use_go_plugin_auth"`
+	EnableCoProcessAuth        bool                    `bson:"enable_coprocess_auth" json:"enable_coprocess_auth"`
+	JWTSigningMethod           string                  `bson:"jwt_signing_method" json:"jwt_signing_method"`
+	JWTSource                  string                  `bson:"jwt_source" json:"jwt_source"`
+	JWTIdentityBaseField       string                  `bson:"jwt_identit_base_field" json:"jwt_identity_base_field"`
+	JWTClientIDBaseField       string                  `bson:"jwt_client_base_field" json:"jwt_client_base_field"`
+	JWTPolicyFieldName         string                  `bson:"jwt_policy_field_name" json:"jwt_policy_field_name"`
+	JWTDefaultPolicies         []string                `bson:"jwt_default_policies" json:"jwt_default_policies"`
+	JWTIssuedAtValidationSkew  uint64                  `bson:"jwt_issued_at_validation_skew" json:"jwt_issued_at_validation_skew"`
+	JWTExpiresAtValidationSkew uint64                  `bson:"jwt_expires_at_validation_skew" json:"jwt_expires_at_validation_skew"`
+	JWTNotBeforeValidationSkew uint64                  `bson:"jwt_not_before_validation_skew" json:"jwt_not_before_validation_skew"`
+	JWTSkipKid                 bool                    `bson:"jwt_skip_kid" json:"jwt_skip_kid"`
+	JWTScopeToPolicyMapping    map[string]string       `bson:"jwt_scope_to_policy_mapping" json:"jwt_scope_to_policy_mapping"`
+	JWTScopeClaimName          string                  `bson:"jwt_scope_claim_name" json:"jwt_scope_claim_name"`
+	JWTIntrospection           JWTIntrospectionOptions `bson:"jwt_introspection" json:"jwt_introspection"`
+	// ScopeValidatorHook, when Name is set, is a Go plugin symbol invoked
+	// instead of the default "presented scopes must be a superset of
+	// required scopes" check for any endpoint with ExtendedPaths.RequiredScopes
+	// configured. It can only narrow or grant access within the endpoint's
+	// configured required scopes - it never sees or can add scopes the
+	// policy didn't already require. Loaded once per API the same way
+	// Oauth2Meta.AuthorizeHook is.
+	ScopeValidatorHook      MiddlewareDefinition `bson:"scope_validator_hook" json:"scope_validator_hook"`
+	NotificationsDetails    NotificationsManager `bson:"notifications" json:"notifications"`
+	EnableSignatureChecking bool                 `bson:"enable_signature_checking" json:"enable_signature_checking"`
+	HmacAllowedClockSkew    float64              `bson:"hmac_allowed_clock_skew" json:"hmac_allowed_clock_skew"`
+	HmacAllowedAlgorithms   []string             `bson:"hmac_allowed_algorithms" json:"hmac_allowed_algorithms"`
+	RequestSigning          RequestSigningMeta   `bson:"request_signing" json:"request_signing"`
+	BaseIdentityProvidedBy  AuthTypeEnum         `bson:"base_identity_provided_by" json:"base_identity_provided_by"`
+	VersionDefinition       struct {
+		Location string `bson:"location" json:"location"`
+		Key      string `bson:"key" json:"key"`
+		// FallbackToDefault makes version resolution use VersionData.DefaultVersion
+		// when Location/Key resolve to a value that isn't a configured version,
+		// instead of rejecting the request. It has no effect when the value is
+		// simply absent - that already falls back to DefaultVersion.
+		FallbackToDefault bool `bson:"fallback_to_default" json:"fallback_to_default"`
+		StripPath         bool `bson:"strip_path" json:"strip_path"`
 	} `bson:"definition" json:"definition"`
 	VersionData struct {
 		NotVersioned   bool                   `bson:"not_versioned" json:"not_versioned"`
@@ -445,11 +535,29 @@ type APIDefinition struct {
 			SSLForceCommonNameCheck bool     `json:"ssl_force_common_name_check"`
 			ProxyURL                string   `bson:"proxy_url" json:"proxy_url"`
 		} `bson:"transport" json:"transport"`
+		// BasicAuth holds credentials the gateway presents to the upstream
+		// itself (as opposed to the API's own client-facing auth). Only its
+		// presence, never the secret, is ever surfaced to hooks.
+		BasicAuth struct {
+			Enabled  bool   `bson:"enabled" json:"enabled"`
+			Username string `bson:"username" json:"username"`
+			Password string `bson:"password" json:"password"`
+		} `bson:"basic_auth" json:"basic_auth"`
 	} `bson:"proxy" json:"proxy"`
-	DisableRateLimit          bool                   `bson:"disable_rate_limit" json:"disable_rate_limit"`
-	DisableQuota              bool                   `bson:"disable_quota" json:"disable_quota"`
-	CustomMiddleware          MiddlewareSection      `bson:"custom_middleware" json:"custom_middleware"`
-	CustomMiddlewareBundle    string                 `bson:"custom_middleware_bundle" json:"custom_middleware_bundle"`
+	DisableRateLimit bool `bson:"disable_rate_limit" json:"disable_rate_limit"`
+	DisableQuota     bool `bson:"disable_quota" json:"disable_quota"`
+	// RejectOnQuotaCostOverflow controls what happens when a post hook's
+	// tyk.set_quota_cost() declares a cost that would take the session's
+	// quota negative: true rejects the request before it reaches the
+	// upstream, false (the default) lets it through and the quota goes
+	// negative until the next renewal.
+	RejectOnQuotaCostOverflow bool              `bson:"reject_on_quota_cost_overflow" json:"reject_on_quota_cost_overflow"`
+	CustomMiddleware          MiddlewareSection `bson:"custom_middleware" json:"custom_middleware"`
+	CustomMiddlewareBundle    string            `bson:"custom_middleware_bundle" json:"custom_middleware_bundle"`
+	// CompositionRoutes comes from the bundle manifest's "composition"
+	// section (see BundleManifest) and is populated onto the spec the same
+	// way CustomMiddleware is. See CompositionRoute for the route schema.
+	CompositionRoutes         []CompositionRoute     `bson:"-" json:"-"`
 	CacheOptions              CacheOptions           `bson:"cache_options" json:"cache_options"`
 	SessionLifetime           int64                  `bson:"session_lifetime" json:"session_lifetime"`
 	Active                    bool                   `bson:"active" json:"active"`
@@ -458,14 +566,19 @@ type APIDefinition struct {
 	SessionProvider           SessionProviderMeta    `bson:"session_provider" json:"session_provider"`
 	EventHandlers             EventHandlerMetaConfig `bson:"event_handlers" json:"event_handlers"`
 	EnableBatchRequestSupport bool                   `bson:"enable_batch_request_support" json:"enable_batch_request_support"`
-	EnableIpWhiteListing      bool                   `mapstructure:"enable_ip_whitelisting" bson:"enable_ip_whitelisting" json:"enable_ip_whitelisting"`
-	AllowedIPs                []string               `mapstructure:"allowed_ips" bson:"allowed_ips" json:"allowed_ips"`
-	EnableIpBlacklisting      bool                   `mapstructure:"enable_ip_blacklisting" bson:"enable_ip_blacklisting" json:"enable_ip_blacklisting"`
-	BlacklistedIPs            []string               `mapstructure:"blacklisted_ips" bson:"blacklisted_ips" json:"blacklisted_ips"`
-	DontSetQuotasOnCreate     bool                   `mapstructure:"dont_set_quota_on_create" bson:"dont_set_quota_on_create" json:"dont_set_quota_on_create"`
-	ExpireAnalyticsAfter      int64                  `mapstructure:"expire_analytics_after" bson:"expire_analytics_after" json:"expire_analytics_after"` // must have an expireAt TTL index set (http://docs.mongodb.org/manual/tutorial/expire-data/)
-	ResponseProcessors        []ResponseProcessor    `bson:"response_processors" json:"response_processors"`
-	CORS                      struct {
+	// EnableStreamingFanoutSupport exposes /tyk/stream-batch/ alongside
+	// /tyk/batch/, for callers that want each upstream's reply streamed down
+	// as a newline-delimited JSON object the moment it arrives, rather than
+	// waiting for the slowest upstream before responding at all.
+	EnableStreamingFanoutSupport bool                `bson:"enable_streaming_fanout_support" json:"enable_streaming_fanout_support"`
+	EnableIpWhiteListing         bool                `mapstructure:"enable_ip_whitelisting" bson:"enable_ip_whitelisting" json:"enable_ip_whitelisting"`
+	AllowedIPs                   []string            `mapstructure:"allowed_ips" bson:"allowed_ips" json:"allowed_ips"`
+	EnableIpBlacklisting         bool                `mapstructure:"enable_ip_blacklisting" bson:"enable_ip_blacklisting" json:"enable_ip_blacklisting"`
+	BlacklistedIPs               []string            `mapstructure:"blacklisted_ips" bson:"blacklisted_ips" json:"blacklisted_ips"`
+	DontSetQuotasOnCreate        bool                `mapstructure:"dont_set_quota_on_create" bson:"dont_set_quota_on_create" json:"dont_set_quota_on_create"`
+	ExpireAnalyticsAfter         int64               `mapstructure:"expire_analytics_after" bson:"expire_analytics_after" json:"expire_analytics_after"` // must have an expireAt TTL index set (http://docs.mongodb.org/manual/tutorial/expire-data/)
+	ResponseProcessors           []ResponseProcessor `bson:"response_processors" json:"response_processors"`
+	CORS                         struct {
 		Enable             bool     `bson:"enable" json:"enable"`
 		AllowedOrigins     []string `bson:"allowed_origins" json:"allowed_origins"`
 		AllowedMethods     []string `bson:"allowed_methods" json:"allowed_methods"`
@@ -487,6 +600,21 @@ type APIDefinition struct {
 	StripAuthData           bool                   `bson:"strip_auth_data" json:"strip_auth_data"`
 	EnableDetailedRecording bool                   `bson:"enable_detailed_recording" json:"enable_detailed_recording"`
 	GraphQL                 GraphQLConfig          `bson:"graphql" json:"graphql"`
+	// URLSigning turns on gateway-side verification of pre-signed URLs (as
+	// produced by the tyk.presign() coprocess helper) for APIs that hand out
+	// time-limited links. KeyRef names the secret in the gateway's secrets
+	// store used to both sign and verify; it is never itself exposed to hooks.
+	URLSigning struct {
+		Enabled bool   `bson:"enabled" json:"enabled"`
+		KeyRef  string `bson:"key_ref" json:"key_ref"`
+	} `bson:"url_signing" json:"url_signing"`
+	// ChaosEngineering must be explicitly enabled before a pre hook's
+	// return_overrides.inject_delay_ms/inject_fault are honoured, so chaos
+	// testing set up on one API can never leak latency or faults into
+	// another that hasn't opted in.
+	ChaosEngineering struct {
+		Enabled bool `bson:"enabled" json:"enabled"`
+	} `bson:"chaos_engineering" json:"chaos_engineering"`
 }
 
 type AuthConfig struct {
@@ -519,6 +647,85 @@ type BundleManifest struct {
 	CustomMiddleware MiddlewareSection `bson:"custom_middleware" json:"custom_middleware"`
 	Checksum         string            `bson:"checksum" json:"checksum"`
 	Signature        string            `bson:"signature" json:"signature"`
+	// Composition declares client-facing endpoints that fan out to one or
+	// more upstream calls and merge the results, without needing a custom
+	// middleware hook to drive the fan-out per request. See CompositionRoute.
+	Composition []CompositionRoute `bson:"composition" json:"composition"`
+}
+
+// CompositionMergeStrategy controls how a CompositionRoute's step responses
+// are combined into the single response sent to the client.
+type CompositionMergeStrategy string
+
+const (
+	// CompositionMergeObject merges every step's JSON response body into a
+	// single JSON object, keyed by each call's Name. This is the default.
+	CompositionMergeObject CompositionMergeStrategy = "merge_object"
+	// CompositionMergeArray collects every step's response body into a JSON
+	// array, in step declaration order. A body that parses as JSON is
+	// embedded as that JSON value; any other body is embedded as a string.
+	CompositionMergeArray CompositionMergeStrategy = "array"
+)
+
+// CompositionFailurePolicy controls what happens when one of a
+// CompositionRoute's calls fails (a status of 400 or above, or a transport
+// error).
+type CompositionFailurePolicy string
+
+const (
+	// CompositionFailFast aborts the whole route and returns an error to the
+	// client as soon as any call fails. This is the default.
+	CompositionFailFast CompositionFailurePolicy = "fail_fast"
+	// CompositionBestEffort keeps going, merging whatever calls succeeded
+	// and recording the failure under the failing call's Name instead of
+	// its response.
+	CompositionBestEffort CompositionFailurePolicy = "best_effort"
+)
+
+// CompositionRoute maps a single client-facing endpoint to one or more
+// upstream calls, executed in declared Stages, and merged into one
+// response per Merge. Validated at load time: Path, Method and at least one
+// Stage with at least one Call are required, and Merge/OnFailure (if set)
+// must be one of the recognised values.
+type CompositionRoute struct {
+	// Path is the client-facing path this route answers, relative to the
+	// API's listen path (e.g. "/dashboard").
+	Path string `bson:"path" json:"path"`
+	// Method is the HTTP method this route answers, e.g. "GET".
+	Method string `bson:"method" json:"method"`
+	// Stages run in order; every Call within a Stage runs concurrently. Use
+	// multiple Stages for calls that depend on each other sequentially, and
+	// multiple Calls within one Stage for calls that can run in parallel.
+	Stages []CompositionStage `bson:"stages" json:"stages"`
+	// Merge selects how step responses become the client response. Defaults
+	// to CompositionMergeObject.
+	Merge CompositionMergeStrategy `bson:"merge" json:"merge"`
+	// OnFailure selects what happens when a call fails. Defaults to
+	// CompositionFailFast.
+	OnFailure CompositionFailurePolicy `bson:"on_failure" json:"on_failure"`
+}
+
+// CompositionStage is one sequential step of a CompositionRoute; every Call
+// in it is issued concurrently and the stage completes once they all have.
+type CompositionStage struct {
+	Calls []CompositionCall `bson:"calls" json:"calls"`
+}
+
+// CompositionCall is a single internal, in-process request to another
+// loaded API, dispatched the same way makeInternalAPIRequest is (no network
+// round trip, no client-visible redirect).
+type CompositionCall struct {
+	// Name identifies this call's response in the merged result - the key
+	// under CompositionMergeObject, or just a label for logging/error
+	// reporting under CompositionMergeArray.
+	Name string `bson:"name" json:"name"`
+	// APIID is the target API's ID or name, resolved the same way
+	// makeInternalAPIRequest resolves it.
+	APIID string `bson:"api_id" json:"api_id"`
+	// Path is the request path on the target API.
+	Path string `bson:"path" json:"path"`
+	// Method is the request method, e.g. "GET".
+	Method string `bson:"method" json:"method"`
 }
 
 type RequestSigningMeta struct {
@@ -137,6 +137,20 @@ type DnsCacheConfig struct {
 	MultipleIPsHandleStrategy IPsHandleStrategy `json:"multiple_ips_handle_strategy"`
 }
 
+// TenantFairnessConfig controls the weighted fair queuing admission check
+// tenantFairnessAdmit performs on behalf of a Pre hook. Weights default to 1
+// for any tenant (by OrgID) not listed in Weights, so an unweighted gateway
+// gives every tenant an equal share. WindowSeconds and OverageMultiplier
+// both default (see DefaultTenantFairnessWindowSeconds/
+// DefaultTenantFairnessOverageMultiplier) when left at zero, so a bare
+// `"enabled": true` is a usable configuration.
+type TenantFairnessConfig struct {
+	Enabled           bool               `json:"enabled"`
+	WindowSeconds     int64              `json:"window_seconds"`
+	Weights           map[string]float64 `json:"weights"`
+	OverageMultiplier float64            `json:"overage_multiplier"`
+}
+
 type MonitorConfig struct {
 	EnableTriggerMonitors bool               `json:"enable_trigger_monitors"`
 	Config                WebHookHandlerConf `json:"configuration"`
@@ -225,6 +239,101 @@ type CoProcessConfig struct {
 	GRPCSendMaxSize     int    `json:"grpc_send_max_size"`
 	PythonPathPrefix    string `json:"python_path_prefix"`
 	PythonVersion       string `json:"python_version"`
+	// ShutdownGracePeriod is how long, in seconds, the coprocess dispatcher keeps
+	// draining in-flight hook dispatches after the gateway starts a graceful
+	// shutdown, before it starts refusing new ones outright.
+	ShutdownGracePeriod int64 `json:"shutdown_grace_period"`
+	// RequestBatchWindowMs bounds how long tyk.batch_request() holds a hook's
+	// call open waiting for other requests to join the same batch group,
+	// before flushing whatever has accumulated.
+	RequestBatchWindowMs int64 `json:"request_batch_window_ms"`
+	// RequestBatchMaxSize caps how many requests are sent to the upstream in
+	// a single batch, regardless of how many arrive within the window.
+	RequestBatchMaxSize int `json:"request_batch_max_size"`
+	// NotFoundHookDriver and NotFoundHookName configure a gateway-level hook
+	// for requests that don't match any API, so operators can return a
+	// branded 404 or redirect to a fallback instead of the gateway's plain
+	// text response. This is a single, process-wide hook, distinct from the
+	// per-API hooks configured via custom_middleware: it has no API spec or
+	// bundle to run against, since by definition nothing matched. Leave
+	// NotFoundHookName empty to use the gateway's default 404 behaviour.
+	NotFoundHookDriver apidef.MiddlewareDriver `json:"not_found_hook_driver"`
+	NotFoundHookName   string                  `json:"not_found_hook_name"`
+	// HookTimeout bounds how long a single coprocess hook dispatch (pre, post,
+	// auth_check, or response) is allowed to run, in seconds, before the
+	// gateway aborts it rather than let a hung hook (e.g. a Python hook stuck
+	// in a blocking call) take down throughput for the whole API. Zero
+	// disables the timeout. A bundle's manifest can override this per hook
+	// via custom_middleware's HookTimeoutSeconds.
+	HookTimeout int64 `json:"hook_timeout"`
+	// HookTimeoutStatusCode is the status code returned to the client when a
+	// hook dispatch is aborted for exceeding HookTimeout. Defaults to 504
+	// when unset.
+	HookTimeoutStatusCode int `json:"hook_timeout_status_code"`
+	// PythonWorkerPoolSize sets how many pre-warmed Python dispatcher
+	// instances the gateway keeps around to serve hook dispatches, so a
+	// dispatch borrows a ready instance instead of paying its setup/teardown
+	// cost. Dispatches still run one at a time - the embedded CPython
+	// interpreter is process-wide and every call is serialised on it
+	// regardless of pool size - so this does not let hook calls run
+	// concurrently. A dispatch blocks until a worker is free rather than
+	// erroring when the pool is exhausted. A worker that errors is replaced
+	// and the dispatch retried once before giving up. Zero or one disables
+	// pooling (the previous, single-instance behaviour).
+	PythonWorkerPoolSize int `json:"python_worker_pool_size"`
+	// HealthCheckHookDriver and HealthCheckHookName configure a gateway-level
+	// hook that's dispatched while building the /hello response, so a plugin
+	// can contribute its own sub-status (e.g. a dependency check) alongside
+	// the gateway's built-in redis/dashboard/rpc checks. Like NotFoundHook,
+	// this is a single, process-wide hook with no API spec or bundle to run
+	// against. Leave HealthCheckHookName empty to disable it. The hook is
+	// given HealthCheckHookTimeout seconds to respond; if it doesn't, its
+	// component is reported as failed rather than hanging the endpoint.
+	HealthCheckHookDriver apidef.MiddlewareDriver `json:"health_check_hook_driver"`
+	HealthCheckHookName   string                  `json:"health_check_hook_name"`
+	// HealthCheckHookTimeout bounds how long, in seconds, the gateway waits
+	// for the health-check hook to respond. Defaults to 2 seconds when unset.
+	HealthCheckHookTimeout int64 `json:"health_check_hook_timeout"`
+	// EventPayloadHookDriver and EventPayloadHookName configure a gateway-level
+	// hook that's dispatched just before a system event (currently
+	// KeyExpired) is handed to its configured event handlers, so a plugin can
+	// enrich or reroute what a handler like the webhook notifier actually
+	// sends. Like NotFoundHook, this is a single, process-wide hook, not a
+	// per-API one. Leave EventPayloadHookName empty to disable it. A hook
+	// that isn't configured, isn't loaded, or errors never suppresses the
+	// event - the gateway falls back to the original payload and logs it.
+	EventPayloadHookDriver apidef.MiddlewareDriver `json:"event_payload_hook_driver"`
+	EventPayloadHookName   string                  `json:"event_payload_hook_name"`
+	// CoProcessPingInterval sets how often, in seconds, the gateway checks
+	// the gRPC coprocess connection's health and records the result under
+	// the "coprocess" component of the /hello health-check endpoint. Zero
+	// disables the check. Only meaningful with CoProcessGRPCServer set; the
+	// in-process drivers (Python, Lua) have no separate connection to go
+	// stale. See also RequireCoProcessReady.
+	CoProcessPingInterval int64 `json:"coprocess_ping_interval"`
+	// RequireCoProcessReady makes the /hello endpoint report an overall Fail
+	// status (not just Warn) whenever CoProcessPingInterval's check finds the
+	// coprocess unreachable, so a readiness probe watching that endpoint can
+	// pull the gateway out of rotation instead of routing coprocess-auth
+	// requests to it and getting 500s.
+	RequireCoProcessReady bool `json:"require_coprocess_ready"`
+	// GRPCClientTLS configures mutual TLS for the gateway's gRPC connection
+	// to CoProcessGRPCServer, for deployments running the coprocess as a
+	// separate service rather than in-process. Leave CertFile empty to dial
+	// without a client certificate (or plaintext, if the server doesn't
+	// require TLS at all).
+	GRPCClientTLS GRPCClientTLSConfig `json:"grpc_client_tls"`
+}
+
+// GRPCClientTLSConfig holds the client certificate/key the gateway presents
+// to CoProcessGRPCServer, and the CA/server name used to verify it back.
+// ServerName is only needed when it doesn't match the host portion of
+// CoProcessGRPCServer (e.g. dialing by IP, or through a proxy).
+type GRPCClientTLSConfig struct {
+	CertFile   string `json:"cert_file"`
+	KeyFile    string `json:"key_file"`
+	CAFile     string `json:"ca_file"`
+	ServerName string `json:"server_name"`
 }
 
 type CertificatesConfig struct {
@@ -355,11 +464,22 @@ type Config struct {
 	AuthOverride   AuthOverrideConf   `json:"auth_override"`
 
 	// Rate Limiting Strategy
-	EnableNonTransactionalRateLimiter bool    `json:"enable_non_transactional_rate_limiter"`
-	EnableSentinelRateLimiter         bool    `json:"enable_sentinel_rate_limiter"`
-	EnableRedisRollingLimiter         bool    `json:"enable_redis_rolling_limiter"`
-	DRLNotificationFrequency          int     `json:"drl_notification_frequency"`
-	DRLThreshold                      float64 `json:"drl_threshold"`
+	EnableNonTransactionalRateLimiter bool `json:"enable_non_transactional_rate_limiter"`
+	EnableSentinelRateLimiter         bool `json:"enable_sentinel_rate_limiter"`
+	EnableRedisRollingLimiter         bool `json:"enable_redis_rolling_limiter"`
+	// EnableGCRARateLimiter selects the GCRA (generic cell rate algorithm)
+	// limiter gateway-wide for any session that hasn't set its own
+	// rate_algorithm. It replaces the rolling-window/sentinel/DRL counters
+	// with a single theoretical-arrival-time value per key in Redis, which
+	// enforces the configured rate more evenly across gateway nodes under
+	// concurrent load.
+	EnableGCRARateLimiter    bool    `json:"enable_gcra_rate_limiter"`
+	DRLNotificationFrequency int     `json:"drl_notification_frequency"`
+	DRLThreshold             float64 `json:"drl_threshold"`
+	// MaxHookRetryBudget caps the number of extra upstream retries a pre hook
+	// can request via return_overrides.retry_budget, regardless of what the
+	// hook asks for.
+	MaxHookRetryBudget int `json:"max_hook_retry_budget"`
 
 	// Organization configurations
 	EnforceOrgDataAge               bool          `json:"enforce_org_data_age"`
@@ -396,8 +516,13 @@ type Config struct {
 	OauthTokenExpiredRetainPeriod int32                `json:"oauth_token_expired_retain_period"`
 	OauthRedirectUriSeparator     string               `json:"oauth_redirect_uri_separator"`
 	OauthErrorStatusCode          int                  `json:"oauth_error_status_code"`
-	EnableKeyLogging              bool                 `json:"enable_key_logging"`
-	SSLForceCommonNameCheck       bool                 `json:"ssl_force_common_name_check"`
+	// OauthRequirePKCE, when true, makes every authorize request carry a
+	// non-empty code_challenge regardless of what an AuthorizeHook does -
+	// the hook is consulted for everything else, but can't be used to turn
+	// this requirement off for a given request.
+	OauthRequirePKCE        bool `json:"oauth_require_pkce"`
+	EnableKeyLogging        bool `json:"enable_key_logging"`
+	SSLForceCommonNameCheck bool `json:"ssl_force_common_name_check"`
 
 	// Proxy analytics configuration
 	EnableAnalytics bool                  `json:"enable_analytics"`
@@ -412,6 +537,18 @@ type Config struct {
 	EnableSeperateCacheStore bool                  `json:"enable_separate_cache_store"`
 	CacheStorage             StorageOptionsConf    `json:"cache_storage"`
 
+	// RateLimitStorageBackends maps a backend name an auth hook can request
+	// (via session MetaData) to one of the storage pools this gateway already
+	// maintains: "default" or "cache" (only meaningful when
+	// EnableSeperateCacheStore is also set). An unknown or unconfigured name
+	// falls back to the default rate-limit store.
+	RateLimitStorageBackends map[string]string `json:"rate_limit_storage_backends"`
+
+	// TenantFairness configures the weighted fair queuing admission check
+	// hooks can consult (via the Pre hook spec) to protect against a noisy
+	// tenant starving the others of a shared gateway's capacity.
+	TenantFairness TenantFairnessConfig `json:"tenant_fairness"`
+
 	// Middleware/Plugin Configuration
 	EnableBundleDownloader   bool            `bson:"enable_bundle_downloader" json:"enable_bundle_downloader"`
 	BundleBaseURL            string          `bson:"bundle_base_url" json:"bundle_base_url"`
@@ -467,6 +604,13 @@ type Config struct {
 	// Secrets are key-value pairs that can be accessed in the dashboard via "secrets://"
 	Secrets map[string]string `json:"secrets"`
 
+	// PluginSecretsAllowlist names the secret references (e.g. "secrets://api-key",
+	// "env://ENRICHMENT_KEY") that tyk.get_secret() may resolve for coprocess
+	// plugins. Anything not listed here is refused, so enabling the plugin
+	// secret store doesn't implicitly expose every secret configured on the
+	// gateway to every plugin.
+	PluginSecretsAllowlist []string `bson:"plugin_secrets_allowlist" json:"plugin_secrets_allowlist"`
+
 	// OverrideMessages is used to override returned API error codes and messages.
 	OverrideMessages map[string]TykError `bson:"override_messages" json:"override_messages"`
 }
@@ -30,6 +30,9 @@ const (
 	HookType_PostKeyAuth    HookType = 3
 	HookType_CustomKeyCheck HookType = 4
 	HookType_Response       HookType = 5
+	HookType_NotFound       HookType = 6
+	HookType_HealthCheck    HookType = 7
+	HookType_Event          HookType = 8
 )
 
 var HookType_name = map[int32]string{
@@ -39,6 +42,9 @@ var HookType_name = map[int32]string{
 	3: "PostKeyAuth",
 	4: "CustomKeyCheck",
 	5: "Response",
+	6: "NotFound",
+	7: "HealthCheck",
+	8: "Event",
 }
 
 var HookType_value = map[string]int32{
@@ -48,6 +54,9 @@ var HookType_value = map[string]int32{
 	"PostKeyAuth":    3,
 	"CustomKeyCheck": 4,
 	"Response":       5,
+	"NotFound":       6,
+	"HealthCheck":    7,
+	"Event":          8,
 }
 
 func (x HookType) String() string {
@@ -22,23 +22,30 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 type MiniRequestObject struct {
-	Headers              map[string]string `protobuf:"bytes,1,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	SetHeaders           map[string]string `protobuf:"bytes,2,rep,name=set_headers,json=setHeaders,proto3" json:"set_headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	DeleteHeaders        []string          `protobuf:"bytes,3,rep,name=delete_headers,json=deleteHeaders,proto3" json:"delete_headers,omitempty"`
-	Body                 string            `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
-	Url                  string            `protobuf:"bytes,5,opt,name=url,proto3" json:"url,omitempty"`
-	Params               map[string]string `protobuf:"bytes,6,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	AddParams            map[string]string `protobuf:"bytes,7,rep,name=add_params,json=addParams,proto3" json:"add_params,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	ExtendedParams       map[string]string `protobuf:"bytes,8,rep,name=extended_params,json=extendedParams,proto3" json:"extended_params,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	DeleteParams         []string          `protobuf:"bytes,9,rep,name=delete_params,json=deleteParams,proto3" json:"delete_params,omitempty"`
-	ReturnOverrides      *ReturnOverrides  `protobuf:"bytes,10,opt,name=return_overrides,json=returnOverrides,proto3" json:"return_overrides,omitempty"`
-	Method               string            `protobuf:"bytes,11,opt,name=method,proto3" json:"method,omitempty"`
-	RequestUri           string            `protobuf:"bytes,12,opt,name=request_uri,json=requestUri,proto3" json:"request_uri,omitempty"`
-	Scheme               string            `protobuf:"bytes,13,opt,name=scheme,proto3" json:"scheme,omitempty"`
-	RawBody              []byte            `protobuf:"bytes,14,opt,name=raw_body,json=rawBody,proto3" json:"raw_body,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
-	XXX_unrecognized     []byte            `json:"-"`
-	XXX_sizecache        int32             `json:"-"`
+	Headers                map[string]string `protobuf:"bytes,1,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	SetHeaders             map[string]string `protobuf:"bytes,2,rep,name=set_headers,json=setHeaders,proto3" json:"set_headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	DeleteHeaders          []string          `protobuf:"bytes,3,rep,name=delete_headers,json=deleteHeaders,proto3" json:"delete_headers,omitempty"`
+	Body                   string            `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	Url                    string            `protobuf:"bytes,5,opt,name=url,proto3" json:"url,omitempty"`
+	Params                 map[string]string `protobuf:"bytes,6,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	AddParams              map[string]string `protobuf:"bytes,7,rep,name=add_params,json=addParams,proto3" json:"add_params,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	ExtendedParams         map[string]string `protobuf:"bytes,8,rep,name=extended_params,json=extendedParams,proto3" json:"extended_params,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	DeleteParams           []string          `protobuf:"bytes,9,rep,name=delete_params,json=deleteParams,proto3" json:"delete_params,omitempty"`
+	ReturnOverrides        *ReturnOverrides  `protobuf:"bytes,10,opt,name=return_overrides,json=returnOverrides,proto3" json:"return_overrides,omitempty"`
+	Method                 string            `protobuf:"bytes,11,opt,name=method,proto3" json:"method,omitempty"`
+	RequestUri             string            `protobuf:"bytes,12,opt,name=request_uri,json=requestUri,proto3" json:"request_uri,omitempty"`
+	Scheme                 string            `protobuf:"bytes,13,opt,name=scheme,proto3" json:"scheme,omitempty"`
+	RawBody                []byte            `protobuf:"bytes,14,opt,name=raw_body,json=rawBody,proto3" json:"raw_body,omitempty"`
+	AnalyticsDetailLevel   string            `protobuf:"bytes,15,opt,name=analytics_detail_level,json=analyticsDetailLevel,proto3" json:"analytics_detail_level,omitempty"`
+	Tls                    *TLS              `protobuf:"bytes,16,opt,name=tls,proto3" json:"tls,omitempty"`
+	StreamId               int64             `protobuf:"varint,17,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"`
+	ConnectionRequestCount int64             `protobuf:"varint,18,opt,name=connection_request_count,json=connectionRequestCount,proto3" json:"connection_request_count,omitempty"`
+	JwtHeader              map[string]string `protobuf:"bytes,19,rep,name=jwt_header,json=jwtHeader,proto3" json:"jwt_header,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	ReceivedAt             int64             `protobuf:"varint,20,opt,name=received_at,json=receivedAt,proto3" json:"received_at,omitempty"`
+	Deadline               int64             `protobuf:"varint,21,opt,name=deadline,proto3" json:"deadline,omitempty"`
+	XXX_NoUnkeyedLiteral   struct{}          `json:"-"`
+	XXX_unrecognized       []byte            `json:"-"`
+	XXX_sizecache          int32             `json:"-"`
 }
 
 func (m *MiniRequestObject) Reset()         { *m = MiniRequestObject{} }
@@ -164,6 +171,55 @@ func (m *MiniRequestObject) GetRawBody() []byte {
 	return nil
 }
 
+func (m *MiniRequestObject) GetAnalyticsDetailLevel() string {
+	if m != nil {
+		return m.AnalyticsDetailLevel
+	}
+	return ""
+}
+
+func (m *MiniRequestObject) GetTls() *TLS {
+	if m != nil {
+		return m.Tls
+	}
+	return nil
+}
+
+func (m *MiniRequestObject) GetStreamId() int64 {
+	if m != nil {
+		return m.StreamId
+	}
+	return 0
+}
+
+func (m *MiniRequestObject) GetConnectionRequestCount() int64 {
+	if m != nil {
+		return m.ConnectionRequestCount
+	}
+	return 0
+}
+
+func (m *MiniRequestObject) GetJwtHeader() map[string]string {
+	if m != nil {
+		return m.JwtHeader
+	}
+	return nil
+}
+
+func (m *MiniRequestObject) GetReceivedAt() int64 {
+	if m != nil {
+		return m.ReceivedAt
+	}
+	return 0
+}
+
+func (m *MiniRequestObject) GetDeadline() int64 {
+	if m != nil {
+		return m.Deadline
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*MiniRequestObject)(nil), "coprocess.MiniRequestObject")
 	proto.RegisterMapType((map[string]string)(nil), "coprocess.MiniRequestObject.AddParamsEntry")
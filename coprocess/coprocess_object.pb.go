@@ -236,6 +236,7 @@ const _ = grpc.SupportPackageIsVersion4
 type DispatcherClient interface {
 	Dispatch(ctx context.Context, in *Object, opts ...grpc.CallOption) (*Object, error)
 	DispatchEvent(ctx context.Context, in *Event, opts ...grpc.CallOption) (*EventReply, error)
+	DispatchStream(ctx context.Context, opts ...grpc.CallOption) (Dispatcher_DispatchStreamClient, error)
 }
 
 type dispatcherClient struct {
@@ -264,10 +265,42 @@ func (c *dispatcherClient) DispatchEvent(ctx context.Context, in *Event, opts ..
 	return out, nil
 }
 
+func (c *dispatcherClient) DispatchStream(ctx context.Context, opts ...grpc.CallOption) (Dispatcher_DispatchStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Dispatcher_serviceDesc.Streams[0], "/coprocess.Dispatcher/DispatchStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dispatcherDispatchStreamClient{stream}
+	return x, nil
+}
+
+type Dispatcher_DispatchStreamClient interface {
+	Send(*Object) error
+	Recv() (*Object, error)
+	grpc.ClientStream
+}
+
+type dispatcherDispatchStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *dispatcherDispatchStreamClient) Send(m *Object) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *dispatcherDispatchStreamClient) Recv() (*Object, error) {
+	m := new(Object)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // DispatcherServer is the server API for Dispatcher service.
 type DispatcherServer interface {
 	Dispatch(context.Context, *Object) (*Object, error)
 	DispatchEvent(context.Context, *Event) (*EventReply, error)
+	DispatchStream(Dispatcher_DispatchStreamServer) error
 }
 
 // UnimplementedDispatcherServer can be embedded to have forward compatible implementations.
@@ -280,6 +313,9 @@ func (*UnimplementedDispatcherServer) Dispatch(ctx context.Context, req *Object)
 func (*UnimplementedDispatcherServer) DispatchEvent(ctx context.Context, req *Event) (*EventReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DispatchEvent not implemented")
 }
+func (*UnimplementedDispatcherServer) DispatchStream(srv Dispatcher_DispatchStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method DispatchStream not implemented")
+}
 
 func RegisterDispatcherServer(s *grpc.Server, srv DispatcherServer) {
 	s.RegisterService(&_Dispatcher_serviceDesc, srv)
@@ -321,6 +357,32 @@ func _Dispatcher_DispatchEvent_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Dispatcher_DispatchStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DispatcherServer).DispatchStream(&dispatcherDispatchStreamServer{stream})
+}
+
+type Dispatcher_DispatchStreamServer interface {
+	Send(*Object) error
+	Recv() (*Object, error)
+	grpc.ServerStream
+}
+
+type dispatcherDispatchStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *dispatcherDispatchStreamServer) Send(m *Object) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *dispatcherDispatchStreamServer) Recv() (*Object, error) {
+	m := new(Object)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 var _Dispatcher_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "coprocess.Dispatcher",
 	HandlerType: (*DispatcherServer)(nil),
@@ -334,6 +396,13 @@ var _Dispatcher_serviceDesc = grpc.ServiceDesc{
 			Handler:    _Dispatcher_DispatchEvent_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DispatchStream",
+			Handler:       _Dispatcher_DispatchStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "coprocess_object.proto",
 }
@@ -22,13 +22,17 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 type ResponseObject struct {
-	StatusCode           int32             `protobuf:"varint,1,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
-	RawBody              []byte            `protobuf:"bytes,2,opt,name=raw_body,json=rawBody,proto3" json:"raw_body,omitempty"`
-	Body                 string            `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
-	Headers              map[string]string `protobuf:"bytes,4,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
-	XXX_unrecognized     []byte            `json:"-"`
-	XXX_sizecache        int32             `json:"-"`
+	StatusCode            int32             `protobuf:"varint,1,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	RawBody               []byte            `protobuf:"bytes,2,opt,name=raw_body,json=rawBody,proto3" json:"raw_body,omitempty"`
+	Body                  string            `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+	Headers               map[string]string `protobuf:"bytes,4,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	HeaderOrder           []string          `protobuf:"bytes,5,rep,name=header_order,json=headerOrder,proto3" json:"header_order,omitempty"`
+	DeclaredContentLength int64             `protobuf:"varint,6,opt,name=declared_content_length,json=declaredContentLength,proto3" json:"declared_content_length,omitempty"`
+	ActualContentLength   int64             `protobuf:"varint,7,opt,name=actual_content_length,json=actualContentLength,proto3" json:"actual_content_length,omitempty"`
+	ContentLengthUnknown  bool              `protobuf:"varint,8,opt,name=content_length_unknown,json=contentLengthUnknown,proto3" json:"content_length_unknown,omitempty"`
+	XXX_NoUnkeyedLiteral  struct{}          `json:"-"`
+	XXX_unrecognized      []byte            `json:"-"`
+	XXX_sizecache         int32             `json:"-"`
 }
 
 func (m *ResponseObject) Reset()         { *m = ResponseObject{} }
@@ -84,6 +88,34 @@ func (m *ResponseObject) GetHeaders() map[string]string {
 	return nil
 }
 
+func (m *ResponseObject) GetHeaderOrder() []string {
+	if m != nil {
+		return m.HeaderOrder
+	}
+	return nil
+}
+
+func (m *ResponseObject) GetDeclaredContentLength() int64 {
+	if m != nil {
+		return m.DeclaredContentLength
+	}
+	return 0
+}
+
+func (m *ResponseObject) GetActualContentLength() int64 {
+	if m != nil {
+		return m.ActualContentLength
+	}
+	return 0
+}
+
+func (m *ResponseObject) GetContentLengthUnknown() bool {
+	if m != nil {
+		return m.ContentLengthUnknown
+	}
+	return false
+}
+
 func init() {
 	proto.RegisterType((*ResponseObject)(nil), "coprocess.ResponseObject")
 	proto.RegisterMapType((map[string]string)(nil), "coprocess.ResponseObject.HeadersEntry")
@@ -22,14 +22,45 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 type ReturnOverrides struct {
-	ResponseCode         int32             `protobuf:"varint,1,opt,name=response_code,json=responseCode,proto3" json:"response_code,omitempty"`
-	ResponseError        string            `protobuf:"bytes,2,opt,name=response_error,json=responseError,proto3" json:"response_error,omitempty"`
-	Headers              map[string]string `protobuf:"bytes,3,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	OverrideError        bool              `protobuf:"varint,4,opt,name=override_error,json=overrideError,proto3" json:"override_error,omitempty"`
-	ResponseBody         string            `protobuf:"bytes,5,opt,name=response_body,json=responseBody,proto3" json:"response_body,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
-	XXX_unrecognized     []byte            `json:"-"`
-	XXX_sizecache        int32             `json:"-"`
+	ResponseCode                   int32              `protobuf:"varint,1,opt,name=response_code,json=responseCode,proto3" json:"response_code,omitempty"`
+	ResponseError                  string             `protobuf:"bytes,2,opt,name=response_error,json=responseError,proto3" json:"response_error,omitempty"`
+	Headers                        map[string]string  `protobuf:"bytes,3,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	OverrideError                  bool               `protobuf:"varint,4,opt,name=override_error,json=overrideError,proto3" json:"override_error,omitempty"`
+	ResponseBody                   string             `protobuf:"bytes,5,opt,name=response_body,json=responseBody,proto3" json:"response_body,omitempty"`
+	RetryBudget                    int32              `protobuf:"varint,6,opt,name=retry_budget,json=retryBudget,proto3" json:"retry_budget,omitempty"`
+	RegisterIdempotencyKey         bool               `protobuf:"varint,7,opt,name=register_idempotency_key,json=registerIdempotencyKey,proto3" json:"register_idempotency_key,omitempty"`
+	CacheBackend                   string             `protobuf:"bytes,8,opt,name=cache_backend,json=cacheBackend,proto3" json:"cache_backend,omitempty"`
+	UpstreamProtocol               string             `protobuf:"bytes,9,opt,name=upstream_protocol,json=upstreamProtocol,proto3" json:"upstream_protocol,omitempty"`
+	InjectDelayMs                  int64              `protobuf:"varint,10,opt,name=inject_delay_ms,json=injectDelayMs,proto3" json:"inject_delay_ms,omitempty"`
+	InjectFault                    int32              `protobuf:"varint,11,opt,name=inject_fault,json=injectFault,proto3" json:"inject_fault,omitempty"`
+	QuotaCost                      int64              `protobuf:"varint,12,opt,name=quota_cost,json=quotaCost,proto3" json:"quota_cost,omitempty"`
+	AddTags                        []string           `protobuf:"bytes,13,rep,name=add_tags,json=addTags,proto3" json:"add_tags,omitempty"`
+	RemoveTags                     []string           `protobuf:"bytes,14,rep,name=remove_tags,json=removeTags,proto3" json:"remove_tags,omitempty"`
+	AffinityKey                    string             `protobuf:"bytes,15,opt,name=affinity_key,json=affinityKey,proto3" json:"affinity_key,omitempty"`
+	CorsMaxAge                     int32              `protobuf:"varint,16,opt,name=cors_max_age,json=corsMaxAge,proto3" json:"cors_max_age,omitempty"`
+	CanonicalizeQuery              bool               `protobuf:"varint,17,opt,name=canonicalize_query,json=canonicalizeQuery,proto3" json:"canonicalize_query,omitempty"`
+	CompressResponse               string             `protobuf:"bytes,18,opt,name=compress_response,json=compressResponse,proto3" json:"compress_response,omitempty"`
+	AllowedContentTypes            []string           `protobuf:"bytes,19,rep,name=allowed_content_types,json=allowedContentTypes,proto3" json:"allowed_content_types,omitempty"`
+	RateLimitKey                   string             `protobuf:"bytes,20,opt,name=rate_limit_key,json=rateLimitKey,proto3" json:"rate_limit_key,omitempty"`
+	GraphqlAnalyticsSignature      string             `protobuf:"bytes,21,opt,name=graphql_analytics_signature,json=graphqlAnalyticsSignature,proto3" json:"graphql_analytics_signature,omitempty"`
+	GraphqlAnalyticsStripVariables bool               `protobuf:"varint,22,opt,name=graphql_analytics_strip_variables,json=graphqlAnalyticsStripVariables,proto3" json:"graphql_analytics_strip_variables,omitempty"`
+	UpstreamIdempotencyKey         string             `protobuf:"bytes,23,opt,name=upstream_idempotency_key,json=upstreamIdempotencyKey,proto3" json:"upstream_idempotency_key,omitempty"`
+	TargetWeights                  map[string]float64 `protobuf:"bytes,24,rep,name=target_weights,json=targetWeights,proto3" json:"target_weights,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
+	Sunset                         string             `protobuf:"bytes,25,opt,name=sunset,proto3" json:"sunset,omitempty"`
+	Deprecated                     bool               `protobuf:"varint,26,opt,name=deprecated,proto3" json:"deprecated,omitempty"`
+	DeprecationWarning             string             `protobuf:"bytes,27,opt,name=deprecation_warning,json=deprecationWarning,proto3" json:"deprecation_warning,omitempty"`
+	CacheVaryHeaders               []string           `protobuf:"bytes,28,rep,name=cache_vary_headers,json=cacheVaryHeaders,proto3" json:"cache_vary_headers,omitempty"`
+	RetainTrace                    bool               `protobuf:"varint,29,opt,name=retain_trace,json=retainTrace,proto3" json:"retain_trace,omitempty"`
+	RateLimitTemplateVars          map[string]string  `protobuf:"bytes,30,rep,name=rate_limit_template_vars,json=rateLimitTemplateVars,proto3" json:"rate_limit_template_vars,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	RetryAfter                     string             `protobuf:"bytes,31,opt,name=retry_after,json=retryAfter,proto3" json:"retry_after,omitempty"`
+	AnalyticsRequestBody           string             `protobuf:"bytes,32,opt,name=analytics_request_body,json=analyticsRequestBody,proto3" json:"analytics_request_body,omitempty"`
+	UpstreamPathPrefixStrip        string             `protobuf:"bytes,33,opt,name=upstream_path_prefix_strip,json=upstreamPathPrefixStrip,proto3" json:"upstream_path_prefix_strip,omitempty"`
+	UpstreamPathPrefixPrepend      string             `protobuf:"bytes,34,opt,name=upstream_path_prefix_prepend,json=upstreamPathPrefixPrepend,proto3" json:"upstream_path_prefix_prepend,omitempty"`
+	SkipMiddleware                 []string           `protobuf:"bytes,35,rep,name=skip_middleware,json=skipMiddleware,proto3" json:"skip_middleware,omitempty"`
+	UpstreamTimeoutSeconds         int32              `protobuf:"varint,36,opt,name=upstream_timeout_seconds,json=upstreamTimeoutSeconds,proto3" json:"upstream_timeout_seconds,omitempty"`
+	XXX_NoUnkeyedLiteral           struct{}           `json:"-"`
+	XXX_unrecognized               []byte             `json:"-"`
+	XXX_sizecache                  int32              `json:"-"`
 }
 
 func (m *ReturnOverrides) Reset()         { *m = ReturnOverrides{} }
@@ -92,9 +123,228 @@ func (m *ReturnOverrides) GetResponseBody() string {
 	return ""
 }
 
+func (m *ReturnOverrides) GetRetryBudget() int32 {
+	if m != nil {
+		return m.RetryBudget
+	}
+	return 0
+}
+
+func (m *ReturnOverrides) GetRegisterIdempotencyKey() bool {
+	if m != nil {
+		return m.RegisterIdempotencyKey
+	}
+	return false
+}
+
+func (m *ReturnOverrides) GetCacheBackend() string {
+	if m != nil {
+		return m.CacheBackend
+	}
+	return ""
+}
+
+func (m *ReturnOverrides) GetUpstreamProtocol() string {
+	if m != nil {
+		return m.UpstreamProtocol
+	}
+	return ""
+}
+
+func (m *ReturnOverrides) GetInjectDelayMs() int64 {
+	if m != nil {
+		return m.InjectDelayMs
+	}
+	return 0
+}
+
+func (m *ReturnOverrides) GetInjectFault() int32 {
+	if m != nil {
+		return m.InjectFault
+	}
+	return 0
+}
+
+func (m *ReturnOverrides) GetQuotaCost() int64 {
+	if m != nil {
+		return m.QuotaCost
+	}
+	return 0
+}
+
+func (m *ReturnOverrides) GetAddTags() []string {
+	if m != nil {
+		return m.AddTags
+	}
+	return nil
+}
+
+func (m *ReturnOverrides) GetRemoveTags() []string {
+	if m != nil {
+		return m.RemoveTags
+	}
+	return nil
+}
+
+func (m *ReturnOverrides) GetAffinityKey() string {
+	if m != nil {
+		return m.AffinityKey
+	}
+	return ""
+}
+
+func (m *ReturnOverrides) GetCorsMaxAge() int32 {
+	if m != nil {
+		return m.CorsMaxAge
+	}
+	return 0
+}
+
+func (m *ReturnOverrides) GetCanonicalizeQuery() bool {
+	if m != nil {
+		return m.CanonicalizeQuery
+	}
+	return false
+}
+
+func (m *ReturnOverrides) GetCompressResponse() string {
+	if m != nil {
+		return m.CompressResponse
+	}
+	return ""
+}
+
+func (m *ReturnOverrides) GetAllowedContentTypes() []string {
+	if m != nil {
+		return m.AllowedContentTypes
+	}
+	return nil
+}
+
+func (m *ReturnOverrides) GetRateLimitKey() string {
+	if m != nil {
+		return m.RateLimitKey
+	}
+	return ""
+}
+
+func (m *ReturnOverrides) GetGraphqlAnalyticsSignature() string {
+	if m != nil {
+		return m.GraphqlAnalyticsSignature
+	}
+	return ""
+}
+
+func (m *ReturnOverrides) GetGraphqlAnalyticsStripVariables() bool {
+	if m != nil {
+		return m.GraphqlAnalyticsStripVariables
+	}
+	return false
+}
+
+func (m *ReturnOverrides) GetUpstreamIdempotencyKey() string {
+	if m != nil {
+		return m.UpstreamIdempotencyKey
+	}
+	return ""
+}
+
+func (m *ReturnOverrides) GetTargetWeights() map[string]float64 {
+	if m != nil {
+		return m.TargetWeights
+	}
+	return nil
+}
+
+func (m *ReturnOverrides) GetSunset() string {
+	if m != nil {
+		return m.Sunset
+	}
+	return ""
+}
+
+func (m *ReturnOverrides) GetDeprecated() bool {
+	if m != nil {
+		return m.Deprecated
+	}
+	return false
+}
+
+func (m *ReturnOverrides) GetDeprecationWarning() string {
+	if m != nil {
+		return m.DeprecationWarning
+	}
+	return ""
+}
+
+func (m *ReturnOverrides) GetCacheVaryHeaders() []string {
+	if m != nil {
+		return m.CacheVaryHeaders
+	}
+	return nil
+}
+
+func (m *ReturnOverrides) GetRetainTrace() bool {
+	if m != nil {
+		return m.RetainTrace
+	}
+	return false
+}
+
+func (m *ReturnOverrides) GetRateLimitTemplateVars() map[string]string {
+	if m != nil {
+		return m.RateLimitTemplateVars
+	}
+	return nil
+}
+
+func (m *ReturnOverrides) GetRetryAfter() string {
+	if m != nil {
+		return m.RetryAfter
+	}
+	return ""
+}
+
+func (m *ReturnOverrides) GetAnalyticsRequestBody() string {
+	if m != nil {
+		return m.AnalyticsRequestBody
+	}
+	return ""
+}
+
+func (m *ReturnOverrides) GetUpstreamPathPrefixStrip() string {
+	if m != nil {
+		return m.UpstreamPathPrefixStrip
+	}
+	return ""
+}
+
+func (m *ReturnOverrides) GetUpstreamPathPrefixPrepend() string {
+	if m != nil {
+		return m.UpstreamPathPrefixPrepend
+	}
+	return ""
+}
+
+func (m *ReturnOverrides) GetSkipMiddleware() []string {
+	if m != nil {
+		return m.SkipMiddleware
+	}
+	return nil
+}
+
+func (m *ReturnOverrides) GetUpstreamTimeoutSeconds() int32 {
+	if m != nil {
+		return m.UpstreamTimeoutSeconds
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*ReturnOverrides)(nil), "coprocess.ReturnOverrides")
 	proto.RegisterMapType((map[string]string)(nil), "coprocess.ReturnOverrides.HeadersEntry")
+	proto.RegisterMapType((map[string]float64)(nil), "coprocess.ReturnOverrides.TargetWeightsEntry")
+	proto.RegisterMapType((map[string]string)(nil), "coprocess.ReturnOverrides.RateLimitTemplateVarsEntry")
 }
 
 func init() { proto.RegisterFile("coprocess_return_overrides.proto", fileDescriptor_7c6abd8ea4a81548) }
@@ -287,6 +287,7 @@ type SessionState struct {
 	SessionLifetime         int64                        `protobuf:"varint,28,opt,name=session_lifetime,json=sessionLifetime,proto3" json:"session_lifetime,omitempty"`
 	ApplyPolicies           []string                     `protobuf:"bytes,29,rep,name=apply_policies,json=applyPolicies,proto3" json:"apply_policies,omitempty"`
 	Certificate             string                       `protobuf:"bytes,30,opt,name=certificate,proto3" json:"certificate,omitempty"`
+	RateAlgorithm           string                       `protobuf:"bytes,31,opt,name=rate_algorithm,json=rateAlgorithm,proto3" json:"rate_algorithm,omitempty"`
 	XXX_NoUnkeyedLiteral    struct{}                     `json:"-"`
 	XXX_unrecognized        []byte                       `json:"-"`
 	XXX_sizecache           int32                        `json:"-"`
@@ -527,6 +528,13 @@ func (m *SessionState) GetCertificate() string {
 	return ""
 }
 
+func (m *SessionState) GetRateAlgorithm() string {
+	if m != nil {
+		return m.RateAlgorithm
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*AccessSpec)(nil), "coprocess.AccessSpec")
 	proto.RegisterType((*AccessDefinition)(nil), "coprocess.AccessDefinition")
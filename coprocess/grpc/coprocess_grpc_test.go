@@ -116,6 +116,22 @@ func (d *dispatcher) DispatchEvent(ctx context.Context, event *coprocess.Event)
 	return &coprocess.EventReply{}, nil
 }
 
+// DispatchStream echoes each frame back with a "-echo" suffix appended to its
+// hook name, so tests can assert both that frames are transformed and that
+// ordering is preserved.
+func (d *dispatcher) DispatchStream(stream coprocess.Dispatcher_DispatchStreamServer) error {
+	for {
+		object, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		object.HookName = object.HookName + "-echo"
+		if err := stream.Send(object); err != nil {
+			return err
+		}
+	}
+}
+
 func newTestGRPCServer() (s *grpc.Server) {
 	s = grpc.NewServer(
 		grpc.MaxRecvMsgSize(grpcTestMaxSize),
@@ -236,6 +252,40 @@ func loadTestGRPCAPIs() {
 				Driver: apidef.GrpcDriver,
 			}
 		},
+		func(spec *gateway.APISpec) {
+			spec.APIID = "5"
+			spec.OrgID = "default"
+			spec.Auth = apidef.AuthConfig{
+				AuthHeaderName: "authorization",
+			}
+			spec.UseKeylessAccess = true
+			spec.VersionData = struct {
+				NotVersioned   bool                          `bson:"not_versioned" json:"not_versioned"`
+				DefaultVersion string                        `bson:"default_version" json:"default_version"`
+				Versions       map[string]apidef.VersionInfo `bson:"versions" json:"versions"`
+			}{
+				NotVersioned: true,
+				Versions: map[string]apidef.VersionInfo{
+					"v1": {
+						Name:             "v1",
+						UseExtendedPaths: true,
+						ExtendedPaths: apidef.ExtendedPathsSet{
+							BypassCoProcess: []apidef.TrackEndpointMeta{
+								{Path: "/public", Method: http.MethodGet},
+							},
+						},
+					},
+				},
+			}
+			spec.Proxy.ListenPath = "/grpc-test-api-5/"
+			spec.Proxy.StripListenPath = true
+			spec.CustomMiddleware = apidef.MiddlewareSection{
+				Pre: []apidef.MiddlewareDefinition{
+					{Name: "testPreHook1"},
+				},
+				Driver: apidef.GrpcDriver,
+			}
+		},
 	)
 }
 
@@ -377,6 +427,43 @@ func TestGRPCDispatch(t *testing.T) {
 	})
 }
 
+func TestGRPCDispatchBypassCoProcess(t *testing.T) {
+	ts, grpcServer := startTykWithGRPC()
+	defer ts.Close()
+	defer grpcServer.Stop()
+
+	assertHookRan := func(t *testing.T, path string, shouldRun bool) {
+		res, err := ts.Run(t, test.TestCase{
+			Path:   "/grpc-test-api-5" + path,
+			Method: http.MethodGet,
+			Code:   http.StatusOK,
+		})
+		if err != nil {
+			t.Fatalf("Request failed: %s", err.Error())
+		}
+		data, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatalf("Couldn't read response body: %s", err.Error())
+		}
+		var testResponse gateway.TestHttpResponse
+		if err := json.Unmarshal(data, &testResponse); err != nil {
+			t.Fatalf("Couldn't unmarshal test response JSON: %s", err.Error())
+		}
+		_, ran := testResponse.Headers[testHeaderName]
+		if ran != shouldRun {
+			t.Fatalf("expected hook-ran=%v for %s, got %v", shouldRun, path, ran)
+		}
+	}
+
+	t.Run("/secured runs the hook", func(t *testing.T) {
+		assertHookRan(t, "/secured", true)
+	})
+
+	t.Run("/public bypasses the hook", func(t *testing.T) {
+		assertHookRan(t, "/public", false)
+	})
+}
+
 func BenchmarkGRPCDispatch(b *testing.B) {
 	ts, grpcServer := startTykWithGRPC()
 	defer ts.Close()
@@ -399,6 +486,47 @@ func BenchmarkGRPCDispatch(b *testing.B) {
 	})
 }
 
+func TestGRPCDispatchStream(t *testing.T) {
+	listener, err := net.Listen("tcp", ":9998")
+	if err != nil {
+		t.Fatalf("couldn't start test gRPC listener: %s", err.Error())
+	}
+	grpcServer := newTestGRPCServer()
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial("127.0.0.1:9998", grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("couldn't dial test gRPC server: %s", err.Error())
+	}
+	defer conn.Close()
+
+	client := coprocess.NewDispatcherClient(conn)
+	stream, err := client.DispatchStream(context.Background())
+	if err != nil {
+		t.Fatalf("couldn't open stream: %s", err.Error())
+	}
+
+	frames := []string{"frame-1", "frame-2", "frame-3"}
+
+	for _, hookName := range frames {
+		if err := stream.Send(&coprocess.Object{HookName: hookName}); err != nil {
+			t.Fatalf("couldn't send frame %q: %s", hookName, err.Error())
+		}
+	}
+
+	for _, hookName := range frames {
+		object, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("couldn't receive echoed frame: %s", err.Error())
+		}
+		want := hookName + "-echo"
+		if object.HookName != want {
+			t.Fatalf("frame ordering not preserved: got %q, want %q", object.HookName, want)
+		}
+	}
+}
+
 const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
 
 func randStringBytes(n int) string {
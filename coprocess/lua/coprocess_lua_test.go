@@ -0,0 +1,161 @@
+// +build lua
+
+package lua
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/gateway"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+// These mirror the structure of coprocess/python's bundle tests - auth
+// check, pre, post - but exercise the Lua driver's embedded luajit VM
+// instead of the Python interpreter.
+
+var luaBundleWithAuthCheck = map[string]string{
+	"manifest.json": `
+		{
+		    "file_list": [
+		        "middleware.lua"
+		    ],
+		    "custom_middleware": {
+		        "driver": "lua",
+		        "auth_check": {
+		            "name": "MyAuthHook"
+		        }
+		    }
+		}
+`,
+	"middleware.lua": `
+function MyAuthHook(request, session, metadata, spec)
+  if request['headers']['Authorization'] == 'valid_token' then
+    session['rate'] = 1000.0
+    session['per'] = 1.0
+    session['quota_max'] = 1
+    session['quota_renewal_rate'] = 60
+    metadata['token'] = 'valid_token'
+  end
+  return request, session, metadata
+end
+`,
+}
+
+var luaBundleWithPreHook = map[string]string{
+	"manifest.json": `
+		{
+		    "file_list": [
+		        "middleware.lua"
+		    ],
+		    "custom_middleware": {
+		        "driver": "lua",
+		        "pre": [{
+		            "name": "MyPreHook"
+		        }]
+		    }
+		}
+`,
+	"middleware.lua": `
+function MyPreHook(request, session, metadata, spec)
+  if request['headers']['Authorization'] == 'rewrite' then
+    request['set_headers']['X-Lua-Pre'] = 'ran'
+  end
+  return request, session, metadata
+end
+`,
+}
+
+var luaBundleWithPostHook = map[string]string{
+	"manifest.json": `
+		{
+		    "file_list": [
+		        "middleware.lua"
+		    ],
+		    "custom_middleware": {
+		        "driver": "lua",
+		        "post": [{
+		            "name": "MyPostHook"
+		        }]
+		    }
+		}
+`,
+	"middleware.lua": `
+function MyPostHook(request, session, spec)
+  request['set_headers']['X-Lua-Post'] = 'ran'
+  return request, session
+end
+`,
+}
+
+func TestMain(m *testing.M) {
+	os.Exit(gateway.InitTestMain(context.Background(), m))
+}
+
+func TestLuaBundles(t *testing.T) {
+	ts := gateway.StartTest(gateway.TestConfig{
+		CoprocessConfig: config.CoProcessConfig{
+			EnableCoProcess: true,
+		}})
+	defer ts.Close()
+
+	authCheckBundle := gateway.RegisterBundle("lua_with_auth_check", luaBundleWithAuthCheck)
+	preHookBundle := gateway.RegisterBundle("lua_with_pre_hook", luaBundleWithPreHook)
+	postHookBundle := gateway.RegisterBundle("lua_with_post_hook", luaBundleWithPostHook)
+
+	t.Run("Single-file bundle with auth check hook", func(t *testing.T) {
+		gateway.BuildAndLoadAPI(func(spec *gateway.APISpec) {
+			spec.Proxy.ListenPath = "/test-lua-api-auth/"
+			spec.UseKeylessAccess = false
+			spec.EnableCoProcessAuth = true
+			spec.CustomMiddlewareBundle = authCheckBundle
+			spec.VersionData.NotVersioned = true
+		})
+
+		time.Sleep(1 * time.Second)
+
+		validAuth := map[string]string{"Authorization": "valid_token"}
+		invalidAuth := map[string]string{"Authorization": "invalid_token"}
+
+		ts.Run(t, []test.TestCase{
+			{Path: "/test-lua-api-auth/", Code: http.StatusOK, Headers: validAuth},
+			{Path: "/test-lua-api-auth/", Code: http.StatusForbidden, Headers: invalidAuth},
+		}...)
+	})
+
+	t.Run("Single-file bundle with pre hook", func(t *testing.T) {
+		gateway.BuildAndLoadAPI(func(spec *gateway.APISpec) {
+			spec.Proxy.ListenPath = "/test-lua-api-pre/"
+			spec.UseKeylessAccess = true
+			spec.EnableCoProcessAuth = false
+			spec.CustomMiddlewareBundle = preHookBundle
+			spec.VersionData.NotVersioned = true
+		})
+
+		time.Sleep(1 * time.Second)
+
+		ts.Run(t, []test.TestCase{
+			{Path: "/test-lua-api-pre/", Code: http.StatusOK, Headers: map[string]string{"Authorization": "rewrite"}, BodyMatch: "X-Lua-Pre"},
+		}...)
+	})
+
+	t.Run("Single-file bundle with post hook", func(t *testing.T) {
+		gateway.BuildAndLoadAPI(func(spec *gateway.APISpec) {
+			spec.Proxy.ListenPath = "/test-lua-api-post/"
+			spec.UseKeylessAccess = true
+			spec.EnableCoProcessAuth = false
+			spec.CustomMiddlewareBundle = postHookBundle
+			spec.VersionData.NotVersioned = true
+		})
+
+		time.Sleep(1 * time.Second)
+
+		ts.Run(t, []test.TestCase{
+			{Path: "/test-lua-api-post/", Code: http.StatusOK, BodyMatch: "X-Lua-Post"},
+		}...)
+	})
+}
@@ -60,6 +60,53 @@ def MyAuthHook(request, session, metadata, spec):
 `,
 }
 
+var pythonBundleWithTypedMetadataAuthCheck = map[string]string{
+	"manifest.json": `
+		{
+		    "file_list": [
+		        "middleware.py"
+		    ],
+		    "custom_middleware": {
+		        "driver": "python",
+		        "auth_check": {
+		            "name": "MyAuthHook"
+		        },
+		        "post": [{
+		            "name": "MyPostHook"
+		        }]
+		    }
+		}
+`,
+	"middleware.py": `
+from tyk.decorators import *
+from gateway import TykGateway as tyk
+
+@Hook
+def MyAuthHook(request, session, metadata, spec):
+  auth_header = request.get_header('Authorization')
+  if auth_header == 'valid_token':
+    session.rate = 1000.0
+    session.per = 1.0
+    session.quota_max = 1
+    session.quota_renewal_rate = 60
+    metadata["limits"] = {"rate": 10}
+  return request, session, metadata
+
+@Hook
+def MyPostHook(request, session, spec):
+    if "limits" not in session.metadata.keys():
+        request.object.return_overrides.response_code = 400
+        request.object.return_overrides.response_error = "'limits' not found in metadata"
+        return request, session
+    limits = session.metadata["limits"]
+    if not isinstance(limits, dict) or limits.get("rate") != 10:
+        request.object.return_overrides.response_code = 400
+        request.object.return_overrides.response_error = "'limits' wasn't decoded back into a nested object"
+        return request, session
+    return request, session
+`,
+}
+
 var pythonBundleWithPostHook = map[string]string{
 	"manifest.json": `
 		{
@@ -104,6 +151,34 @@ def MyPostHook(request, session, spec):
 `,
 }
 
+var pythonBundleWithPostHookCustomErrorBody = map[string]string{
+	"manifest.json": `
+		{
+		    "file_list": [
+		        "middleware.py"
+		    ],
+		    "custom_middleware": {
+		        "driver": "python",
+		        "post": [{
+		            "name": "MyPostHook"
+		        }]
+		    }
+		}
+	`,
+	"middleware.py": `
+from tyk.decorators import *
+from gateway import TykGateway as tyk
+import json
+
+@Hook
+def MyPostHook(request, session, spec):
+    request.object.return_overrides.response_code = 422
+    request.object.return_overrides.headers["Content-Type"] = "application/json"
+    request.object.return_overrides.response_body = json.dumps({"error": "bad"})
+    return request, session
+`,
+}
+
 var pythonPostRequestTransform = map[string]string{
 	"manifest.json": `
 		{
@@ -181,6 +256,34 @@ def MyPreHook(request, session, metadata, spec):
 `,
 }
 
+var pythonBundleWithParamRewritePreHook = map[string]string{
+	"manifest.json": `
+		{
+		    "file_list": [
+		        "middleware.py"
+		    ],
+		    "custom_middleware": {
+		        "driver": "python",
+		        "pre": [{
+		            "name": "MyPreHook"
+		        }]
+		    }
+		}
+	`,
+	"middleware.py": `
+from tyk.decorators import *
+from gateway import TykGateway as tyk
+
+@Hook
+def MyPreHook(request, session, metadata, spec):
+    tenant = request.get_param("tenant")
+    if tenant == "acme":
+        request.add_param("tenant", "globex")
+    return request, session, metadata
+
+`,
+}
+
 var pythonBundleWithResponseHook = map[string]string{
 	"manifest.json": `
 		{
@@ -207,6 +310,130 @@ def MyResponseHook(request, response, session, metadata, spec):
 `,
 }
 
+var pythonBundleWithResponseTransformHook = map[string]string{
+	"manifest.json": `
+		{
+		    "file_list": [
+		        "middleware.py"
+		    ],
+		    "custom_middleware": {
+		        "driver": "python",
+		        "response": [{
+		            "name": "MyResponseTransformHook"
+		        }]
+		    }
+		}
+	`,
+	"middleware.py": `
+from tyk.decorators import *
+from gateway import TykGateway as tyk
+import json
+
+@Hook
+def MyResponseTransformHook(request, response, session, metadata, spec):
+  data = json.loads(response.raw_body)
+  data["Url"] = data["Url"].upper()
+  response.raw_body = json.dumps(data).encode()
+  response.headers["X-Processed"] = "true"
+  return response
+
+`,
+}
+
+func pythonBundleWithAuthCheckHooks(policy string) map[string]string {
+	return map[string]string{
+		"manifest.json": `
+		{
+		    "file_list": [
+		        "middleware.py"
+		    ],
+		    "custom_middleware": {
+		        "driver": "python",
+		        "auth_check_hooks": [
+		            {"name": "CheckFactorOne"},
+		            {"name": "CheckFactorTwo"}
+		        ],
+		        "auth_check_hooks_policy": "` + policy + `"
+		    }
+		}
+`,
+		"middleware.py": `
+from tyk.decorators import *
+from gateway import TykGateway as tyk
+
+@Hook
+def CheckFactorOne(request, session, metadata, spec):
+  if request.get_header('X-Factor-One') == 'one':
+    session.rate = 1000.0
+    session.per = 1.0
+    metadata["token"] = "factor-one"
+  return request, session, metadata
+
+@Hook
+def CheckFactorTwo(request, session, metadata, spec):
+  if request.get_header('X-Factor-Two') == 'two':
+    session.rate = 1000.0
+    session.per = 1.0
+    metadata["token"] = "factor-two"
+  return request, session, metadata
+`,
+	}
+}
+
+func TestPythonAuthCheckHooks(t *testing.T) {
+	ts := gateway.StartTest(gateway.TestConfig{
+		CoprocessConfig: config.CoProcessConfig{
+			EnableCoProcess:  true,
+			PythonPathPrefix: pkgPath,
+		}})
+	defer ts.Close()
+
+	bothFactors := map[string]string{"X-Factor-One": "one", "X-Factor-Two": "two"}
+	onlyFactorOne := map[string]string{"X-Factor-One": "one"}
+	onlyFactorTwo := map[string]string{"X-Factor-Two": "two"}
+	neitherFactor := map[string]string{}
+
+	t.Run("and policy requires every hook to succeed", func(t *testing.T) {
+		andBundle := gateway.RegisterBundle("python_with_auth_check_hooks_and", pythonBundleWithAuthCheckHooks("and"))
+		gateway.BuildAndLoadAPI(func(spec *gateway.APISpec) {
+			spec.Proxy.ListenPath = "/test-api-auth-hooks-and/"
+			spec.UseKeylessAccess = false
+			spec.EnableCoProcessAuth = true
+			spec.CustomMiddlewareBundle = andBundle
+			spec.VersionData.NotVersioned = true
+		})
+
+		time.Sleep(1 * time.Second)
+
+		ts.Run(t, []test.TestCase{
+			{Path: "/test-api-auth-hooks-and/", Code: http.StatusOK, Headers: bothFactors},
+			{Path: "/test-api-auth-hooks-and/", Code: http.StatusForbidden, Headers: onlyFactorOne},
+			{Path: "/test-api-auth-hooks-and/", Code: http.StatusForbidden, Headers: onlyFactorTwo},
+			{Path: "/test-api-auth-hooks-and/", Code: http.StatusForbidden, Headers: neitherFactor},
+		}...)
+	})
+
+	t.Run("or policy accepts the first hook that succeeds", func(t *testing.T) {
+		orBundle := gateway.RegisterBundle("python_with_auth_check_hooks_or", pythonBundleWithAuthCheckHooks("or"))
+		gateway.BuildAndLoadAPI(func(spec *gateway.APISpec) {
+			spec.Proxy.ListenPath = "/test-api-auth-hooks-or/"
+			spec.UseKeylessAccess = false
+			spec.EnableCoProcessAuth = true
+			spec.CustomMiddlewareBundle = orBundle
+			spec.VersionData.NotVersioned = true
+		})
+
+		time.Sleep(1 * time.Second)
+
+		ts.Run(t, []test.TestCase{
+			{Path: "/test-api-auth-hooks-or/", Code: http.StatusOK, Headers: bothFactors},
+			{Path: "/test-api-auth-hooks-or/", Code: http.StatusOK, Headers: onlyFactorOne},
+			{Path: "/test-api-auth-hooks-or/", Code: http.StatusOK, Headers: onlyFactorTwo},
+			{Path: "/test-api-auth-hooks-or/", Code: http.StatusForbidden, Headers: neitherFactor},
+		}...)
+	})
+}
+
 func TestMain(m *testing.M) {
 	os.Exit(gateway.InitTestMain(context.Background(), m))
 }
@@ -220,9 +447,12 @@ func TestPythonBundles(t *testing.T) {
 	defer ts.Close()
 
 	authCheckBundle := gateway.RegisterBundle("python_with_auth_check", pythonBundleWithAuthCheck)
+	typedMetadataAuthCheckBundle := gateway.RegisterBundle("python_with_typed_metadata_auth_check", pythonBundleWithTypedMetadataAuthCheck)
 	postHookBundle := gateway.RegisterBundle("python_with_post_hook", pythonBundleWithPostHook)
 	preHookBundle := gateway.RegisterBundle("python_with_pre_hook", pythonBundleWithPreHook)
 	responseHookBundle := gateway.RegisterBundle("python_with_response_hook", pythonBundleWithResponseHook)
+	paramRewritePreHookBundle := gateway.RegisterBundle("python_with_param_rewrite_pre_hook", pythonBundleWithParamRewritePreHook)
+	responseTransformHookBundle := gateway.RegisterBundle("python_with_response_transform_hook", pythonBundleWithResponseTransformHook)
 	postRequestTransformHookBundle := gateway.RegisterBundle("python_post_with_request_transform_hook", pythonPostRequestTransform)
 
 	t.Run("Single-file bundle with authentication hook", func(t *testing.T) {
@@ -275,6 +505,24 @@ func TestPythonBundles(t *testing.T) {
 		}...)
 	})
 
+	t.Run("Auth hook sets a nested metadata value, post hook reads it back typed", func(t *testing.T) {
+		gateway.BuildAndLoadAPI(func(spec *gateway.APISpec) {
+			spec.Proxy.ListenPath = "/test-api-typed-metadata/"
+			spec.UseKeylessAccess = false
+			spec.EnableCoProcessAuth = true
+			spec.CustomMiddlewareBundle = typedMetadataAuthCheckBundle
+			spec.VersionData.NotVersioned = true
+		})
+
+		time.Sleep(1 * time.Second)
+
+		validAuth := map[string]string{"Authorization": "valid_token"}
+
+		ts.Run(t, []test.TestCase{
+			{Path: "/test-api-typed-metadata/", Code: http.StatusOK, Headers: validAuth},
+		}...)
+	})
+
 	t.Run("Single-file bundle with post hook", func(t *testing.T) {
 
 		keyID := gateway.CreateSession(func(s *user.SessionState) {
@@ -301,6 +549,28 @@ func TestPythonBundles(t *testing.T) {
 		}...)
 	})
 
+	t.Run("Post hook short-circuits with a custom JSON error body", func(t *testing.T) {
+		postHookCustomErrorBodyBundle := gateway.RegisterBundle("python_with_post_hook_custom_error_body", pythonBundleWithPostHookCustomErrorBody)
+
+		gateway.BuildAndLoadAPI(func(spec *gateway.APISpec) {
+			spec.Proxy.ListenPath = "/test-api-post-custom-error/"
+			spec.UseKeylessAccess = true
+			spec.CustomMiddlewareBundle = postHookCustomErrorBodyBundle
+			spec.VersionData.NotVersioned = true
+		})
+
+		time.Sleep(1 * time.Second)
+
+		ts.Run(t, []test.TestCase{
+			{
+				Path:         "/test-api-post-custom-error/",
+				Code:         http.StatusUnprocessableEntity,
+				BodyMatch:    `{"error":\s*"bad"}`,
+				HeadersMatch: map[string]string{"Content-Type": "application/json"},
+			},
+		}...)
+	})
+
 	t.Run("Single-file bundle with response hook", func(t *testing.T) {
 
 		keyID := gateway.CreateSession(func(s *user.SessionState) {
@@ -368,6 +638,38 @@ func TestPythonBundles(t *testing.T) {
 		}...)
 	})
 
+	t.Run("Single-file bundle with response hook rewriting headers and body", func(t *testing.T) {
+		gateway.BuildAndLoadAPI(func(spec *gateway.APISpec) {
+			spec.Proxy.ListenPath = "/test-api-5/"
+			spec.UseKeylessAccess = true
+			spec.EnableCoProcessAuth = false
+			spec.CustomMiddlewareBundle = responseTransformHookBundle
+			spec.VersionData.NotVersioned = true
+		})
+
+		time.Sleep(1 * time.Second)
+
+		ts.Run(t, []test.TestCase{
+			{Path: "/test-api-5/", Code: http.StatusOK, BodyMatch: `/TEST-API-5/`, HeadersMatch: map[string]string{"X-Processed": "true"}},
+		}...)
+	})
+
+	t.Run("Single-file bundle with pre hook rewriting a query param", func(t *testing.T) {
+		gateway.BuildAndLoadAPI(func(spec *gateway.APISpec) {
+			spec.Proxy.ListenPath = "/test-api-4/"
+			spec.UseKeylessAccess = true
+			spec.EnableCoProcessAuth = false
+			spec.CustomMiddlewareBundle = paramRewritePreHookBundle
+			spec.VersionData.NotVersioned = true
+		})
+
+		time.Sleep(1 * time.Second)
+
+		ts.Run(t, []test.TestCase{
+			{Path: "/test-api-4/?tenant=acme", Code: http.StatusOK, BodyMatch: "tenant=globex"},
+		}...)
+	})
+
 	t.Run("python post hook with url rewrite and method transform", func(t *testing.T) {
 		gateway.BuildAndLoadAPI(func(spec *gateway.APISpec) {
 			spec.Proxy.ListenPath = "/test-api-1/"
@@ -38,6 +38,38 @@ const (
 	Definition
 	RequestStatus
 	GraphQLRequest
+	AnalyticsDetailOverride
+	RetryBudget
+	PresentedScopes
+	CacheBackendOverride
+	SessionRequestRate
+	UpstreamProtocolOverride
+	TerminationReason
+	CORSDecision
+	ResponseHeaderOrder
+	AddTagsOverride
+	RemoveTagsOverride
+	BodyBufferSkipped
+	UpstreamAffinityKey
+	AuthLatencyStart
+	AuthLatency
+	AllowedContentTypes
+	CustomRateLimitKey
+	GraphQLAnalyticsSignature
+	GraphQLAnalyticsStripVariables
+	UpstreamIdempotencyKey
+	TargetWeights
+	CacheVaryHeaders
+	RetainTrace
+	RateLimitTemplateVars
+	RetryAfter
+	StreamMultiplexInfo
+	AnalyticsRequestBodyOverride
+	UpstreamPathPrefixOverride
+	SkipMiddleware
+	UpstreamTimeoutOverride
+	RequestReceivedAt
+	RequestDeadline
 )
 
 func setContext(r *http.Request, ctx context.Context) {
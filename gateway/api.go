@@ -3,24 +3,24 @@
 // The code below describes the Tyk Gateway API
 // Version: 2.8.0
 //
-//     Schemes: https, http
-//     Host: localhost
-//     BasePath: /tyk/
+//	Schemes: https, http
+//	Host: localhost
+//	BasePath: /tyk/
 //
-//     Consumes:
-//     - application/json
+//	Consumes:
+//	- application/json
 //
-//     Produces:
-//     - application/json
+//	Produces:
+//	- application/json
 //
-//     Security:
-//     - api_key:
+//	Security:
+//	- api_key:
 //
-//     SecurityDefinitions:
-//     api_key:
-//          type: apiKey
-//          name: X-Tyk-Authorization
-//          in: header
+//	SecurityDefinitions:
+//	api_key:
+//	     type: apiKey
+//	     name: X-Tyk-Authorization
+//	     in: header
 //
 // swagger:meta
 package gateway
@@ -1205,7 +1205,6 @@ func groupResetHandler(w http.ResponseWriter, r *http.Request) {
 // was in the URL parameters, it will block until the reload is done.
 // Otherwise, it won't block and fn will be called once the reload is
 // finished.
-//
 func resetHandler(fn func()) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var wg sync.WaitGroup
@@ -2503,6 +2502,476 @@ func ctxGetRequestStatus(r *http.Request) (stat RequestStatus) {
 	return
 }
 
+// ctxSetAnalyticsDetailOverride lets a pre/auth hook override the static
+// analytics detail-recording config for this request only.
+func ctxSetAnalyticsDetailOverride(r *http.Request, detailed bool) {
+	setCtxValue(r, ctx.AnalyticsDetailOverride, detailed)
+}
+
+// ctxGetAnalyticsDetailOverride returns the hook-provided override and
+// whether one was set at all.
+func ctxGetAnalyticsDetailOverride(r *http.Request) (detailed bool, ok bool) {
+	if v := r.Context().Value(ctx.AnalyticsDetailOverride); v != nil {
+		return v.(bool), true
+	}
+	return false, false
+}
+
+// ctxSetRetryBudget records how many extra upstream retries a pre hook has
+// allowed for this request, already capped at the operator maximum.
+func ctxSetRetryBudget(r *http.Request, n int) {
+	setCtxValue(r, ctx.RetryBudget, n)
+}
+
+func ctxGetRetryBudget(r *http.Request) int {
+	if v := r.Context().Value(ctx.RetryBudget); v != nil {
+		return v.(int)
+	}
+	return 0
+}
+
+// ctxSetUpstreamTimeoutOverride records a pre hook's requested upstream
+// timeout for this request, in seconds, already capped at the API's own
+// hard-timeout/default maximum.
+func ctxSetUpstreamTimeoutOverride(r *http.Request, seconds float64) {
+	setCtxValue(r, ctx.UpstreamTimeoutOverride, seconds)
+}
+
+func ctxGetUpstreamTimeoutOverride(r *http.Request) float64 {
+	if v := r.Context().Value(ctx.UpstreamTimeoutOverride); v != nil {
+		return v.(float64)
+	}
+	return 0
+}
+
+// ctxSetRequestReceivedAt records when the gateway first started processing
+// this request, so hooks can tell how much of a configured timeout budget
+// is already spent. It is surfaced to hooks as a wall-clock unix-nanosecond
+// value, not a monotonic reading.
+func ctxSetRequestReceivedAt(r *http.Request, t time.Time) {
+	setCtxValue(r, ctx.RequestReceivedAt, t)
+}
+
+func ctxGetRequestReceivedAt(r *http.Request) time.Time {
+	if v := r.Context().Value(ctx.RequestReceivedAt); v != nil {
+		return v.(time.Time)
+	}
+	return time.Time{}
+}
+
+// ctxSetRequestDeadline records the point by which this request is expected
+// to finish, derived from the API's own hard-timeout/default configuration,
+// so a post hook can skip expensive enrichment on a request that's already
+// blown its budget. Zero means no deadline is configured.
+func ctxSetRequestDeadline(r *http.Request, t time.Time) {
+	setCtxValue(r, ctx.RequestDeadline, t)
+}
+
+func ctxGetRequestDeadline(r *http.Request) time.Time {
+	if v := r.Context().Value(ctx.RequestDeadline); v != nil {
+		return v.(time.Time)
+	}
+	return time.Time{}
+}
+
+// ctxSetPresentedScopes records the OAuth/OIDC scopes a validated token
+// presented, so downstream per-endpoint scope checks don't need to
+// re-parse the token.
+func ctxSetPresentedScopes(r *http.Request, scopes []string) {
+	setCtxValue(r, ctx.PresentedScopes, scopes)
+}
+
+func ctxGetPresentedScopes(r *http.Request) []string {
+	if v := r.Context().Value(ctx.PresentedScopes); v != nil {
+		return v.([]string)
+	}
+	return nil
+}
+
+func ctxSetCacheBackendOverride(r *http.Request, backend string) {
+	setCtxValue(r, ctx.CacheBackendOverride, backend)
+}
+
+func ctxGetCacheBackendOverride(r *http.Request) string {
+	if v := r.Context().Value(ctx.CacheBackendOverride); v != nil {
+		return v.(string)
+	}
+	return ""
+}
+
+func ctxSetSessionRequestRate(r *http.Request, rate float64) {
+	setCtxValue(r, ctx.SessionRequestRate, rate)
+}
+
+func ctxGetSessionRequestRate(r *http.Request) float64 {
+	if v := r.Context().Value(ctx.SessionRequestRate); v != nil {
+		return v.(float64)
+	}
+	return 0
+}
+
+// Termination reasons exposed to hooks as the "termination_reason" entry of
+// object.Spec (see BuildObject in coprocess.go). Values are stable strings so dashboards
+// built against them don't break across releases.
+const (
+	TerminationNormal           = "normal"
+	TerminationClientDisconnect = "client_disconnect"
+	TerminationUpstreamTimeout  = "upstream_timeout"
+	TerminationUpstreamError    = "upstream_error"
+	TerminationRateLimited      = "rate_limited"
+	TerminationAuthFailed       = "auth_failed"
+)
+
+func ctxSetTerminationReason(r *http.Request, reason string) {
+	setCtxValue(r, ctx.TerminationReason, reason)
+}
+
+func ctxGetTerminationReason(r *http.Request) string {
+	if v := r.Context().Value(ctx.TerminationReason); v != nil {
+		return v.(string)
+	}
+	return TerminationNormal
+}
+
+func ctxSetUpstreamProtocolOverride(r *http.Request, protocol string) {
+	setCtxValue(r, ctx.UpstreamProtocolOverride, protocol)
+}
+
+func ctxGetUpstreamProtocolOverride(r *http.Request) string {
+	if v := r.Context().Value(ctx.UpstreamProtocolOverride); v != nil {
+		return v.(string)
+	}
+	return ""
+}
+
+// CORS decisions exposed to hooks as the "cors_decision" entry of
+// object.Spec (see BuildObject in coprocess.go). CORSNotApplicable covers requests with no
+// Origin header, where allow/deny has no meaning.
+const (
+	CORSNotApplicable = "not_cors"
+	CORSAllowed       = "allowed"
+	CORSDenied        = "denied"
+)
+
+func ctxSetCORSDecision(r *http.Request, decision string) {
+	setCtxValue(r, ctx.CORSDecision, decision)
+}
+
+func ctxGetCORSDecision(r *http.Request) string {
+	if v := r.Context().Value(ctx.CORSDecision); v != nil {
+		return v.(string)
+	}
+	return CORSNotApplicable
+}
+
+// ctxSetResponseHeaderOrder/ctxGetResponseHeaderOrder carry a response
+// hook's requested header casing/order (see CustomMiddlewareResponseHook and
+// writeOrderedResponse) from where the hook runs through to where the
+// gateway actually writes the response.
+func ctxSetResponseHeaderOrder(r *http.Request, order []string) {
+	setCtxValue(r, ctx.ResponseHeaderOrder, order)
+}
+
+func ctxGetResponseHeaderOrder(r *http.Request) []string {
+	if v := r.Context().Value(ctx.ResponseHeaderOrder); v != nil {
+		return v.([]string)
+	}
+	return nil
+}
+
+// ctxAddAnalyticsTags/ctxRemoveAnalyticsTags/ctxGetAnalyticsTagOverrides carry
+// a hook's requested add_tags/remove_tags (see ReturnOverrides) from wherever
+// the hook runs through to SuccessHandler.RecordHit, which applies them last,
+// on top of the session and API's own tags. Hooks may run more than once per
+// request (e.g. several Pre hooks, then a Post hook), so each call
+// accumulates onto whatever an earlier hook already requested rather than
+// overwriting it.
+func ctxAddAnalyticsTags(r *http.Request, tags []string) {
+	add, _ := ctxGetAnalyticsTagOverrides(r)
+	setCtxValue(r, ctx.AddTagsOverride, append(add, tags...))
+}
+
+func ctxRemoveAnalyticsTags(r *http.Request, tags []string) {
+	_, remove := ctxGetAnalyticsTagOverrides(r)
+	setCtxValue(r, ctx.RemoveTagsOverride, append(remove, tags...))
+}
+
+func ctxGetAnalyticsTagOverrides(r *http.Request) (add, remove []string) {
+	if v := r.Context().Value(ctx.AddTagsOverride); v != nil {
+		add = v.([]string)
+	}
+	if v := r.Context().Value(ctx.RemoveTagsOverride); v != nil {
+		remove = v.([]string)
+	}
+	return add, remove
+}
+
+// ctxSetBodyBufferSkipped/ctxGetBodyBufferSkipped record that a coprocess
+// hook's request body was left unbuffered because it crossed
+// MiddlewareDefinition.MaxBufferedBodyBytes (see CoProcessor.BuildObject),
+// so CoProcessor.ObjectPostProcess knows not to overwrite the request's body
+// with the (empty) one the hook saw.
+func ctxSetBodyBufferSkipped(r *http.Request, skipped bool) {
+	setCtxValue(r, ctx.BodyBufferSkipped, skipped)
+}
+
+func ctxGetBodyBufferSkipped(r *http.Request) bool {
+	if v := r.Context().Value(ctx.BodyBufferSkipped); v != nil {
+		return v.(bool)
+	}
+	return false
+}
+
+// ctxSetUpstreamAffinityKey/ctxGetUpstreamAffinityKey carry a hook's
+// requested sticky-session key (see ReturnOverrides.AffinityKey) from where
+// the hook runs through to the reverse proxy's upstream selection.
+func ctxSetUpstreamAffinityKey(r *http.Request, key string) {
+	setCtxValue(r, ctx.UpstreamAffinityKey, key)
+}
+
+func ctxGetUpstreamAffinityKey(r *http.Request) string {
+	if v := r.Context().Value(ctx.UpstreamAffinityKey); v != nil {
+		return v.(string)
+	}
+	return ""
+}
+
+// ctxSetAuthLatencyStart/ctxSetAuthLatency/ctxGetAuthLatencyMs bracket the
+// auth stage of the middleware chain (whichever key/JWT/OAuth/HMAC/OpenID/
+// coprocess auth_check method is actually enabled for the API), so hooks
+// downstream of auth can read back how long it took via
+// object.Spec["auth_latency_ms"]. Never set for a keyless API, so the getter
+// correctly reports 0 for those requests.
+func ctxSetAuthLatencyStart(r *http.Request, t time.Time) {
+	setCtxValue(r, ctx.AuthLatencyStart, t)
+}
+
+func ctxGetAuthLatencyStart(r *http.Request) (time.Time, bool) {
+	if v := r.Context().Value(ctx.AuthLatencyStart); v != nil {
+		return v.(time.Time), true
+	}
+	return time.Time{}, false
+}
+
+func ctxSetAuthLatency(r *http.Request, d time.Duration) {
+	setCtxValue(r, ctx.AuthLatency, d)
+}
+
+func ctxGetAuthLatencyMs(r *http.Request) int64 {
+	if v := r.Context().Value(ctx.AuthLatency); v != nil {
+		return v.(time.Duration).Milliseconds()
+	}
+	return 0
+}
+
+// ctxSetAllowedContentTypes/ctxGetAllowedContentTypes carry a pre hook's
+// requested Content-Type allow-list (see ReturnOverrides.AllowedContentTypes)
+// from where the hook runs through to ContentTypeAllowListMiddleware.
+func ctxSetAllowedContentTypes(r *http.Request, types []string) {
+	setCtxValue(r, ctx.AllowedContentTypes, types)
+}
+
+func ctxGetAllowedContentTypes(r *http.Request) []string {
+	if v := r.Context().Value(ctx.AllowedContentTypes); v != nil {
+		return v.([]string)
+	}
+	return nil
+}
+
+// ctxSetCustomRateLimitKey/ctxGetCustomRateLimitKey carry an auth or pre
+// hook's requested composite rate-limit key (see
+// ReturnOverrides.RateLimitKey) through to SessionLimiter, which namespaces
+// it to keep it distinct from the default per-session key.
+func ctxSetCustomRateLimitKey(r *http.Request, key string) {
+	setCtxValue(r, ctx.CustomRateLimitKey, key)
+}
+
+func ctxGetCustomRateLimitKey(r *http.Request) string {
+	if v := r.Context().Value(ctx.CustomRateLimitKey); v != nil {
+		return v.(string)
+	}
+	return ""
+}
+
+// ctxSetGraphQLAnalyticsSignature lets a hook replace the GraphQL query
+// stored in detailed analytics with a normalized/hashed signature of its
+// own computation (see ReturnOverrides.GraphqlAnalyticsSignature).
+func ctxSetGraphQLAnalyticsSignature(r *http.Request, signature string) {
+	setCtxValue(r, ctx.GraphQLAnalyticsSignature, signature)
+}
+
+func ctxGetGraphQLAnalyticsSignature(r *http.Request) string {
+	if v := r.Context().Value(ctx.GraphQLAnalyticsSignature); v != nil {
+		return v.(string)
+	}
+	return ""
+}
+
+func ctxSetGraphQLAnalyticsStripVariables(r *http.Request, strip bool) {
+	setCtxValue(r, ctx.GraphQLAnalyticsStripVariables, strip)
+}
+
+func ctxGetGraphQLAnalyticsStripVariables(r *http.Request) bool {
+	if v := r.Context().Value(ctx.GraphQLAnalyticsStripVariables); v != nil {
+		return v.(bool)
+	}
+	return false
+}
+
+// ctxSetUpstreamIdempotencyKey lets a pre hook set the idempotency key the
+// gateway sends to the upstream for this request (see
+// ReturnOverrides.UpstreamIdempotencyKey). Set once before the upstream
+// round trip starts, so every gateway-initiated retry of the same request
+// reuses the exact same key rather than regenerating one.
+func ctxSetUpstreamIdempotencyKey(r *http.Request, key string) {
+	setCtxValue(r, ctx.UpstreamIdempotencyKey, key)
+}
+
+func ctxGetUpstreamIdempotencyKey(r *http.Request) string {
+	if v := r.Context().Value(ctx.UpstreamIdempotencyKey); v != nil {
+		return v.(string)
+	}
+	return ""
+}
+
+// ctxSetTargetWeights lets a pre hook steer this request's load-balanced
+// target pick (see ReturnOverrides.TargetWeights / weightedTarget).
+func ctxSetTargetWeights(r *http.Request, weights map[string]float64) {
+	setCtxValue(r, ctx.TargetWeights, weights)
+}
+
+func ctxGetTargetWeights(r *http.Request) map[string]float64 {
+	if v := r.Context().Value(ctx.TargetWeights); v != nil {
+		return v.(map[string]float64)
+	}
+	return nil
+}
+
+// ctxSetCacheVaryHeaders lets a pre hook widen which request headers the
+// response cache's key varies on for this request, beyond the API's static
+// CacheOptions.CacheByHeaders (see ReturnOverrides.CacheVaryHeaders).
+func ctxSetCacheVaryHeaders(r *http.Request, hdrs []string) {
+	setCtxValue(r, ctx.CacheVaryHeaders, hdrs)
+}
+
+func ctxGetCacheVaryHeaders(r *http.Request) []string {
+	if v := r.Context().Value(ctx.CacheVaryHeaders); v != nil {
+		return v.([]string)
+	}
+	return nil
+}
+
+// ctxSetRetainTrace lets a response/error hook force this request's trace to
+// be exported even though the head sampler dropped it (see
+// ReturnOverrides.RetainTrace).
+func ctxSetRetainTrace(r *http.Request) {
+	setCtxValue(r, ctx.RetainTrace, true)
+}
+
+func ctxGetRetainTrace(r *http.Request) bool {
+	if v := r.Context().Value(ctx.RetainTrace); v != nil {
+		return v.(bool)
+	}
+	return false
+}
+
+// ctxSetRateLimitTemplateVars lets a pre hook supply extra values for the
+// "error_ratelimit" template rendered on a rate-limit rejection (see
+// ReturnOverrides.RateLimitTemplateVars).
+func ctxSetRateLimitTemplateVars(r *http.Request, vars map[string]string) {
+	setCtxValue(r, ctx.RateLimitTemplateVars, vars)
+}
+
+func ctxGetRateLimitTemplateVars(r *http.Request) map[string]string {
+	if v := r.Context().Value(ctx.RateLimitTemplateVars); v != nil {
+		return v.(map[string]string)
+	}
+	return nil
+}
+
+// ctxSetRetryAfter lets a pre hook supply a dynamic Retry-After value (see
+// ReturnOverrides.RetryAfter) for the gateway's own circuit-breaker 503,
+// which doesn't otherwise go through ReturnOverrides at all. value is
+// assumed already validated (a non-negative delta-seconds string or an
+// http.TimeFormat-encoded date) by the caller.
+func ctxSetRetryAfter(r *http.Request, value string) {
+	setCtxValue(r, ctx.RetryAfter, value)
+}
+
+func ctxGetRetryAfter(r *http.Request) string {
+	if v := r.Context().Value(ctx.RetryAfter); v != nil {
+		return v.(string)
+	}
+	return ""
+}
+
+// ctxSetStreamMultiplexInfo records this request's HTTP/2 stream id and
+// connection request count (see connMultiplexTracker), so downstream
+// CoProcess dispatch can surface them without re-deriving them per hook.
+func ctxSetStreamMultiplexInfo(r *http.Request, info connMultiplexInfo) {
+	setCtxValue(r, ctx.StreamMultiplexInfo, info)
+}
+
+func ctxGetStreamMultiplexInfo(r *http.Request) connMultiplexInfo {
+	if v := r.Context().Value(ctx.StreamMultiplexInfo); v != nil {
+		return v.(connMultiplexInfo)
+	}
+	return connMultiplexInfo{StreamID: -1, ConnectionRequestCount: 1}
+}
+
+// ctxSetAnalyticsRequestBodyOverride lets a pre hook keep the original,
+// unmasked request body in the detailed analytics record even though it
+// rewrote the body that's actually forwarded upstream into a masked form
+// (see ReturnOverrides.AnalyticsRequestBody).
+func ctxSetAnalyticsRequestBodyOverride(r *http.Request, body []byte) {
+	setCtxValue(r, ctx.AnalyticsRequestBodyOverride, body)
+}
+
+func ctxGetAnalyticsRequestBodyOverride(r *http.Request) []byte {
+	if v := r.Context().Value(ctx.AnalyticsRequestBodyOverride); v != nil {
+		return v.([]byte)
+	}
+	return nil
+}
+
+// upstreamPathPrefixOverride is a pre hook's request to reshape the
+// upstream-bound path beyond what StripListenPath/url_rewrites express -
+// see ReturnOverrides.UpstreamPathPrefixStrip/UpstreamPathPrefixPrepend for
+// precedence.
+type upstreamPathPrefixOverride struct {
+	Strip   string
+	Prepend string
+}
+
+// ctxSetUpstreamPathPrefixOverride records a pre hook's dynamic upstream
+// path prefix strip/prepend, applied by the reverse proxy's Director once
+// it's settled on the final request path (after StripListenPath, and only
+// when no url_rewrite has already finalised the path).
+func ctxSetUpstreamPathPrefixOverride(r *http.Request, strip, prepend string) {
+	setCtxValue(r, ctx.UpstreamPathPrefixOverride, upstreamPathPrefixOverride{Strip: strip, Prepend: prepend})
+}
+
+func ctxGetUpstreamPathPrefixOverride(r *http.Request) (o upstreamPathPrefixOverride, ok bool) {
+	if v := r.Context().Value(ctx.UpstreamPathPrefixOverride); v != nil {
+		return v.(upstreamPathPrefixOverride), true
+	}
+	return upstreamPathPrefixOverride{}, false
+}
+
+// ctxSetSkipMiddleware records the (already-sanitized) set of middleware
+// names an early hook asked to be skipped for this request, via
+// ReturnOverrides.SkipMiddleware - see sanitizeSkipMiddlewareNames.
+func ctxSetSkipMiddleware(r *http.Request, skip map[string]bool) {
+	setCtxValue(r, ctx.SkipMiddleware, skip)
+}
+
+func ctxGetSkipMiddleware(r *http.Request) map[string]bool {
+	if v := r.Context().Value(ctx.SkipMiddleware); v != nil {
+		return v.(map[string]bool)
+	}
+	return nil
+}
+
 func createOauthClientSecret() string {
 	secret := uuid.NewV4()
 	return base64.StdEncoding.EncodeToString([]byte(secret.String()))
@@ -27,13 +27,14 @@ import (
 	"github.com/TykTechnologies/gojsonschema"
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/goplugin"
 	"github.com/TykTechnologies/tyk/headers"
 	"github.com/TykTechnologies/tyk/regexp"
 	"github.com/TykTechnologies/tyk/rpc"
 	"github.com/TykTechnologies/tyk/storage"
 )
 
-//const used by cache middleware
+// const used by cache middleware
 const SAFE_METHODS = "SAFE_METHODS"
 
 const (
@@ -76,6 +77,8 @@ const (
 	RequestNotTracked
 	ValidateJSONRequest
 	Internal
+	RequiredScope
+	CoProcessBypass
 )
 
 // RequestStatus is a custom type to avoid collisions
@@ -108,6 +111,8 @@ const (
 	StatusRequestNotTracked        RequestStatus = "Request Not Tracked"
 	StatusValidateJSON             RequestStatus = "Validate JSON"
 	StatusInternal                 RequestStatus = "Internal path"
+	StatusScopeRequired            RequestStatus = "Requires OAuth scope"
+	StatusCoProcessBypass          RequestStatus = "CoProcess hook bypassed for path"
 )
 
 // URLSpec represents a flattened specification for URLs, used to check if a proxy URL
@@ -134,6 +139,8 @@ type URLSpec struct {
 	DoNotTrackEndpoint        apidef.TrackEndpointMeta
 	ValidatePathMeta          apidef.ValidatePathMeta
 	Internal                  apidef.InternalMeta
+	RequiredScopes            apidef.ScopeMeta
+	BypassCoProcess           apidef.TrackEndpointMeta
 	IgnoreCase                bool
 }
 
@@ -176,22 +183,46 @@ type APISpec struct {
 	LastGoodHostList         *apidef.HostList
 	HasRun                   bool
 	ServiceRefreshInProgress bool
-	HTTPTransport            *TykRoundTripper
-	HTTPTransportCreated     time.Time
-	WSTransport              http.RoundTripper
-	WSTransportCreated       time.Time
-	GlobalConfig             config.Config
-	OrgHasNoSession          bool
+
+	// ListenPathCollision is precomputed once at load time (see processSpec)
+	// so hooks can cheaply ask whether this API's listen path had to be
+	// deduplicated against another API on the same domain, rather than
+	// recomputing collisions per request. False when routing is unambiguous.
+	ListenPathCollision  bool
+	HTTPTransport        *TykRoundTripper
+	HTTPTransportCreated time.Time
+	WSTransport          http.RoundTripper
+	WSTransportCreated   time.Time
+	GlobalConfig         config.Config
+	OrgHasNoSession      bool
 
 	middlewareChain *ChainObject
 
 	network NetworkStats
 
+	// InFlightRequests counts requests currently inside this API's middleware
+	// chain, for hook-driven adaptive load shedding (see object.Spec's
+	// in_flight_requests and trackInFlightRequests). Always updated via
+	// sync/atomic so a hook's read never blocks a request in flight.
+	InFlightRequests int64
+
 	GraphQLExecutor struct {
 		Engine *graphql.ExecutionEngine
 		Client *http.Client
 		Schema *graphql.Schema
 	}
+
+	// UpstreamErrorRate is a sliding-window error counter for this API's
+	// upstream, exposed to hooks via object.Spec so a plugin can implement
+	// its own circuit-breaking logic without configuring a per-path
+	// CircuitBreakerMeta. See upstream_error_rate.go.
+	UpstreamErrorRate *upstreamErrorRateTracker
+
+	// ScopeValidator is loaded from ScopeValidatorHook, if configured, the
+	// same way Oauth2Meta.AuthorizeHook is loaded into OAuthManager. Nil
+	// means ScopeCheck falls back to requiring presented to be a superset
+	// of required.
+	ScopeValidator goplugin.ScopeValidatorFunc
 }
 
 // Release re;leases all resources associated with API spec
@@ -269,6 +300,8 @@ func (a APIDefinitionLoader) MakeSpec(def *apidef.APIDefinition, logger *logrus.
 		APIID: spec.APIID,
 	}
 
+	spec.UpstreamErrorRate = &upstreamErrorRateTracker{}
+
 	// Add any new session managers or auth handlers here
 	spec.AuthManager = &DefaultAuthorisationManager{}
 
@@ -882,6 +915,20 @@ func (a APIDefinitionLoader) compileValidateJSONPathspathSpec(paths []apidef.Val
 	return urlSpec
 }
 
+func (a APIDefinitionLoader) compileScopePathSpec(paths []apidef.ScopeMeta, stat URLStatus) []URLSpec {
+	urlSpec := make([]URLSpec, len(paths))
+
+	for i, stringSpec := range paths {
+		newSpec := URLSpec{}
+		a.generateRegex(stringSpec.Path, &newSpec, stat)
+		// Extend with method actions
+		newSpec.RequiredScopes = stringSpec
+		urlSpec[i] = newSpec
+	}
+
+	return urlSpec
+}
+
 func (a APIDefinitionLoader) compileUnTrackedEndpointPathspathSpec(paths []apidef.TrackEndpointMeta, stat URLStatus) []URLSpec {
 	urlSpec := []URLSpec{}
 
@@ -896,6 +943,20 @@ func (a APIDefinitionLoader) compileUnTrackedEndpointPathspathSpec(paths []apide
 	return urlSpec
 }
 
+func (a APIDefinitionLoader) compileBypassCoProcessPathSpec(paths []apidef.TrackEndpointMeta, stat URLStatus) []URLSpec {
+	urlSpec := []URLSpec{}
+
+	for _, stringSpec := range paths {
+		newSpec := URLSpec{}
+		a.generateRegex(stringSpec.Path, &newSpec, stat)
+		// Extend with method actions
+		newSpec.BypassCoProcess = stringSpec
+		urlSpec = append(urlSpec, newSpec)
+	}
+
+	return urlSpec
+}
+
 func (a APIDefinitionLoader) compileInternalPathspathSpec(paths []apidef.InternalMeta, stat URLStatus) []URLSpec {
 	urlSpec := []URLSpec{}
 
@@ -933,6 +994,8 @@ func (a APIDefinitionLoader) getExtendedPathSpecs(apiVersionDef apidef.VersionIn
 	unTrackedPaths := a.compileUnTrackedEndpointPathspathSpec(apiVersionDef.ExtendedPaths.DoNotTrackEndpoints, RequestNotTracked)
 	validateJSON := a.compileValidateJSONPathspathSpec(apiVersionDef.ExtendedPaths.ValidateJSON, ValidateJSONRequest)
 	internalPaths := a.compileInternalPathspathSpec(apiVersionDef.ExtendedPaths.Internal, Internal)
+	requiredScopes := a.compileScopePathSpec(apiVersionDef.ExtendedPaths.RequiredScopes, RequiredScope)
+	bypassCoProcessPaths := a.compileBypassCoProcessPathSpec(apiVersionDef.ExtendedPaths.BypassCoProcess, CoProcessBypass)
 
 	combinedPath := []URLSpec{}
 	combinedPath = append(combinedPath, ignoredPaths...)
@@ -955,6 +1018,8 @@ func (a APIDefinitionLoader) getExtendedPathSpecs(apiVersionDef apidef.VersionIn
 	combinedPath = append(combinedPath, unTrackedPaths...)
 	combinedPath = append(combinedPath, validateJSON...)
 	combinedPath = append(combinedPath, internalPaths...)
+	combinedPath = append(combinedPath, requiredScopes...)
+	combinedPath = append(combinedPath, bypassCoProcessPaths...)
 
 	return combinedPath, len(whiteListPaths) > 0
 }
@@ -1011,6 +1076,10 @@ func (a *APISpec) getURLStatus(stat URLStatus) RequestStatus {
 		return StatusValidateJSON
 	case Internal:
 		return StatusInternal
+	case RequiredScope:
+		return StatusScopeRequired
+	case CoProcessBypass:
+		return StatusCoProcessBypass
 
 	default:
 		log.Error("URL Status was not one of Ignored, Blacklist or WhiteList! Blocking.")
@@ -1188,6 +1257,10 @@ func (a *APISpec) CheckSpecMatchesStatus(r *http.Request, rxPaths []URLSpec, mod
 			if method == rxPaths[i].DoNotTrackEndpoint.Method {
 				return true, &rxPaths[i].DoNotTrackEndpoint
 			}
+		case CoProcessBypass:
+			if method == rxPaths[i].BypassCoProcess.Method {
+				return true, &rxPaths[i].BypassCoProcess
+			}
 		case ValidateJSONRequest:
 			if method == rxPaths[i].ValidatePathMeta.Method {
 				return true, &rxPaths[i].ValidatePathMeta
@@ -1196,6 +1269,10 @@ func (a *APISpec) CheckSpecMatchesStatus(r *http.Request, rxPaths []URLSpec, mod
 			if method == rxPaths[i].Internal.Method {
 				return true, &rxPaths[i].Internal
 			}
+		case RequiredScope:
+			if method == rxPaths[i].RequiredScopes.Method {
+				return true, &rxPaths[i].RequiredScopes
+			}
 		}
 	}
 	return false, nil
@@ -1315,7 +1392,17 @@ func (a *APISpec) Version(r *http.Request) (*apidef.VersionInfo, []URLSpec, bool
 			// Load Version Data - General
 			var ok bool
 			if version, ok = a.VersionData.Versions[vName]; !ok {
-				return &version, nil, false, VersionDoesNotExist
+				// An unknown value (as opposed to an absent one, handled
+				// above) can optionally fall back to the default version
+				// too, rather than rejecting the request outright.
+				if a.VersionDefinition.FallbackToDefault && a.VersionData.DefaultVersion != "" {
+					if version, ok = a.VersionData.Versions[a.VersionData.DefaultVersion]; ok {
+						ctxSetDefaultVersion(r)
+					}
+				}
+				if !ok {
+					return &version, nil, false, VersionDoesNotExist
+				}
 			}
 		}
 
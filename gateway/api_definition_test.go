@@ -707,6 +707,54 @@ func TestGetVersionFromRequest(t *testing.T) {
 	})
 }
 
+func TestGetVersionFromRequest_HeaderUnknownValue(t *testing.T) {
+	ts := StartTest()
+	defer ts.Close()
+
+	v1 := apidef.VersionInfo{Name: "v1"}
+	v1.Paths.WhiteList = []string{"/v1-only"}
+
+	v2 := apidef.VersionInfo{Name: "v2"}
+	v2.Paths.WhiteList = []string{"/v2-only"}
+
+	t.Run("rejects by default", func(t *testing.T) {
+		BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Proxy.ListenPath = "/"
+			spec.VersionData.NotVersioned = false
+			spec.VersionDefinition.Location = headerLocation
+			spec.VersionDefinition.Key = "Api-Version"
+			spec.VersionData.DefaultVersion = "v1"
+			spec.VersionData.Versions["v1"] = v1
+			spec.VersionData.Versions["v2"] = v2
+		})
+
+		ts.Run(t, []test.TestCase{
+			{Path: "/v1-only", Headers: map[string]string{"Api-Version": "v1"}, Code: http.StatusOK},
+			{Path: "/v2-only", Headers: map[string]string{"Api-Version": "v2"}, Code: http.StatusOK},
+			{Path: "/v1-only", Headers: map[string]string{"Api-Version": "v99"}, Code: http.StatusForbidden},
+			{Path: "/v1-only", Code: http.StatusOK}, // absent header already falls back to default
+		}...)
+	})
+
+	t.Run("falls back to default when configured", func(t *testing.T) {
+		BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Proxy.ListenPath = "/"
+			spec.VersionData.NotVersioned = false
+			spec.VersionDefinition.Location = headerLocation
+			spec.VersionDefinition.Key = "Api-Version"
+			spec.VersionDefinition.FallbackToDefault = true
+			spec.VersionData.DefaultVersion = "v1"
+			spec.VersionData.Versions["v1"] = v1
+			spec.VersionData.Versions["v2"] = v2
+		})
+
+		ts.Run(t, []test.TestCase{
+			{Path: "/v1-only", Headers: map[string]string{"Api-Version": "v99"}, Code: http.StatusOK},
+			{Path: "/v2-only", Headers: map[string]string{"Api-Version": "v99"}, Code: http.StatusForbidden},
+		}...)
+	})
+}
+
 func BenchmarkGetVersionFromRequest(b *testing.B) {
 	b.ReportAllocs()
 	ts := StartTest()
@@ -19,6 +19,7 @@ import (
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/coprocess"
+	"github.com/TykTechnologies/tyk/goplugin"
 	"github.com/TykTechnologies/tyk/storage"
 	"github.com/TykTechnologies/tyk/trace"
 )
@@ -135,6 +136,15 @@ func processSpec(spec *APISpec, apisByListen map[string]int,
 		return &chainDef
 	}
 
+	if spec.ScopeValidatorHook.Name != "" {
+		validator, err := goplugin.GetScopeValidator(spec.ScopeValidatorHook.Path, spec.ScopeValidatorHook.Name)
+		if err != nil {
+			logger.WithError(err).Error("Could not load scope validator hook")
+		} else {
+			spec.ScopeValidator = validator
+		}
+	}
+
 	// Expose API only to looping
 	if spec.Internal {
 		chainDef.Skip = true
@@ -165,6 +175,7 @@ func processSpec(spec *APISpec, apisByListen map[string]int,
 	if pathModified {
 		logger.Error("Listen path collision, changed to ", spec.Proxy.ListenPath)
 	}
+	spec.ListenPathCollision = pathModified
 
 	// Set up LB targets:
 	if spec.Proxy.EnableLoadBalancing {
@@ -240,6 +251,10 @@ func processSpec(spec *APISpec, apisByListen map[string]int,
 		addBatchEndpoint(spec, subrouter)
 	}
 
+	if spec.EnableStreamingFanoutSupport {
+		addStreamingFanoutEndpoint(spec, subrouter)
+	}
+
 	if spec.UseOauth2 {
 		logger.Debug("Loading OAuth Manager")
 		oauthManager := addOAuthHandlers(spec, subrouter)
@@ -295,8 +310,15 @@ func processSpec(spec *APISpec, apisByListen map[string]int,
 		logger.Info("Checking security policy: Open")
 	}
 
+	mwAppendEnabled(&chainArray, &RequestTimingMiddleware{baseMid})
+
 	handleCORS(&chainArray, spec)
 
+	// ValidateJSON runs ahead of the Pre coprocess hooks below, so a request
+	// body that fails schema validation is rejected before it's ever handed
+	// to a hook (e.g. a Python plugin).
+	mwAppendEnabled(&chainArray, &ValidateJSON{BaseMiddleware: baseMid})
+
 	for _, obj := range mwPreFuncs {
 		if mwDriver == apidef.GoPluginDriver {
 			mwAppendEnabled(
@@ -309,17 +331,19 @@ func processSpec(spec *APISpec, apisByListen map[string]int,
 			)
 		} else if mwDriver != apidef.OttoDriver {
 			coprocessLog.Debug("Registering coprocess middleware, hook name: ", obj.Name, "hook type: Pre", ", driver: ", mwDriver)
-			mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid, coprocess.HookType_Pre, obj.Name, mwDriver, obj.RawBodyOnly, nil})
+			mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid, coprocess.HookType_Pre, obj.Name, mwDriver, obj.RawBodyOnly, obj.EnableBodyTransformCache, obj.BodyTransformCacheTTL, obj.MaxBufferedBodyBytes, obj.MaxRequestBodySize, obj.HookTimeoutSeconds, nil})
 		} else {
 			chainArray = append(chainArray, createDynamicMiddleware(obj.Name, true, obj.RequireSession, baseMid))
 		}
 	}
 
+	mwAppendEnabled(&chainArray, &ContentTypeAllowListMiddleware{baseMid})
 	mwAppendEnabled(&chainArray, &VersionCheck{BaseMiddleware: baseMid})
 	mwAppendEnabled(&chainArray, &RateCheckMW{BaseMiddleware: baseMid})
 	mwAppendEnabled(&chainArray, &IPWhiteListMiddleware{BaseMiddleware: baseMid})
 	mwAppendEnabled(&chainArray, &IPBlackListMiddleware{BaseMiddleware: baseMid})
 	mwAppendEnabled(&chainArray, &CertificateCheckMW{BaseMiddleware: baseMid})
+	mwAppendEnabled(&chainArray, &PresignedURLCheck{baseMid})
 	mwAppendEnabled(&chainArray, &OrganizationMonitor{BaseMiddleware: baseMid})
 	mwAppendEnabled(&chainArray, &RequestSizeLimitMiddleware{baseMid})
 	mwAppendEnabled(&chainArray, &MiddlewareContextVars{BaseMiddleware: baseMid})
@@ -357,7 +381,7 @@ func processSpec(spec *APISpec, apisByListen map[string]int,
 			coprocessLog.Debug("Registering coprocess middleware, hook name: ", mwAuthCheckFunc.Name, "hook type: CustomKeyCheck", ", driver: ", mwDriver)
 
 			newExtractor(spec, baseMid)
-			mwAppendEnabled(&authArray, &CoProcessMiddleware{baseMid, coprocess.HookType_CustomKeyCheck, mwAuthCheckFunc.Name, mwDriver, mwAuthCheckFunc.RawBodyOnly, nil})
+			mwAppendEnabled(&authArray, &CoProcessMiddleware{baseMid, coprocess.HookType_CustomKeyCheck, mwAuthCheckFunc.Name, mwDriver, mwAuthCheckFunc.RawBodyOnly, false, 0, 0, mwAuthCheckFunc.MaxRequestBodySize, mwAuthCheckFunc.HookTimeoutSeconds, nil})
 		}
 
 		if ottoAuth {
@@ -382,7 +406,9 @@ func processSpec(spec *APISpec, apisByListen map[string]int,
 			authArray = append(authArray, createMiddleware(&AuthKey{baseMid}))
 		}
 
+		chainArray = append(chainArray, authLatencyStart)
 		chainArray = append(chainArray, authArray...)
+		chainArray = append(chainArray, authLatencyStop)
 
 		for _, obj := range mwPostAuthCheckFuncs {
 			if mwDriver == apidef.GoPluginDriver {
@@ -396,7 +422,7 @@ func processSpec(spec *APISpec, apisByListen map[string]int,
 				)
 			} else {
 				coprocessLog.Debug("Registering coprocess middleware, hook name: ", obj.Name, "hook type: Pre", ", driver: ", mwDriver)
-				mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid, coprocess.HookType_PostKeyAuth, obj.Name, mwDriver, obj.RawBodyOnly, nil})
+				mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid, coprocess.HookType_PostKeyAuth, obj.Name, mwDriver, obj.RawBodyOnly, obj.EnableBodyTransformCache, obj.BodyTransformCacheTTL, obj.MaxBufferedBodyBytes, obj.MaxRequestBodySize, obj.HookTimeoutSeconds, nil})
 			}
 		}
 
@@ -404,17 +430,18 @@ func processSpec(spec *APISpec, apisByListen map[string]int,
 		mwAppendEnabled(&chainArray, &KeyExpired{baseMid})
 		mwAppendEnabled(&chainArray, &AccessRightsCheck{baseMid})
 		mwAppendEnabled(&chainArray, &GranularAccessMiddleware{baseMid})
+		mwAppendEnabled(&chainArray, &ScopeCheck{baseMid})
 		mwAppendEnabled(&chainArray, &RateLimitAndQuotaCheck{baseMid})
 	}
 
 	mwAppendEnabled(&chainArray, &RateLimitForAPI{BaseMiddleware: baseMid})
-	mwAppendEnabled(&chainArray, &ValidateJSON{BaseMiddleware: baseMid})
 	mwAppendEnabled(&chainArray, &TransformMiddleware{baseMid})
 	mwAppendEnabled(&chainArray, &TransformJQMiddleware{baseMid})
 	mwAppendEnabled(&chainArray, &TransformHeaders{BaseMiddleware: baseMid})
 	mwAppendEnabled(&chainArray, &URLRewriteMiddleware{BaseMiddleware: baseMid})
 	mwAppendEnabled(&chainArray, &TransformMethod{BaseMiddleware: baseMid})
 	mwAppendEnabled(&chainArray, &VirtualEndpoint{BaseMiddleware: baseMid})
+	mwAppendEnabled(&chainArray, &CompositionMiddleware{BaseMiddleware: baseMid})
 	mwAppendEnabled(&chainArray, &RequestSigning{BaseMiddleware: baseMid})
 
 	for _, obj := range mwPostFuncs {
@@ -429,7 +456,7 @@ func processSpec(spec *APISpec, apisByListen map[string]int,
 			)
 		} else if mwDriver != apidef.OttoDriver {
 			coprocessLog.Debug("Registering coprocess middleware, hook name: ", obj.Name, "hook type: Post", ", driver: ", mwDriver)
-			mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid, coprocess.HookType_Post, obj.Name, mwDriver, obj.RawBodyOnly, nil})
+			mwAppendEnabled(&chainArray, &CoProcessMiddleware{baseMid, coprocess.HookType_Post, obj.Name, mwDriver, obj.RawBodyOnly, obj.EnableBodyTransformCache, obj.BodyTransformCacheTTL, obj.MaxBufferedBodyBytes, obj.MaxRequestBodySize, obj.HookTimeoutSeconds, nil})
 		} else {
 			chainArray = append(chainArray, createDynamicMiddleware(obj.Name, false, obj.RequireSession, baseMid))
 		}
@@ -465,6 +492,7 @@ func processSpec(spec *APISpec, apisByListen map[string]int,
 	} else {
 		chainDef.ThisHandler = chain
 	}
+	chainDef.ThisHandler = trackInFlightRequests(spec, chainDef.ThisHandler)
 	chainDef.ListenOn = spec.Proxy.ListenPath + "{rest:.*}"
 	chainDef.Domain = spec.Domain
 
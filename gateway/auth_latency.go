@@ -0,0 +1,29 @@
+package gateway
+
+import (
+	"net/http"
+	"time"
+)
+
+// authLatencyStart and authLatencyStop bracket the auth stage of an API's
+// middleware chain - whichever key/JWT/OAuth/HMAC/OpenID/coprocess
+// auth_check method is actually enabled - so the time it took, including a
+// coprocess auth_check hook's own execution time, can be surfaced to
+// downstream hooks. They're only inserted around a non-keyless API's
+// authArray (see api_loader.go), so a keyless request never sets a start
+// time and ctxGetAuthLatencyMs correctly reports 0 for it.
+func authLatencyStart(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxSetAuthLatencyStart(r, time.Now())
+		h.ServeHTTP(w, r)
+	})
+}
+
+func authLatencyStop(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if start, ok := ctxGetAuthLatencyStart(r); ok {
+			ctxSetAuthLatency(r, time.Since(start))
+		}
+		h.ServeHTTP(w, r)
+	})
+}
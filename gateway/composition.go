@@ -0,0 +1,144 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+// validateCompositionRoutes checks a bundle manifest's composition routes at
+// load time, so a malformed manifest is rejected up front instead of
+// surfacing as a confusing per-request failure once the API is live.
+func validateCompositionRoutes(routes []apidef.CompositionRoute) error {
+	for i, route := range routes {
+		if route.Path == "" {
+			return fmt.Errorf("composition route %d: path is required", i)
+		}
+		if route.Method == "" {
+			return fmt.Errorf("composition route %d (%s): method is required", i, route.Path)
+		}
+		if len(route.Stages) == 0 {
+			return fmt.Errorf("composition route %d (%s %s): at least one stage is required", i, route.Method, route.Path)
+		}
+
+		switch route.Merge {
+		case "", apidef.CompositionMergeObject, apidef.CompositionMergeArray:
+		default:
+			return fmt.Errorf("composition route %d (%s %s): unknown merge strategy %q", i, route.Method, route.Path, route.Merge)
+		}
+
+		switch route.OnFailure {
+		case "", apidef.CompositionFailFast, apidef.CompositionBestEffort:
+		default:
+			return fmt.Errorf("composition route %d (%s %s): unknown failure policy %q", i, route.Method, route.Path, route.OnFailure)
+		}
+
+		for j, stage := range route.Stages {
+			if len(stage.Calls) == 0 {
+				return fmt.Errorf("composition route %d (%s %s): stage %d has no calls", i, route.Method, route.Path, j)
+			}
+			for k, call := range stage.Calls {
+				if call.Name == "" {
+					return fmt.Errorf("composition route %d (%s %s): stage %d call %d: name is required", i, route.Method, route.Path, j, k)
+				}
+				if call.APIID == "" {
+					return fmt.Errorf("composition route %d (%s %s): stage %d call %q: api_id is required", i, route.Method, route.Path, j, call.Name)
+				}
+				if call.Path == "" {
+					return fmt.Errorf("composition route %d (%s %s): stage %d call %q: path is required", i, route.Method, route.Path, j, call.Name)
+				}
+				if call.Method == "" {
+					return fmt.Errorf("composition route %d (%s %s): stage %d call %q: method is required", i, route.Method, route.Path, j, call.Name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// compositionCallResult is one call's outcome, kept alongside its
+// declaration so merging can report a call's Name even on failure.
+type compositionCallResult struct {
+	call     apidef.CompositionCall
+	response *internalAPIResponse
+	err      error
+}
+
+// executeCompositionRoute runs every stage of route in order, fanning each
+// stage's calls out concurrently via makeInternalAPIRequest, and merges the
+// results per route.Merge. aborted is true if a fail_fast route stopped
+// after a stage in which a call failed - the caller should treat the response as an
+// error rather than a partial result.
+func executeCompositionRoute(route *apidef.CompositionRoute, body []byte) (merged []byte, aborted bool, err error) {
+	failurePolicy := route.OnFailure
+	if failurePolicy == "" {
+		failurePolicy = apidef.CompositionFailFast
+	}
+
+	var results []compositionCallResult
+
+	for _, stage := range route.Stages {
+		stageResults := make([]compositionCallResult, len(stage.Calls))
+
+		var wg sync.WaitGroup
+		for i, call := range stage.Calls {
+			wg.Add(1)
+			go func(i int, call apidef.CompositionCall) {
+				defer wg.Done()
+				resp, err := makeInternalAPIRequest(call.APIID, call.Path, call.Method, body)
+				stageResults[i] = compositionCallResult{call: call, response: resp, err: err}
+				if err == nil && resp.StatusCode >= 400 {
+					stageResults[i].err = fmt.Errorf("call %q returned status %d", call.Name, resp.StatusCode)
+				}
+			}(i, call)
+		}
+		wg.Wait()
+
+		results = append(results, stageResults...)
+
+		if failurePolicy == apidef.CompositionFailFast {
+			for _, r := range stageResults {
+				if r.err != nil {
+					return nil, true, r.err
+				}
+			}
+		}
+	}
+
+	merged, err = mergeCompositionResults(route.Merge, results)
+	return merged, false, err
+}
+
+func mergeCompositionResults(strategy apidef.CompositionMergeStrategy, results []compositionCallResult) ([]byte, error) {
+	decode := func(r compositionCallResult) interface{} {
+		if r.err != nil {
+			return map[string]string{"error": r.err.Error()}
+		}
+		var v interface{}
+		if err := json.Unmarshal([]byte(r.response.Body), &v); err != nil {
+			return r.response.Body
+		}
+		return v
+	}
+
+	switch strategy {
+	case apidef.CompositionMergeArray:
+		arr := make([]interface{}, len(results))
+		for i, r := range results {
+			arr[i] = decode(r)
+		}
+		return json.Marshal(arr)
+	case apidef.CompositionMergeObject, "":
+		obj := make(map[string]interface{}, len(results))
+		for _, r := range results {
+			obj[r.call.Name] = decode(r)
+		}
+		return json.Marshal(obj)
+	default:
+		return nil, errors.New("unknown merge strategy")
+	}
+}
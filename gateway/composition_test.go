@@ -0,0 +1,175 @@
+package gateway
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+func TestValidateCompositionRoutes(t *testing.T) {
+	validCall := apidef.CompositionCall{Name: "a", APIID: "test-api-2", Path: "/get", Method: "GET"}
+
+	tests := []struct {
+		name    string
+		routes  []apidef.CompositionRoute
+		wantErr bool
+	}{
+		{"empty is valid", nil, false},
+		{
+			"valid route",
+			[]apidef.CompositionRoute{{
+				Path: "/dashboard", Method: "GET",
+				Stages: []apidef.CompositionStage{{Calls: []apidef.CompositionCall{validCall}}},
+			}},
+			false,
+		},
+		{
+			"missing path",
+			[]apidef.CompositionRoute{{
+				Method: "GET",
+				Stages: []apidef.CompositionStage{{Calls: []apidef.CompositionCall{validCall}}},
+			}},
+			true,
+		},
+		{
+			"no stages",
+			[]apidef.CompositionRoute{{Path: "/dashboard", Method: "GET"}},
+			true,
+		},
+		{
+			"empty stage",
+			[]apidef.CompositionRoute{{
+				Path: "/dashboard", Method: "GET",
+				Stages: []apidef.CompositionStage{{}},
+			}},
+			true,
+		},
+		{
+			"call missing api_id",
+			[]apidef.CompositionRoute{{
+				Path: "/dashboard", Method: "GET",
+				Stages: []apidef.CompositionStage{{Calls: []apidef.CompositionCall{{Name: "a", Path: "/get", Method: "GET"}}}},
+			}},
+			true,
+		},
+		{
+			"unknown merge strategy",
+			[]apidef.CompositionRoute{{
+				Path: "/dashboard", Method: "GET",
+				Stages: []apidef.CompositionStage{{Calls: []apidef.CompositionCall{validCall}}},
+				Merge:  "nonsense",
+			}},
+			true,
+		},
+		{
+			"unknown failure policy",
+			[]apidef.CompositionRoute{{
+				Path: "/dashboard", Method: "GET",
+				Stages:    []apidef.CompositionStage{{Calls: []apidef.CompositionCall{validCall}}},
+				OnFailure: "nonsense",
+			}},
+			true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateCompositionRoutes(tc.routes)
+			if tc.wantErr && err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("expected no error, got: %s", err.Error())
+			}
+		})
+	}
+}
+
+// TestExecuteCompositionRoute checks that a route's stages run in order,
+// calls within a stage fan out concurrently via makeInternalAPIRequest, and
+// the results are merged per the declared strategy.
+func TestExecuteCompositionRoute(t *testing.T) {
+	ts := StartTest()
+	defer ts.Close()
+
+	BuildAndLoadAPI(
+		func(spec *APISpec) {
+			spec.APIID = "primary"
+			spec.Proxy.ListenPath = "/primary/"
+		},
+		func(spec *APISpec) {
+			spec.APIID = "test-api-2"
+			spec.Proxy.ListenPath = "/test-api-2/"
+		},
+	)
+
+	route := &apidef.CompositionRoute{
+		Path:   "/dashboard",
+		Method: "GET",
+		Stages: []apidef.CompositionStage{
+			{Calls: []apidef.CompositionCall{{Name: "get", APIID: "test-api-2", Path: "/get", Method: "GET"}}},
+		},
+		Merge: apidef.CompositionMergeObject,
+	}
+
+	merged, aborted, err := executeCompositionRoute(route, nil)
+	if err != nil {
+		t.Fatalf("executeCompositionRoute failed: %s", err.Error())
+	}
+	if aborted {
+		t.Fatal("did not expect the route to be aborted")
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(merged, &result); err != nil {
+		t.Fatalf("merged result isn't valid JSON: %s", err.Error())
+	}
+	if _, ok := result["get"]; !ok {
+		t.Fatalf("expected a \"get\" key in the merged result, got: %s", merged)
+	}
+
+	t.Run("fail_fast aborts on a failing call", func(t *testing.T) {
+		failing := &apidef.CompositionRoute{
+			Path:   "/dashboard",
+			Method: "GET",
+			Stages: []apidef.CompositionStage{
+				{Calls: []apidef.CompositionCall{{Name: "missing", APIID: "no-such-api", Path: "/get", Method: "GET"}}},
+			},
+		}
+
+		_, aborted, err := executeCompositionRoute(failing, nil)
+		if err == nil {
+			t.Fatal("expected an error from a failing call")
+		}
+		if !aborted {
+			t.Fatal("expected the fail_fast route to be aborted")
+		}
+	})
+
+	t.Run("best_effort merges failures instead of aborting", func(t *testing.T) {
+		mixed := &apidef.CompositionRoute{
+			Path:   "/dashboard",
+			Method: "GET",
+			Stages: []apidef.CompositionStage{
+				{Calls: []apidef.CompositionCall{
+					{Name: "ok", APIID: "test-api-2", Path: "/get", Method: "GET"},
+					{Name: "bad", APIID: "no-such-api", Path: "/get", Method: "GET"},
+				}},
+			},
+			OnFailure: apidef.CompositionBestEffort,
+		}
+
+		merged, aborted, err := executeCompositionRoute(mixed, nil)
+		if err != nil {
+			t.Fatalf("executeCompositionRoute failed: %s", err.Error())
+		}
+		if aborted {
+			t.Fatal("did not expect a best_effort route to be aborted")
+		}
+		if !strings.Contains(string(merged), `"bad"`) {
+			t.Fatalf("expected the failing call's name in the merged result, got: %s", merged)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package gateway
+
+import (
+	"net"
+	"net/http"
+	"sync"
+)
+
+// connMultiplexInfo is the stream_id/connection_request_count pair surfaced
+// to hooks via request.object for HTTP/2 diagnostics.
+type connMultiplexInfo struct {
+	// StreamID loosely mirrors HTTP/2's client-initiated stream numbering
+	// (odd, strictly increasing per connection) so requests sharing a
+	// connection get distinct, stable values. The standard library doesn't
+	// expose the real wire-level stream id, so this is synthesised rather
+	// than read off the h2 frame; it's -1 for HTTP/1.1, which never
+	// multiplexes requests onto one connection.
+	StreamID int64
+	// ConnectionRequestCount is the 1-based count of requests seen so far
+	// on this connection, accurate across HTTP/1.1 keep-alive reuse and
+	// HTTP/2 stream multiplexing alike.
+	ConnectionRequestCount int64
+}
+
+type connMultiplexState struct {
+	requestCount int64
+	nextStreamID int64
+}
+
+// connMultiplexTracker counts requests per connection, keyed by the
+// connection's remote address (unique for the lifetime of a TCP
+// connection), so pipelined/multiplexed requests sharing a connection can be
+// told apart without threading a custom net.Listener through every server.
+type connMultiplexTracker struct {
+	mu    sync.Mutex
+	conns map[string]*connMultiplexState
+}
+
+var sharedConnMultiplexTracker = &connMultiplexTracker{conns: map[string]*connMultiplexState{}}
+
+func (t *connMultiplexTracker) track(r *http.Request) connMultiplexInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cs, ok := t.conns[r.RemoteAddr]
+	if !ok {
+		cs = &connMultiplexState{nextStreamID: 1}
+		t.conns[r.RemoteAddr] = cs
+	}
+
+	cs.requestCount++
+	if r.ProtoMajor < 2 {
+		return connMultiplexInfo{StreamID: -1, ConnectionRequestCount: cs.requestCount}
+	}
+
+	info := connMultiplexInfo{StreamID: cs.nextStreamID, ConnectionRequestCount: cs.requestCount}
+	cs.nextStreamID += 2
+
+	return info
+}
+
+// forget drops any tracked state for a connection, called once it goes away
+// so the map doesn't grow unboundedly.
+func (t *connMultiplexTracker) forget(remoteAddr string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.conns, remoteAddr)
+}
+
+// trackConnState is an http.Server ConnState hook that forgets a
+// connection's multiplexing state once it's closed or hijacked.
+func trackConnState(conn net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateClosed, http.StateHijacked:
+		sharedConnMultiplexTracker.forget(conn.RemoteAddr().String())
+	}
+}
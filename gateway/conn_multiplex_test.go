@@ -0,0 +1,75 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConnMultiplexTracker(t *testing.T) {
+	t.Run("HTTP/1.1 always gets the sentinel stream id", func(t *testing.T) {
+		tracker := &connMultiplexTracker{conns: map[string]*connMultiplexState{}}
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = "10.0.0.1:1111"
+		r.ProtoMajor = 1
+
+		for i := 0; i < 3; i++ {
+			info := tracker.track(r)
+			if info.StreamID != -1 {
+				t.Errorf("expected sentinel stream id -1 for HTTP/1.1, got %d", info.StreamID)
+			}
+		}
+	})
+
+	t.Run("requests sharing an HTTP/2 connection get increasing stream ids and an accurate count", func(t *testing.T) {
+		tracker := &connMultiplexTracker{conns: map[string]*connMultiplexState{}}
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = "10.0.0.2:2222"
+		r.ProtoMajor = 2
+
+		first := tracker.track(r)
+		second := tracker.track(r)
+		third := tracker.track(r)
+
+		if first.ConnectionRequestCount != 1 || second.ConnectionRequestCount != 2 || third.ConnectionRequestCount != 3 {
+			t.Errorf("expected connection request count to increase with connection reuse, got %d, %d, %d",
+				first.ConnectionRequestCount, second.ConnectionRequestCount, third.ConnectionRequestCount)
+		}
+		if second.StreamID <= first.StreamID || third.StreamID <= second.StreamID {
+			t.Errorf("expected strictly increasing stream ids, got %d, %d, %d", first.StreamID, second.StreamID, third.StreamID)
+		}
+	})
+
+	t.Run("a different connection starts its own count", func(t *testing.T) {
+		tracker := &connMultiplexTracker{conns: map[string]*connMultiplexState{}}
+		a := httptest.NewRequest(http.MethodGet, "/", nil)
+		a.RemoteAddr = "10.0.0.3:3333"
+		a.ProtoMajor = 2
+		b := httptest.NewRequest(http.MethodGet, "/", nil)
+		b.RemoteAddr = "10.0.0.4:4444"
+		b.ProtoMajor = 2
+
+		tracker.track(a)
+		tracker.track(a)
+		infoB := tracker.track(b)
+
+		if infoB.ConnectionRequestCount != 1 {
+			t.Errorf("expected a fresh connection to start its own count at 1, got %d", infoB.ConnectionRequestCount)
+		}
+	})
+
+	t.Run("forget drops a connection's state", func(t *testing.T) {
+		tracker := &connMultiplexTracker{conns: map[string]*connMultiplexState{}}
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = "10.0.0.5:5555"
+		r.ProtoMajor = 2
+
+		tracker.track(r)
+		tracker.forget(r.RemoteAddr)
+
+		info := tracker.track(r)
+		if info.ConnectionRequestCount != 1 {
+			t.Errorf("expected forgetting a connection to reset its count, got %d", info.ConnectionRequestCount)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package gateway
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+)
+
+// hashRingReplicasPerWeight is how many virtual nodes a node with weight 1
+// gets on the ring. Scaling replicas by weight is what gives heavier nodes a
+// proportionally larger share of the keyspace; using a few hundred per unit
+// of weight keeps the distribution smooth without the ring getting too big
+// to build on every call.
+const hashRingReplicasPerWeight = 200
+
+// hashRingNode is one entry of the "nodes" argument to consistentHashNode:
+// a node name and its relative weight (how large a share of the keyspace it
+// should get, as a multiple of a weight-1 node's share).
+type hashRingNode struct {
+	Name   string `json:"name"`
+	Weight int    `json:"weight"`
+}
+
+// consistentHashNode picks which of nodes key should be routed to, using
+// Karger et al.'s consistent hashing: each node gets hashRingReplicasPerWeight
+// virtual points on a ring per unit of weight, and key is routed to the node
+// owning the first point clockwise of hash(key). This is the standard
+// construction behind consistent hashing's headline guarantee - adding or
+// removing a node only reshuffles the keys that land in its own arc of the
+// ring, not the whole keyspace. Nodes with a weight <= 0 default to 1.
+// Returns "" if nodes is empty.
+func consistentHashNode(key string, nodes []hashRingNode) string {
+	if len(nodes) == 0 {
+		return ""
+	}
+	if len(nodes) == 1 {
+		return nodes[0].Name
+	}
+
+	type ringPoint struct {
+		hash uint32
+		node string
+	}
+
+	ring := make([]ringPoint, 0, len(nodes)*hashRingReplicasPerWeight)
+	for _, n := range nodes {
+		weight := n.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		for i := 0; i < hashRingReplicasPerWeight*weight; i++ {
+			point := crc32.ChecksumIEEE([]byte(n.Name + "#" + strconv.Itoa(i)))
+			ring = append(ring, ringPoint{hash: point, node: n.Name})
+		}
+	}
+
+	sort.Slice(ring, func(i, j int) bool { return ring[i].hash < ring[j].hash })
+
+	keyHash := crc32.ChecksumIEEE([]byte(key))
+	idx := sort.Search(len(ring), func(i int) bool { return ring[i].hash >= keyHash })
+	if idx == len(ring) {
+		idx = 0
+	}
+	return ring[idx].node
+}
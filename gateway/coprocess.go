@@ -2,21 +2,32 @@ package gateway
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 	"unicode/utf8"
 
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/golang/protobuf/proto"
+	cache "github.com/pmylund/go-cache"
 	"github.com/sirupsen/logrus"
 
 	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/certs"
 	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/coprocess"
+	"github.com/TykTechnologies/tyk/headers"
 	"github.com/TykTechnologies/tyk/user"
 
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 )
@@ -26,6 +37,127 @@ var (
 	loadedDrivers    = map[apidef.MiddlewareDriver]coprocess.Dispatcher{}
 )
 
+// defaultBodyTransformCacheTTL is used when a middleware enables body transform
+// caching but doesn't specify one.
+const defaultBodyTransformCacheTTL = 60 * time.Second
+
+// maxBodyTransformCacheEntries bounds the number of cached transform results per
+// hook, so the cache can't grow unbounded regardless of TTL.
+const maxBodyTransformCacheEntries = 5000
+
+// bodyTransformCaches holds one go-cache instance per hook name, so each hook's
+// configured TTL only governs its own entries.
+var bodyTransformCaches = map[string]*cache.Cache{}
+
+// bodyTransformCacheFor returns (creating if necessary) the transform result
+// cache for this middleware's hook.
+func bodyTransformCacheFor(m *CoProcessMiddleware) *cache.Cache {
+	if c, ok := bodyTransformCaches[m.HookName]; ok {
+		return c
+	}
+
+	ttl := defaultBodyTransformCacheTTL
+	if m.BodyTransformCacheTTL > 0 {
+		ttl = time.Duration(m.BodyTransformCacheTTL) * time.Second
+	}
+
+	c := cache.New(ttl, ttl)
+	bodyTransformCaches[m.HookName] = c
+	return c
+}
+
+func bodyTransformHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+var (
+	gwShutdownMu       sync.RWMutex
+	gwShuttingDown     bool
+	gwShutdownDeadline time.Time
+)
+
+// markGatewayShuttingDown flags the gateway as shutting down, so hooks and the
+// coprocess dispatcher can start winding down gracefully. New dispatches are
+// still accepted until CoProcessOptions.ShutdownGracePeriod elapses.
+func markGatewayShuttingDown() {
+	grace := time.Duration(config.Global().CoProcessOptions.ShutdownGracePeriod) * time.Second
+
+	gwShutdownMu.Lock()
+	gwShuttingDown = true
+	gwShutdownDeadline = time.Now().Add(grace)
+	gwShutdownMu.Unlock()
+}
+
+// GatewayIsShuttingDown reports whether a graceful shutdown has started.
+func GatewayIsShuttingDown() bool {
+	gwShutdownMu.RLock()
+	defer gwShutdownMu.RUnlock()
+	return gwShuttingDown
+}
+
+// gatewayShutdownGraceExpired reports whether the shutdown grace period has
+// elapsed, meaning new dispatches should be refused.
+func gatewayShutdownGraceExpired() bool {
+	gwShutdownMu.RLock()
+	defer gwShutdownMu.RUnlock()
+	return gwShuttingDown && time.Now().After(gwShutdownDeadline)
+}
+
+// clusterMembershipCacheTTL bounds how often hooks force a fresh read of the
+// DRL-tracked node count; membership doesn't need to be live per-request.
+const clusterMembershipCacheTTL = 5 * time.Second
+
+var clusterMembershipCache = cache.New(clusterMembershipCacheTTL, clusterMembershipCacheTTL)
+
+// clusterNodeCount returns the current gateway cluster's node count, sourced
+// from the DRL node-registration mechanism, or 1 when clustering/DRL isn't
+// active. The result is cached briefly so hooks don't trigger extra work.
+func clusterNodeCount() int {
+	if cached, found := clusterMembershipCache.Get("count"); found {
+		return cached.(int)
+	}
+
+	count := 1
+	if DRLManager != nil && DRLManager.Ready {
+		if c := DRLManager.Servers.Count(); c > count {
+			count = c
+		}
+	}
+
+	clusterMembershipCache.SetDefault("count", count)
+	return count
+}
+
+// idempotencyKeyHeader is the request header hooks use to mark a request as
+// deduplicatable.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// idempotencyCacheTTL bounds how long a registered idempotency key is
+// remembered before it's eligible for reuse again.
+const idempotencyCacheTTL = 24 * time.Hour
+
+var idempotencyCache = cache.New(idempotencyCacheTTL, time.Hour)
+
+// idempotencyKeyState reports whether key has a previously registered
+// result and, if so, its age. Looking up a key is purely a read: it never
+// registers it, so hooks can check repeatedly without side effects.
+// Registration is the distinct operation performed by
+// registerIdempotencyKey, triggered explicitly via return_overrides.
+func idempotencyKeyState(key string) (found bool, age time.Duration) {
+	v, ok := idempotencyCache.Get(key)
+	if !ok {
+		return false, 0
+	}
+	return true, time.Since(v.(time.Time))
+}
+
+// registerIdempotencyKey records that key's result now exists, starting its
+// age from now.
+func registerIdempotencyKey(key string) {
+	idempotencyCache.SetDefault(key, time.Now())
+}
+
 // CoProcessMiddleware is the basic CP middleware struct.
 type CoProcessMiddleware struct {
 	BaseMiddleware
@@ -34,9 +166,82 @@ type CoProcessMiddleware struct {
 	MiddlewareDriver apidef.MiddlewareDriver
 	RawBodyOnly      bool
 
+	// EnableBodyTransformCache and BodyTransformCacheTTL mirror the matching
+	// apidef.MiddlewareDefinition fields; see there for semantics.
+	EnableBodyTransformCache bool
+	BodyTransformCacheTTL    int64
+
+	// MaxBufferedBodyBytes mirrors apidef.MiddlewareDefinition.MaxBufferedBodyBytes.
+	MaxBufferedBodyBytes int64
+
+	// MaxRequestBodySize mirrors apidef.MiddlewareDefinition.MaxRequestBodySize.
+	MaxRequestBodySize int64
+
+	// HookTimeoutSeconds mirrors apidef.MiddlewareDefinition.HookTimeoutSeconds.
+	// Zero uses config.CoProcessOptions.HookTimeout.
+	HookTimeoutSeconds int64
+
 	successHandler *SuccessHandler
 }
 
+// hookTimeout resolves the timeout bounding a single dispatch of this hook:
+// its own HookTimeoutSeconds if set, else the gateway-wide
+// CoProcessOptions.HookTimeout. Zero means no timeout.
+func (m *CoProcessMiddleware) hookTimeout() time.Duration {
+	seconds := m.HookTimeoutSeconds
+	if seconds == 0 {
+		seconds = config.Global().CoProcessOptions.HookTimeout
+	}
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// hookTimeoutStatusCode is the status code ProcessRequest returns when this
+// hook's dispatch is aborted for exceeding hookTimeout.
+func (m *CoProcessMiddleware) hookTimeoutStatusCode() int {
+	if code := config.Global().CoProcessOptions.HookTimeoutStatusCode; code != 0 {
+		return code
+	}
+	return http.StatusGatewayTimeout
+}
+
+// enforceMaxRequestBodySize rejects a request whose body exceeds
+// MaxRequestBodySize with a 413, before any of it is buffered to build the
+// hook's request object. Checked against Content-Length first where
+// present, so an oversized body is rejected without reading a single byte
+// of it; otherwise against bytes actually read, so a chunked body with no
+// advertised length is still caught the moment it crosses the limit. The
+// bytes read while checking are stitched back onto the body so a request at
+// or under the limit reaches BuildObject unchanged.
+func (m *CoProcessMiddleware) enforceMaxRequestBodySize(r *http.Request) (error, int) {
+	if m.MaxRequestBodySize <= 0 || r.Body == nil {
+		return nil, http.StatusOK
+	}
+
+	if r.ContentLength > m.MaxRequestBodySize {
+		return errors.New("request entity too large"), http.StatusRequestEntityTooLarge
+	}
+
+	if r.ContentLength >= 0 {
+		return nil, http.StatusOK
+	}
+
+	buffered, err := ioutil.ReadAll(io.LimitReader(r.Body, m.MaxRequestBodySize+1))
+	if err != nil {
+		r.Body.Close()
+		return err, http.StatusInternalServerError
+	}
+	r.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(buffered), r.Body))
+
+	if int64(len(buffered)) > m.MaxRequestBodySize {
+		return errors.New("request entity too large"), http.StatusRequestEntityTooLarge
+	}
+
+	return nil, http.StatusOK
+}
+
 func (m *CoProcessMiddleware) Name() string {
 	return "CoProcessMiddleware"
 }
@@ -68,6 +273,48 @@ type CoProcessor struct {
 	Middleware *CoProcessMiddleware
 }
 
+// willServeFromCacheLookahead is a best-effort check of whether a request
+// matches a cached endpoint, so a pre hook can skip expensive auth enrichment
+// work it won't need. It only checks path/method matching, not whether an
+// entry actually exists yet, so a true result never guarantees a cache hit -
+// plugins must still handle a miss.
+func willServeFromCacheLookahead(spec *APISpec, r *http.Request) bool {
+	if !spec.CacheOptions.EnableCache {
+		return false
+	}
+
+	if spec.CacheOptions.CacheAllSafeRequests && isSafeMethod(r.Method) {
+		return true
+	}
+
+	_, versionPaths, _, _ := spec.Version(r)
+	found, _ := spec.CheckSpecMatchesStatus(r, versionPaths, Cached)
+	return found
+}
+
+// clientCertDetails returns the presented client certificate's subject,
+// issuer, SANs (DNS names plus IP addresses) and SHA256 fingerprint for an
+// mTLS request, so an auth hook can make decisions based on it. Returns nil
+// for plaintext requests or ones where the client didn't present a cert.
+func clientCertDetails(req *http.Request) *coprocess.TLS {
+	if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+
+	cert := req.TLS.PeerCertificates[0]
+	sans := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+
+	return &coprocess.TLS{
+		Subject:     cert.Subject.String(),
+		Issuer:      cert.Issuer.String(),
+		Sans:        sans,
+		Fingerprint: certs.HexSHA256(cert.Raw),
+	}
+}
+
 // BuildObject constructs a CoProcessObject from a given http.Request.
 func (c *CoProcessor) BuildObject(req *http.Request, res *http.Response) (*coprocess.Object, error) {
 	headers := ProtoMap(req.Header)
@@ -98,17 +345,57 @@ func (c *CoProcessor) BuildObject(req *http.Request, res *http.Response) (*copro
 		Method:     req.Method,
 		RequestUri: req.RequestURI,
 		Scheme:     scheme,
+		Tls:        clientCertDetails(req),
+	}
+
+	multiplexInfo := ctxGetStreamMultiplexInfo(req)
+	miniRequestObject.StreamId = multiplexInfo.StreamID
+	miniRequestObject.ConnectionRequestCount = multiplexInfo.ConnectionRequestCount
+
+	if receivedAt := ctxGetRequestReceivedAt(req); !receivedAt.IsZero() {
+		miniRequestObject.ReceivedAt = receivedAt.UnixNano()
+	}
+	if deadline := ctxGetRequestDeadline(req); !deadline.IsZero() {
+		miniRequestObject.Deadline = deadline.UnixNano()
 	}
 
 	if req.Body != nil {
-		defer req.Body.Close()
-		var err error
-		miniRequestObject.RawBody, err = ioutil.ReadAll(req.Body)
-		if err != nil {
-			return nil, err
-		}
-		if utf8.Valid(miniRequestObject.RawBody) && !c.Middleware.RawBodyOnly {
-			miniRequestObject.Body = string(miniRequestObject.RawBody)
+		maxBuffer := c.Middleware.MaxBufferedBodyBytes
+		if maxBuffer <= 0 {
+			defer req.Body.Close()
+			var err error
+			miniRequestObject.RawBody, err = ioutil.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			if utf8.Valid(miniRequestObject.RawBody) && !c.Middleware.RawBodyOnly {
+				miniRequestObject.Body = string(miniRequestObject.RawBody)
+			}
+		} else {
+			// Only read one byte past the threshold, so a chunked body with
+			// no advertised length still gets caught the moment it crosses
+			// the limit, rather than after being fully buffered.
+			buffered, err := ioutil.ReadAll(io.LimitReader(req.Body, maxBuffer+1))
+			if err != nil {
+				req.Body.Close()
+				return nil, err
+			}
+
+			if int64(len(buffered)) > maxBuffer {
+				// Over threshold: don't hold the rest of the body in memory
+				// for the hook - stitch the bytes already consumed back onto
+				// what's left of the original body so the request can still
+				// stream through to the upstream untouched. The hook still
+				// runs, but sees an empty body.
+				req.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(buffered), req.Body))
+				ctxSetBodyBufferSkipped(req, true)
+			} else {
+				req.Body.Close()
+				miniRequestObject.RawBody = buffered
+				if utf8.Valid(buffered) && !c.Middleware.RawBodyOnly {
+					miniRequestObject.Body = string(buffered)
+				}
+			}
 		}
 	}
 
@@ -131,10 +418,88 @@ func (c *CoProcessor) BuildObject(req *http.Request, res *http.Response) (*copro
 			}
 		}
 
+		uptimeStatus, uptimeCheckedAt := uptimeTestStatus(c.Middleware.Spec)
+
 		object.Spec = map[string]string{
-			"OrgID":       c.Middleware.Spec.OrgID,
-			"APIID":       c.Middleware.Spec.APIID,
-			"config_data": string(configDataAsJSON),
+			"OrgID":                    c.Middleware.Spec.OrgID,
+			"APIID":                    c.Middleware.Spec.APIID,
+			"api_id":                   c.Middleware.Spec.APIID,
+			"api_name":                 c.Middleware.Spec.Name,
+			"config_data":              string(configDataAsJSON),
+			"GatewayShutdown":          strconv.FormatBool(GatewayIsShuttingDown()),
+			"ClusterNodeCount":         strconv.Itoa(clusterNodeCount()),
+			"ClusterNodeID":            GetNodeID(),
+			"upstream_auth_configured": strconv.FormatBool(c.Middleware.Spec.Proxy.BasicAuth.Enabled),
+			"listen_path_collision":    strconv.FormatBool(c.Middleware.Spec.ListenPathCollision),
+			"session_request_rate":     strconv.FormatFloat(ctxGetSessionRequestRate(req), 'f', 4, 64),
+			"termination_reason":       ctxGetTerminationReason(req),
+			"cors_decision":            ctxGetCORSDecision(req),
+			"api_tags":                 strings.Join(c.Middleware.Spec.Tags, ","),
+			"tls_ocsp_status":          tlsOCSPStatus(req, res),
+			"auth_latency_ms":          strconv.FormatInt(ctxGetAuthLatencyMs(req), 10),
+			"uptime_test_status":       uptimeStatus,
+			"uptime_test_checked_at":   uptimeCheckedAt,
+			"upstream_error_rate":      strconv.FormatFloat(c.Middleware.Spec.UpstreamErrorRate.Rate(), 'f', 4, 64),
+			"in_flight_requests":       strconv.FormatInt(atomic.LoadInt64(&c.Middleware.Spec.InFlightRequests), 10),
+		}
+
+		if allowedPaths, err := json.Marshal(effectiveAllowedPaths(c.Middleware.Spec, ctxGetSession(req))); err == nil {
+			object.Spec["allowed_paths"] = string(allowedPaths)
+		}
+
+		if vinfo := ctxGetVersionInfo(req); vinfo != nil {
+			object.Spec["version_name"] = vinfo.Name
+			if versionDataJSON, err := json.Marshal(vinfo); err == nil {
+				object.Spec["version_data"] = string(versionDataJSON)
+			}
+		}
+
+		if gqlRequest := ctxGetGraphQLRequest(req); gqlRequest != nil {
+			object.Spec["graphql_operation_name"] = gqlRequest.OperationName
+			object.Spec["graphql_normalized_query"] = normalizeGraphQLQuery(gqlRequest.Query)
+			// Variables often carry user-supplied data, so they're only
+			// handed to the hook when detailed recording is already active
+			// for this request - the same privacy gate the raw request/
+			// response capture above is subject to.
+			if recordDetail(req, c.Middleware.Spec) {
+				object.Spec["graphql_variables_json"] = string(gqlRequest.Variables)
+			}
+		}
+
+		if c.Middleware.HookType == coprocess.HookType_Pre {
+			miniRequestObject.JwtHeader = jwtHeaderUnverified(req)
+
+			// matched_endpoint lets a hook key its own cost-tier-to-timeout
+			// mapping (see ReturnOverrides.UpstreamTimeoutSeconds) off the
+			// same path+method identity the gateway's own per-endpoint
+			// configs (like HardTimeouts) already match requests against.
+			object.Spec["matched_endpoint"] = req.Method + " " + req.URL.Path
+
+			// max_body_size reports 0 for unlimited, same as the underlying
+			// config fields, so a hook doesn't need a separate sentinel to
+			// tell "unlimited" apart from "limit of zero" (which none of the
+			// contributing limits can legitimately mean; they all either
+			// treat zero as unset or reject everything, never the former
+			// disguised as the latter).
+			object.Spec["max_body_size"] = strconv.FormatInt(effectiveMaxBodySize(c.Middleware.Spec, c.Middleware, req), 10)
+
+			object.Spec["WillServeFromCache"] = strconv.FormatBool(willServeFromCacheLookahead(c.Middleware.Spec, req))
+
+			pressure := getResourcePressure()
+			object.Spec["gw_memory_used_percent"] = strconv.FormatFloat(pressure.MemoryUsedPercent, 'f', 2, 64)
+			object.Spec["gw_goroutine_count"] = strconv.Itoa(pressure.GoroutineCount)
+
+			if config.Global().TenantFairness.Enabled {
+				object.Spec["tenant_fairness_admit"] = strconv.FormatBool(tenantFairnessAdmit(c.Middleware.Spec.OrgID, &tenantFairnessStore))
+			}
+
+			if key := req.Header.Get(idempotencyKeyHeader); key != "" {
+				found, age := idempotencyKeyState(key)
+				object.Spec["IdempotencyKeyFound"] = strconv.FormatBool(found)
+				if found {
+					object.Spec["IdempotencyKeyAgeSeconds"] = strconv.FormatInt(int64(age.Seconds()), 10)
+				}
+			}
 		}
 	}
 
@@ -156,11 +521,31 @@ func (c *CoProcessor) BuildObject(req *http.Request, res *http.Response) (*copro
 			resObj.Headers[k] = v[0]
 		}
 		resObj.StatusCode = int32(res.StatusCode)
-		rawBody, err := ioutil.ReadAll(res.Body)
+
+		// Capture the upstream's declared length before respBodyReader has a
+		// chance to rewrite it (it zeroes ContentLength when it decompresses
+		// a gzip/deflate body, since the decompressed size isn't known
+		// upfront) - a response hook doing integrity monitoring wants what
+		// upstream actually claimed on the wire.
+		declaredContentLength := res.ContentLength
+		resObj.ContentLengthUnknown = declaredContentLength < 0
+		if !resObj.ContentLengthUnknown {
+			resObj.DeclaredContentLength = declaredContentLength
+		}
+
+		// Decompress a gzip/deflate upstream body before handing it to the
+		// hook - a response hook works with the logical content (e.g.
+		// uppercasing a JSON field), not the wire encoding. HandleResponse
+		// re-compresses the hook's output afterwards if it's still marked
+		// with the same Content-Encoding.
+		bodyReader := respBodyReader(req, res)
+		rawBody, err := ioutil.ReadAll(bodyReader)
+		bodyReader.Close()
 		if err != nil {
 			return nil, err
 		}
 		resObj.RawBody = rawBody
+		resObj.ActualContentLength = int64(len(rawBody))
 		res.Body = ioutil.NopCloser(bytes.NewReader(rawBody))
 		if utf8.Valid(rawBody) && !c.Middleware.RawBodyOnly {
 			resObj.Body = string(rawBody)
@@ -171,11 +556,63 @@ func (c *CoProcessor) BuildObject(req *http.Request, res *http.Response) (*copro
 	return object, nil
 }
 
+// jwtHeaderUnverified extracts a request's JWT header (typically "alg" and
+// "kid") WITHOUT verifying its signature, so a pre hook can use it to route
+// to the right key before the gateway's own JWT middleware ever runs.
+// Returns an empty map if the Authorization header isn't present or doesn't
+// parse as a JWT. Callers must never treat these values as authenticated -
+// they come from an unverified token.
+func jwtHeaderUnverified(req *http.Request) map[string]string {
+	header := map[string]string{}
+
+	rawJWT := stripBearer(req.Header.Get("Authorization"))
+	if rawJWT == "" {
+		return header
+	}
+
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	token, _, err := parser.ParseUnverified(rawJWT, jwt.MapClaims{})
+	if err != nil {
+		return header
+	}
+
+	for k, v := range token.Header {
+		if s, ok := v.(string); ok {
+			header[k] = s
+		}
+	}
+
+	return header
+}
+
 // ObjectPostProcess does CoProcessObject post-processing (adding/removing headers or params, etc.).
 func (c *CoProcessor) ObjectPostProcess(object *coprocess.Object, r *http.Request, origURL string, origMethod string) (err error) {
-	r.ContentLength = int64(len(object.Request.RawBody))
-	r.Body = ioutil.NopCloser(bytes.NewReader(object.Request.RawBody))
-	nopCloseRequestBody(r)
+	// When BuildObject skipped buffering this request's body (see
+	// MaxBufferedBodyBytes), r.Body already holds the original, still-unread
+	// stream - the hook never saw the body, so there's nothing here to write
+	// back.
+	if !ctxGetBodyBufferSkipped(r) {
+		r.ContentLength = int64(len(object.Request.RawBody))
+		r.Body = ioutil.NopCloser(bytes.NewReader(object.Request.RawBody))
+		nopCloseRequestBody(r)
+	}
+
+	// A hook may have just masked the body that's about to be forwarded
+	// upstream (above) while asking to keep the original for analytics -
+	// stash it so RecordHit can use it for the detailed record instead of
+	// the masked body that's actually on the wire.
+	if analyticsBody := object.Request.ReturnOverrides.AnalyticsRequestBody; analyticsBody != "" {
+		ctxSetAnalyticsRequestBodyOverride(r, []byte(analyticsBody))
+	}
+
+	overrides := object.Request.ReturnOverrides
+	if overrides.UpstreamPathPrefixStrip != "" || overrides.UpstreamPathPrefixPrepend != "" {
+		ctxSetUpstreamPathPrefixOverride(r, overrides.UpstreamPathPrefixStrip, overrides.UpstreamPathPrefixPrepend)
+	}
+
+	if len(overrides.SkipMiddleware) > 0 {
+		ctxSetSkipMiddleware(r, sanitizeSkipMiddlewareNames(overrides.SkipMiddleware))
+	}
 
 	logger := c.Middleware.Logger()
 
@@ -188,6 +625,7 @@ func (c *CoProcessor) ObjectPostProcess(object *coprocess.Object, r *http.Reques
 	}
 
 	values := r.URL.Query()
+	paramsChanged := len(object.Request.DeleteParams) > 0 || len(object.Request.AddParams) > 0
 	for _, k := range object.Request.DeleteParams {
 		values.Del(k)
 	}
@@ -222,7 +660,15 @@ func (c *CoProcessor) ObjectPostProcess(object *coprocess.Object, r *http.Reques
 		r.Method = object.Request.Method
 	}
 
-	r.URL.RawQuery = values.Encode()
+	// Canonicalizing always sorts parameters lexicographically by key (this is
+	// what url.Values.Encode does), keeping repeated values for the same key
+	// in the order they originally appeared. Only do this when the hook asked
+	// for it via ReturnOverrides.CanonicalizeQuery, or when add/delete params
+	// already forced us to rebuild the query from the parsed values - anything
+	// else leaves the query string exactly as the hook returned it.
+	if paramsChanged || object.Request.ReturnOverrides.GetCanonicalizeQuery() {
+		r.URL.RawQuery = values.Encode()
+	}
 
 	return
 }
@@ -293,6 +739,21 @@ func (m *CoProcessMiddleware) EnabledForSpec() bool {
 func (m *CoProcessMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
 	logger := m.Logger()
 	logger.Debug("CoProcess Request, HookType: ", m.HookType)
+
+	// Auth hooks are a security control, not a per-endpoint convenience
+	// feature - they can't be bypassed via ExtendedPaths.BypassCoProcess.
+	if m.HookType != coprocess.HookType_CustomKeyCheck {
+		_, versionPaths, _, _ := m.Spec.Version(r)
+		if found, _ := m.Spec.CheckSpecMatchesStatus(r, versionPaths, CoProcessBypass); found {
+			logger.Debug("CoProcess hook bypassed for this path")
+			return nil, http.StatusOK
+		}
+	}
+
+	if err, code := m.enforceMaxRequestBodySize(r); err != nil {
+		return err, code
+	}
+
 	originalURL := r.URL
 
 	var extractor IdExtractor
@@ -338,21 +799,200 @@ func (m *CoProcessMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Requ
 		object.Request.Method = transformMethod
 	}
 
-	t1 := time.Now()
-	returnObject, err := coProcessor.Dispatch(object)
-	ms := DurationToMillisecond(time.Since(t1))
+	// Body transform caching only applies to Pre hooks: it skips re-dispatching the
+	// hook entirely when an identical body was already transformed recently, so it
+	// must not be used where the hook's output can depend on anything else (auth
+	// state, headers, etc.).
+	useBodyTransformCache := m.HookType == coprocess.HookType_Pre && m.EnableBodyTransformCache
+	var bodyCacheKey string
+	var returnObject *coprocess.Object
+	if useBodyTransformCache {
+		bodyCacheKey = bodyTransformHash(object.Request.RawBody)
+		if cached, found := bodyTransformCacheFor(m).Get(bodyCacheKey); found {
+			returnObject = proto.Clone(cached.(*coprocess.Object)).(*coprocess.Object)
+		}
+	}
 
-	if err != nil {
-		logger.WithError(err).Error("Dispatch error")
-		if m.HookType == coprocess.HookType_CustomKeyCheck {
-			return errors.New("Key not authorised"), 403
+	t1 := time.Now()
+	if returnObject == nil {
+		var dispatchErr error
+		if m.HookType == coprocess.HookType_CustomKeyCheck && len(m.Spec.CustomMiddleware.AuthCheckHooks) > 0 {
+			returnObject, dispatchErr = coProcessor.DispatchAuthCheckHooks(object, m.Spec.CustomMiddleware.AuthCheckHooks, m.Spec.CustomMiddleware.AuthCheckHooksPolicy)
 		} else {
-			return errors.New("Middleware error"), 500
+			returnObject, dispatchErr = coProcessor.Dispatch(object)
+		}
+		if dispatchErr != nil {
+			logger.WithError(dispatchErr).Error("Dispatch error")
+			if dispatchErr == ErrHookTimeout {
+				return dispatchErr, m.hookTimeoutStatusCode()
+			}
+			if m.HookType == coprocess.HookType_CustomKeyCheck {
+				return errors.New("Key not authorised"), 403
+			} else {
+				return errors.New("Middleware error"), 500
+			}
+		}
+
+		if useBodyTransformCache {
+			bodyCache := bodyTransformCacheFor(m)
+			if bodyCache.ItemCount() < maxBodyTransformCacheEntries {
+				bodyCache.SetDefault(bodyCacheKey, proto.Clone(returnObject))
+			}
 		}
 	}
+	ms := DurationToMillisecond(time.Since(t1))
 
 	m.logger.WithField("ms", ms).Debug("gRPC request processing took")
 
+	switch returnObject.Request.AnalyticsDetailLevel {
+	case "detailed":
+		ctxSetAnalyticsDetailOverride(r, true)
+	case "summary":
+		ctxSetAnalyticsDetailOverride(r, false)
+	}
+
+	if m.HookType == coprocess.HookType_Pre {
+		if budget := int(returnObject.Request.ReturnOverrides.RetryBudget); budget > 0 {
+			if max := config.Global().MaxHookRetryBudget; max > 0 && budget > max {
+				budget = max
+			}
+			ctxSetRetryBudget(r, budget)
+		}
+
+		if seconds := int(returnObject.Request.ReturnOverrides.UpstreamTimeoutSeconds); seconds > 0 {
+			_, maxTimeout := hardTimeoutFor(m.Spec, r)
+			timeout := float64(seconds)
+			if maxTimeout > 0 && timeout > maxTimeout {
+				timeout = maxTimeout
+			}
+			ctxSetUpstreamTimeoutOverride(r, timeout)
+		}
+
+		if returnObject.Request.ReturnOverrides.RegisterIdempotencyKey {
+			if key := r.Header.Get(idempotencyKeyHeader); key != "" {
+				registerIdempotencyKey(key)
+			}
+		}
+
+		if key := returnObject.Request.ReturnOverrides.UpstreamIdempotencyKey; key != "" {
+			ctxSetUpstreamIdempotencyKey(r, key)
+		}
+
+		// Sunset/Deprecation are set directly on the response here, rather than
+		// via ctx, because they're meant to reach the client on every response
+		// to a deprecated version - including the normal, non-blocked path
+		// where the request still proceeds to the upstream.
+		if sunset := returnObject.Request.ReturnOverrides.Sunset; sunset != "" {
+			if t, parseErr := http.ParseTime(sunset); parseErr == nil {
+				w.Header().Set("Sunset", t.UTC().Format(http.TimeFormat))
+			} else {
+				m.logger.Warningf("sunset date %q is not a valid HTTP-date, ignoring", sunset)
+			}
+		}
+
+		if returnObject.Request.ReturnOverrides.Deprecated {
+			w.Header().Set("Deprecation", "true")
+			if warning := returnObject.Request.ReturnOverrides.DeprecationWarning; warning != "" {
+				w.Header().Set("Warning", `299 - "`+warning+`"`)
+			}
+		}
+
+		if protocol := returnObject.Request.ReturnOverrides.UpstreamProtocol; protocol != "" {
+			ctxSetUpstreamProtocolOverride(r, protocol)
+		}
+
+		delayMs := returnObject.Request.ReturnOverrides.InjectDelayMs
+		fault := returnObject.Request.ReturnOverrides.InjectFault
+		if delayMs > 0 || fault != 0 {
+			if !m.Spec.ChaosEngineering.Enabled {
+				m.logger.Warning("chaos injection requested by hook but not enabled for this API; ignoring")
+			} else {
+				if delayMs > 0 {
+					delay := time.Duration(delayMs) * time.Millisecond
+					if deadline, ok := r.Context().Deadline(); ok {
+						if remaining := time.Until(deadline); remaining < delay {
+							delay = remaining
+						}
+					}
+					if delay > 0 {
+						time.Sleep(delay)
+					}
+				}
+				if fault != 0 {
+					return errors.New("synthetic fault injected for chaos testing"), int(fault)
+				}
+			}
+		}
+	}
+
+	if m.HookType == coprocess.HookType_Post {
+		if cost := returnObject.Request.ReturnOverrides.QuotaCost; cost > 1 {
+			if session := ctxGetSession(r); session != nil {
+				quotaStore := rateLimitStoreFor(session, GlobalSessionManager.Store())
+				if sessionLimiter.ApplyQuotaCost(session, m.Spec.APIID, cost, quotaStore, m.Spec.RejectOnQuotaCostOverflow) {
+					return errors.New("Quota exceeded"), http.StatusForbidden
+				}
+			}
+		}
+	}
+
+	// add_tags/remove_tags adjust the request's analytics record. They're not
+	// tied to a single hook type: a Pre hook may want to tag a request before
+	// it's even authenticated, while a Post hook may only know the right tags
+	// once it's seen the upstream's response. Both are merged into the final
+	// record by SuccessHandler.RecordHit.
+	if tags := returnObject.Request.ReturnOverrides.AddTags; len(tags) > 0 {
+		ctxAddAnalyticsTags(r, tags)
+	}
+	if tags := returnObject.Request.ReturnOverrides.RemoveTags; len(tags) > 0 {
+		ctxRemoveAnalyticsTags(r, tags)
+	}
+
+	if returnObject.Request.ReturnOverrides.RetainTrace {
+		ctxSetRetainTrace(r)
+	}
+
+	if key := returnObject.Request.ReturnOverrides.AffinityKey; key != "" {
+		ctxSetUpstreamAffinityKey(r, key)
+	}
+
+	if types := returnObject.Request.ReturnOverrides.AllowedContentTypes; len(types) > 0 {
+		ctxSetAllowedContentTypes(r, types)
+	}
+
+	if key := returnObject.Request.ReturnOverrides.RateLimitKey; key != "" {
+		ctxSetCustomRateLimitKey(r, key)
+	}
+
+	if sig := returnObject.Request.ReturnOverrides.GraphqlAnalyticsSignature; sig != "" {
+		ctxSetGraphQLAnalyticsSignature(r, sig)
+		ctxSetGraphQLAnalyticsStripVariables(r, returnObject.Request.ReturnOverrides.GraphqlAnalyticsStripVariables)
+	}
+
+	if weights := returnObject.Request.ReturnOverrides.TargetWeights; len(weights) > 0 {
+		ctxSetTargetWeights(r, weights)
+	}
+
+	if hdrs := returnObject.Request.ReturnOverrides.CacheVaryHeaders; len(hdrs) > 0 {
+		ctxSetCacheVaryHeaders(r, hdrs)
+	}
+
+	if vars := returnObject.Request.ReturnOverrides.RateLimitTemplateVars; len(vars) > 0 {
+		ctxSetRateLimitTemplateVars(r, vars)
+	}
+
+	// Stashed in ctx, rather than applied directly to a header here, because
+	// it also needs to reach the gateway's own circuit-breaker 503 (which
+	// doesn't go through ReturnOverrides at all) if this request later trips
+	// the breaker.
+	if retryAfter := returnObject.Request.ReturnOverrides.RetryAfter; retryAfter != "" {
+		if value, ok := retryAfterHeaderValue(retryAfter); ok {
+			ctxSetRetryAfter(r, value)
+		} else {
+			m.logger.Warningf("retry_after %q is not a valid HTTP-date or delta-seconds, ignoring", retryAfter)
+		}
+	}
+
 	err = coProcessor.ObjectPostProcess(returnObject, r, origURL, origMethod)
 	if err != nil {
 		// Restore original URL object so that it can be used by ErrorHandler:
@@ -380,13 +1020,22 @@ func (m *CoProcessMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Requ
 		returnObject.Request.ReturnOverrides.ResponseBody = returnObject.Request.ReturnOverrides.ResponseError
 	}
 
-	// The CP middleware indicates this is a bad auth:
-	if returnObject.Request.ReturnOverrides.ResponseCode >= http.StatusBadRequest && !returnObject.Request.ReturnOverrides.OverrideError {
+	// The CP middleware indicates this is a bad auth. Only an auth_check
+	// hook gets this framing (the "key not authorised" logging/event/body
+	// fallback) - a pre/post/response hook rejecting a request isn't an
+	// auth failure, and falls through to the generic verbatim-response
+	// branch below regardless of its response code.
+	if m.HookType == coprocess.HookType_CustomKeyCheck &&
+		returnObject.Request.ReturnOverrides.ResponseCode >= http.StatusBadRequest &&
+		!returnObject.Request.ReturnOverrides.OverrideError {
 		logger.WithField("key", obfuscateKey(token)).Info("Attempted access with invalid key")
 
 		for h, v := range returnObject.Request.ReturnOverrides.Headers {
 			w.Header().Set(h, v)
 		}
+		if value, ok := retryAfterHeaderValue(returnObject.Request.ReturnOverrides.RetryAfter); ok {
+			w.Header().Set("Retry-After", value)
+		}
 
 		// Fire Authfailed Event
 		AuthFailed(m, r, token)
@@ -406,6 +1055,9 @@ func (m *CoProcessMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Requ
 		for h, v := range returnObject.Request.ReturnOverrides.Headers {
 			w.Header().Set(h, v)
 		}
+		if value, ok := retryAfterHeaderValue(returnObject.Request.ReturnOverrides.RetryAfter); ok {
+			w.Header().Set("Retry-After", value)
+		}
 		w.WriteHeader(int(returnObject.Request.ReturnOverrides.ResponseCode))
 		w.Write([]byte(returnObject.Request.ReturnOverrides.ResponseBody))
 
@@ -440,7 +1092,7 @@ func (m *CoProcessMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Requ
 
 		// If the returned object contains metadata, add them to the session:
 		for k, v := range returnObject.Metadata {
-			returnedSession.MetaData[k] = string(v)
+			returnedSession.MetaData[k] = decodeMetadataValue(v)
 		}
 
 		returnedSession.OrgID = m.Spec.OrgID
@@ -487,10 +1139,12 @@ func (h *CustomMiddlewareResponseHook) Init(mwDef interface{}, spec *APISpec) er
 		BaseMiddleware: BaseMiddleware{
 			Spec: spec,
 		},
-		HookName:         mwDefinition.Name,
-		HookType:         coprocess.HookType_Response,
-		RawBodyOnly:      mwDefinition.RawBodyOnly,
-		MiddlewareDriver: spec.CustomMiddleware.Driver,
+		HookName:             mwDefinition.Name,
+		HookType:             coprocess.HookType_Response,
+		RawBodyOnly:          mwDefinition.RawBodyOnly,
+		MiddlewareDriver:     spec.CustomMiddleware.Driver,
+		MaxBufferedBodyBytes: mwDefinition.MaxBufferedBodyBytes,
+		HookTimeoutSeconds:   mwDefinition.HookTimeoutSeconds,
 	}
 	return nil
 }
@@ -535,28 +1189,212 @@ func (h *CustomMiddlewareResponseHook) HandleResponse(rw http.ResponseWriter, re
 		return errors.New("Middleware error")
 	}
 
-	// Set headers:
+	// cache_backend is only honoured here, not on Pre/AuthCheck hook return
+	// overrides, because picking a backend by response size (the documented
+	// use case) needs the response - object.Response.ActualContentLength
+	// above already gave the hook that, whereas a Pre hook runs before the
+	// upstream call even happens.
+	if backend := retObject.Request.ReturnOverrides.CacheBackend; backend != "" {
+		switch backend {
+		case cacheBackendMemory, cacheBackendRedis:
+			ctxSetCacheBackendOverride(req, backend)
+		default:
+			h.mw.logger.Warningf("unsupported cache_backend %q requested, ignoring", backend)
+		}
+	}
+
+	// Set headers. Assigning the map key directly, rather than going
+	// through Header.Set, preserves whatever casing the hook chose instead
+	// of canonicalizing it, so a hook-requested HeaderOrder (honoured for
+	// HTTP/1.1 responses, see writeOrderedResponse) keys match what's
+	// actually written.
 	for k, v := range retObject.Response.Headers {
-		res.Header.Set(k, v)
+		res.Header.Del(k)
+		res.Header[k] = []string{v}
+	}
+
+	if order := retObject.Response.HeaderOrder; len(order) > 0 {
+		ctxSetResponseHeaderOrder(req, order)
 	}
 
 	// Set response body:
 	bodyBuf := bytes.NewBuffer(retObject.Response.RawBody)
+
+	// Re-compress if the response is still marked with the encoding it
+	// arrived with - BuildObject decompressed it before the hook ran, so
+	// unless the hook changed Content-Encoding itself, the bytes it handed
+	// back are plain and need to go out the way the client expects.
+	if encoding := res.Header.Get(headers.ContentEncoding); encoding != "" {
+		compressed := compressBuffer(*bodyBuf, encoding)
+		bodyBuf = &compressed
+	}
+
+	// The hook may have edited the body (e.g. via tyk.apply_json_patch), so
+	// ContentLength and the header must be recomputed rather than left
+	// pointing at the original upstream length.
+	res.ContentLength = int64(bodyBuf.Len())
+	res.Header.Set("Content-Length", strconv.Itoa(bodyBuf.Len()))
+
+	applyHookCompression(req, res, retObject.Request.ReturnOverrides.GetCompressResponse(), bodyBuf)
+
 	res.Body = ioutil.NopCloser(bodyBuf)
 
 	res.StatusCode = int(retObject.Response.StatusCode)
 	return nil
 }
 
+// ErrHookTimeout is returned by CoProcessor.Dispatch when a hook's dispatch
+// is aborted for exceeding its configured timeout (see
+// CoProcessMiddleware.hookTimeout). Callers map it to a specific status code
+// rather than the generic "Middleware error" 500.
+var ErrHookTimeout = errors.New("coprocess: hook dispatch timed out")
+
 func (c *CoProcessor) Dispatch(object *coprocess.Object) (*coprocess.Object, error) {
+	if gatewayShutdownGraceExpired() {
+		return nil, errors.New("gateway is shutting down, no new dispatches are accepted")
+	}
+
 	dispatcher := loadedDrivers[c.Middleware.MiddlewareDriver]
 	if dispatcher == nil {
 		err := fmt.Errorf("Couldn't dispatch request, driver '%s' isn't available", c.Middleware.MiddlewareDriver)
 		return nil, err
 	}
-	newObject, err := dispatcher.Dispatch(object)
-	if err != nil {
-		return nil, err
+
+	timeout := c.Middleware.hookTimeout()
+	if timeout <= 0 {
+		newObject, err := dispatcher.Dispatch(object)
+		if err != nil {
+			return nil, err
+		}
+		return newObject, nil
+	}
+
+	type dispatchResult struct {
+		object *coprocess.Object
+		err    error
+	}
+	resultChan := make(chan dispatchResult, 1)
+	go func() {
+		newObject, err := dispatcher.Dispatch(object)
+		resultChan <- dispatchResult{newObject, err}
+	}()
+
+	select {
+	case result := <-resultChan:
+		if result.err != nil {
+			return nil, result.err
+		}
+		return result.object, nil
+	case <-time.After(timeout):
+		log.WithFields(logrus.Fields{
+			"prefix": "coprocess",
+			"bundle": c.Middleware.Spec.CustomMiddlewareBundle,
+			"hook":   c.Middleware.HookName,
+		}).Errorf("hook dispatch exceeded %s timeout, aborting", timeout)
+		// The goroutine above is left running - the underlying driver call
+		// (e.g. a blocked Python hook) can't be forcibly cancelled from
+		// here, so its result, once it eventually arrives, is just
+		// discarded into the buffered channel.
+		return nil, ErrHookTimeout
+	}
+}
+
+// authCheckHookSucceeded mirrors the same definition of "this CustomKeyCheck
+// hook accepted the request" that ProcessRequest itself applies further down
+// to a single hook's result: it set a session and, on it, the token that
+// identifies it (see ProcessRequest's "didn't setup a session" check), and
+// didn't explicitly reject via ReturnOverrides.ResponseCode.
+func authCheckHookSucceeded(result *coprocess.Object) bool {
+	if result.Request.ReturnOverrides.ResponseCode >= http.StatusBadRequest {
+		return false
+	}
+	return result.Session != nil && result.Session.Metadata["token"] != ""
+}
+
+// DispatchAuthCheckHooks runs a chain of CustomKeyCheck hooks against
+// independent clones of object (each sees the same incoming request, not
+// whatever an earlier hook in the chain mutated - these are meant to be
+// independent factors, e.g. "validate this header" / "validate that
+// header", not a pipeline), short-circuiting according to policy:
+//
+//   - "and" (the default): every hook must succeed (see
+//     authCheckHookSucceeded). Stops and returns the first failure as-is.
+//     If all succeed, returns a merged result - session and metadata from
+//     every hook, later hooks' values winning on key conflicts.
+//   - "or": the first hook to succeed wins and is returned immediately,
+//     without running the rest. If every hook fails, the last failure is
+//     returned as-is.
+func (c *CoProcessor) DispatchAuthCheckHooks(object *coprocess.Object, hooks []apidef.MiddlewareDefinition, policy string) (*coprocess.Object, error) {
+	policy = strings.ToLower(policy)
+
+	var merged *coprocess.Object
+	var lastResult *coprocess.Object
+
+	for _, hook := range hooks {
+		hookObject := proto.Clone(object).(*coprocess.Object)
+		hookObject.HookName = hook.Name
+
+		result, err := c.Dispatch(hookObject)
+		if err != nil {
+			return nil, err
+		}
+		lastResult = result
+
+		failed := !authCheckHookSucceeded(result)
+
+		if policy == "or" {
+			if !failed {
+				return result, nil
+			}
+			continue
+		}
+
+		// "and" (default):
+		if failed {
+			return result, nil
+		}
+		if merged == nil {
+			merged = result
+		} else {
+			mergeAuthCheckHookResult(merged, result)
+		}
+	}
+
+	if policy == "or" {
+		// Every hook failed - nothing to merge, report the last rejection.
+		return lastResult, nil
+	}
+
+	return merged, nil
+}
+
+// mergeAuthCheckHookResult folds from's session and metadata into into,
+// from's values winning on key conflicts. Used to combine the results of
+// several AND-chained auth hooks into the single object the rest of
+// CoProcessMiddleware.ProcessRequest expects.
+func mergeAuthCheckHookResult(into, from *coprocess.Object) {
+	if from.Metadata != nil {
+		if into.Metadata == nil {
+			into.Metadata = map[string]string{}
+		}
+		for k, v := range from.Metadata {
+			into.Metadata[k] = v
+		}
+	}
+
+	if from.Session == nil {
+		return
+	}
+	if into.Session == nil {
+		into.Session = from.Session
+		return
+	}
+	if from.Session.Metadata != nil {
+		if into.Session.Metadata == nil {
+			into.Session.Metadata = map[string]string{}
+		}
+		for k, v := range from.Session.Metadata {
+			into.Session.Metadata[k] = v
+		}
 	}
-	return newObject, nil
 }
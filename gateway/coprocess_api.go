@@ -3,18 +3,105 @@ package gateway
 import "C"
 
 import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver"
+	cache "github.com/pmylund/go-cache"
 	"github.com/sirupsen/logrus"
 
 	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/storage"
 )
 
+// defaultIntrospectionCacheTTL is used when the IdP's introspection response
+// doesn't carry its own expiry and the API hasn't set CacheTTL.
+const defaultIntrospectionCacheTTL = 30 * time.Second
+
+var introspectionCache = cache.New(defaultIntrospectionCacheTTL, defaultIntrospectionCacheTTL)
+
+// TykIntrospectJWT is a CoProcess API function that validates an opaque token
+// against the API's configured IdP introspection endpoint (RFC 7662), caching
+// the result by token hash. On an unreachable IdP it fails closed (returns
+// inactive) unless the API opts into FailOpen.
+//
+//export TykIntrospectJWT
+func TykIntrospectJWT(CAPIID, CToken *C.char) *C.char {
+	apiID := C.GoString(CAPIID)
+	token := C.GoString(CToken)
+
+	inactive := C.CString(`{"active":false}`)
+
+	spec := getApiSpec(apiID)
+	if spec == nil || !spec.JWTIntrospection.Enabled {
+		return inactive
+	}
+
+	opts := spec.JWTIntrospection
+	cacheKey := apiID + ":" + storage.HashKey(token)
+	if cached, found := introspectionCache.Get(cacheKey); found {
+		return C.CString(cached.(string))
+	}
+
+	form := url.Values{"token": {token}}
+	req, err := http.NewRequest(http.MethodPost, opts.URL, bytes.NewBufferString(form.Encode()))
+	if err != nil {
+		return inactive
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if opts.ClientID != "" {
+		req.SetBasicAuth(opts.ClientID, opts.ClientSecret)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"prefix": "coprocess",
+			"apiID":  apiID,
+		}).WithError(err).Error("JWT introspection request failed")
+		if opts.FailOpen {
+			return C.CString(`{"active":true}`)
+		}
+		return inactive
+	}
+	defer resp.Body.Close()
+
+	var body bytes.Buffer
+	if _, err := body.ReadFrom(resp.Body); err != nil {
+		return inactive
+	}
+
+	ttl := defaultIntrospectionCacheTTL
+	if opts.CacheTTL > 0 {
+		ttl = time.Duration(opts.CacheTTL) * time.Second
+	}
+	var introspected struct {
+		Exp int64 `json:"exp"`
+	}
+	if json.Unmarshal(body.Bytes(), &introspected) == nil && introspected.Exp > 0 {
+		if untilExp := time.Until(time.Unix(introspected.Exp, 0)); untilExp > 0 {
+			ttl = untilExp
+		}
+	}
+	introspectionCache.Set(cacheKey, body.String(), ttl)
+
+	return C.CString(body.String())
+}
+
 // CoProcessDefaultKeyPrefix is used as a key prefix for this CP.
 const CoProcessDefaultKeyPrefix = "coprocess-data:"
 
 // TODO: implement INCR, DECR?
 
 // TykStoreData is a CoProcess API function for storing data.
+//
 //export TykStoreData
 func TykStoreData(CKey, CValue *C.char, CTTL C.int) {
 	key := C.GoString(CKey)
@@ -26,6 +113,7 @@ func TykStoreData(CKey, CValue *C.char, CTTL C.int) {
 }
 
 // TykGetData is a CoProcess API function for fetching data.
+//
 //export TykGetData
 func TykGetData(CKey *C.char) *C.char {
 	key := C.GoString(CKey)
@@ -37,6 +125,7 @@ func TykGetData(CKey *C.char) *C.char {
 }
 
 // TykTriggerEvent is a CoProcess API function for triggering Tyk system events.
+//
 //export TykTriggerEvent
 func TykTriggerEvent(CEventName, CPayload *C.char) {
 	eventName := C.GoString(CEventName)
@@ -47,7 +136,544 @@ func TykTriggerEvent(CEventName, CPayload *C.char) {
 	})
 }
 
+// TykRenderTemplate is a CoProcess API function that renders a template
+// registered with the gateway's template engine (see server.go's `templates`),
+// so hooks can build responses without string-building JSON/HTML themselves.
+// CData is a JSON object of template variables. Returns an empty string and
+// logs the cause on a missing template or a render error, so hooks get a
+// clean failure rather than a partial body.
+//
+//export TykRenderTemplate
+func TykRenderTemplate(CName, CData *C.char) *C.char {
+	name := C.GoString(CName)
+	data := C.GoString(CData)
+
+	tmpl := templates.Lookup(name)
+	if tmpl == nil {
+		log.WithFields(logrus.Fields{
+			"prefix":   "coprocess",
+			"template": name,
+		}).Error("Couldn't render template: not registered")
+		return C.CString("")
+	}
+
+	var vars map[string]interface{}
+	if data != "" {
+		if err := json.Unmarshal([]byte(data), &vars); err != nil {
+			log.WithFields(logrus.Fields{
+				"prefix":   "coprocess",
+				"template": name,
+			}).WithError(err).Error("Couldn't render template: invalid data")
+			return C.CString("")
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		log.WithFields(logrus.Fields{
+			"prefix":   "coprocess",
+			"template": name,
+		}).WithError(err).Error("Couldn't render template")
+		return C.CString("")
+	}
+
+	return C.CString(buf.String())
+}
+
+// requestBatchers holds one RequestBatcher per (group, url) pair so
+// concurrent hook calls naming the same group coalesce into the same batch.
+var requestBatchers sync.Map
+
+// requestBatcherFor returns the batcher for this group/url pair, creating it
+// on first use with the operator-configured window and max batch size.
+func requestBatcherFor(group, url string) *RequestBatcher {
+	key := group + "|" + url
+	if v, ok := requestBatchers.Load(key); ok {
+		return v.(*RequestBatcher)
+	}
+
+	cpConf := config.Global().CoProcessOptions
+	window := time.Duration(cpConf.RequestBatchWindowMs) * time.Millisecond
+	if window <= 0 {
+		window = 50 * time.Millisecond
+	}
+	maxBatch := cpConf.RequestBatchMaxSize
+	if maxBatch <= 0 {
+		maxBatch = 10
+	}
+
+	batcher := NewRequestBatcher(window, maxBatch, func(bodies []string) ([]batchItemResult, error) {
+		return sendUpstreamBatch(url, bodies)
+	})
+
+	v, _ := requestBatchers.LoadOrStore(key, batcher)
+	return v.(*RequestBatcher)
+}
+
+// sendUpstreamBatch POSTs all pending items to url as a single JSON array
+// request (`{"items": [...]}`) and expects back a same-shaped response,
+// matching results to items positionally so each caller only ever sees its
+// own response.
+func sendUpstreamBatch(url string, bodies []string) ([]batchItemResult, error) {
+	reqPayload := struct {
+		Items []json.RawMessage `json:"items"`
+	}{}
+	for _, b := range bodies {
+		reqPayload.Items = append(reqPayload.Items, json.RawMessage(b))
+	}
+
+	payload, err := json.Marshal(reqPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var respPayload struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&respPayload); err != nil {
+		return nil, err
+	}
+
+	results := make([]batchItemResult, len(bodies))
+	for i := range bodies {
+		if i >= len(respPayload.Items) || respPayload.Items[i] == nil {
+			results[i] = batchItemResult{err: errBatchItemMissing}
+			continue
+		}
+		results[i] = batchItemResult{body: string(respPayload.Items[i])}
+	}
+	return results, nil
+}
+
+// TykBatchRequest is a CoProcess API function that coalesces calls sharing
+// the same group within a short window into a single upstream batch-endpoint
+// call, so plugins handling many small requests can avoid one upstream round
+// trip per request. Returns the JSON body of this call's own result, or
+// "error: <reason>" on failure (including a partial batch failure affecting
+// just this item).
+//
+//export TykBatchRequest
+func TykBatchRequest(CGroup, CURL, CBody *C.char) *C.char {
+	group := C.GoString(CGroup)
+	url := C.GoString(CURL)
+	body := C.GoString(CBody)
+
+	result, err := requestBatcherFor(group, url).Submit(body)
+	if err != nil {
+		return C.CString("error: " + err.Error())
+	}
+	return C.CString(result)
+}
+
+// TykMakeRequest is a CoProcess API function that makes a blocking,
+// in-process call to another loaded API's own handler - the same internal
+// routing "tyk://" looping uses - and returns its response, so a hook can
+// enrich or compose its own response from another API's output without the
+// client ever seeing a redirect. Returns a JSON object with status_code,
+// headers and body fields on success, or "error: <reason>" if no loaded API
+// matches CAPIID (an API ID or name).
+//
+//export TykMakeRequest
+func TykMakeRequest(CAPIID, CPath, CMethod, CBody *C.char) *C.char {
+	apiID := C.GoString(CAPIID)
+	path := C.GoString(CPath)
+	method := C.GoString(CMethod)
+	body := C.GoString(CBody)
+
+	resp, err := makeInternalAPIRequest(apiID, path, method, []byte(body))
+	if err != nil {
+		return C.CString("error: " + err.Error())
+	}
+
+	encoded, err := json.Marshal(resp)
+	if err != nil {
+		return C.CString("error: " + err.Error())
+	}
+	return C.CString(string(encoded))
+}
+
+// TykRateCheck is a CoProcess API function that reports a key's current
+// rate-limit state - allowed, limit, remaining, reset, reason - without
+// counting as a hit, going through the exact same SessionLimiter counters
+// the enforcing RateLimitAndQuotaCheck middleware uses (in its dry-run
+// mode), so a hook building its own headers or error body from this can't
+// disagree with what the gateway is about to enforce. Returns a JSON object
+// on success, or "error: <reason>" if CAPIID isn't a loaded API or CKey
+// isn't a known key.
+//
+//export TykRateCheck
+func TykRateCheck(CAPIID, CKey *C.char) *C.char {
+	apiID := C.GoString(CAPIID)
+	key := C.GoString(CKey)
+
+	spec := getApiSpec(apiID)
+	if spec == nil {
+		return C.CString("error: no loaded API found for " + apiID)
+	}
+
+	decision, err := rateCheck(spec, key)
+	if err != nil {
+		return C.CString("error: " + err.Error())
+	}
+
+	encoded, err := json.Marshal(decision)
+	if err != nil {
+		return C.CString("error: " + err.Error())
+	}
+	return C.CString(string(encoded))
+}
+
+// TykDecodeJWTClaims is a CoProcess API function that pulls the claims out
+// of a JWT without checking its signature, for a Pre hook on a keyless API
+// that wants to route on something like a region claim without the API
+// enabling JWTMiddleware. The claims are returned UNTRUSTED - nothing here
+// verifies the token was issued by who it claims, or that it wasn't
+// tampered with - so a hook must never use them for an authorization
+// decision, only for things safe to get wrong like routing. Returns a JSON
+// object of the claims on success, or "error: <reason>" if CToken isn't a
+// well-formed JWT.
+//
+//export TykDecodeJWTClaims
+func TykDecodeJWTClaims(CToken *C.char) *C.char {
+	token := C.GoString(CToken)
+
+	claims, err := decodeUnverifiedJWTClaims(token)
+	if err != nil {
+		return C.CString("error: " + err.Error())
+	}
+
+	encoded, err := json.Marshal(claims)
+	if err != nil {
+		return C.CString("error: " + err.Error())
+	}
+	return C.CString(string(encoded))
+}
+
+// TykSemverSatisfies is a CoProcess API function that checks a semantic
+// version against a constraint expression (e.g. ">= 1.2.0, < 2.0.0"),
+// handling pre-release/build metadata per semver precedence rules so hooks
+// don't need to reimplement version gating. Returns "true"/"false" on
+// success, or "error: <reason>" if either argument doesn't parse.
+//
+//export TykSemverSatisfies
+func TykSemverSatisfies(CVersion, CConstraint *C.char) *C.char {
+	version := C.GoString(CVersion)
+	constraintExpr := C.GoString(CConstraint)
+
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return C.CString("error: invalid version: " + err.Error())
+	}
+
+	c, err := semver.NewConstraint(constraintExpr)
+	if err != nil {
+		return C.CString("error: invalid constraint: " + err.Error())
+	}
+
+	return C.CString(strconv.FormatBool(c.Check(v)))
+}
+
+// TykApplyJSONPatch is a CoProcess API function that applies an RFC 6902
+// JSON Patch document to a response body, for hooks that want to make a
+// surgical edit without reconstructing the whole body. Returns the patched
+// body on success, or "error: <reason>" if the body/patch don't parse or an
+// operation targets a path that doesn't exist.
+//
+//export TykApplyJSONPatch
+func TykApplyJSONPatch(CBody, CPatch *C.char) *C.char {
+	body := C.GoString(CBody)
+	patch := C.GoString(CPatch)
+
+	patched, err := applyJSONPatch([]byte(body), []byte(patch))
+	if err != nil {
+		return C.CString("error: " + err.Error())
+	}
+	return C.CString(string(patched))
+}
+
+// TykConsistentHash is a CoProcess API function giving plugins access to the
+// gateway's consistent-hashing ring (see consistentHashNode), so every
+// plugin picking a shard for the same key/node-list agrees on the answer.
+// CNodes is a JSON array of {"name":string,"weight":int}; weight is optional
+// and defaults to 1. Returns the chosen node's name on success, or
+// "error: <reason>" if CNodes doesn't parse or is empty.
+//
+//export TykConsistentHash
+func TykConsistentHash(CKey, CNodes *C.char) *C.char {
+	var nodes []hashRingNode
+	if err := json.Unmarshal([]byte(C.GoString(CNodes)), &nodes); err != nil {
+		return C.CString("error: invalid nodes: " + err.Error())
+	}
+	if len(nodes) == 0 {
+		return C.CString("error: nodes must not be empty")
+	}
+
+	return C.CString(consistentHashNode(C.GoString(CKey), nodes))
+}
+
+// TykRESTToSOAP is a CoProcess API function that wraps CBody in a SOAP
+// envelope using CTemplate, a Go text/template rendered with "." set to
+// {Body: CBody} (see restToSOAP). Returns the rendered envelope on success,
+// or "error: <reason>" if the template doesn't parse or fails to render.
+//
+//export TykRESTToSOAP
+func TykRESTToSOAP(CBody, CTemplate *C.char) *C.char {
+	envelope, err := restToSOAP(C.GoString(CBody), C.GoString(CTemplate))
+	if err != nil {
+		return C.CString("error: " + err.Error())
+	}
+	return C.CString(envelope)
+}
+
+// soapToRESTResult is the structured result TykSOAPToREST returns, since the
+// CoProcess API's cgo boundary only carries strings. Fault is nil on a
+// successful unwrap; otherwise Body is empty and Fault describes what the
+// upstream reported, with HTTPStatus already mapped for a response hook to
+// apply directly via set_response_code.
+type soapToRESTResult struct {
+	Body       string `json:"body"`
+	Fault      string `json:"fault,omitempty"`
+	HTTPStatus int    `json:"http_status,omitempty"`
+}
+
+// TykSOAPToREST is a CoProcess API function that unwraps a SOAP envelope's
+// Body for use as a REST response body (see soapToREST). Returns JSON:
+// {"body": "..."} on success, or {"fault": "...", "http_status": N} if the
+// envelope carried a SOAP fault instead of a normal payload. Returns
+// "error: <reason>" if CBody isn't a well-formed SOAP envelope at all.
+//
+//export TykSOAPToREST
+func TykSOAPToREST(CBody *C.char) *C.char {
+	body, err := soapToREST(C.GoString(CBody))
+	var result soapToRESTResult
+	if fault, ok := err.(*soapFault); ok {
+		result.Fault = fault.Error()
+		result.HTTPStatus = soapFaultHTTPStatus(fault.Code)
+	} else if err != nil {
+		return C.CString("error: " + err.Error())
+	} else {
+		result.Body = body
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return C.CString("error: " + err.Error())
+	}
+	return C.CString(string(encoded))
+}
+
+// TykVerifyDigest is a CoProcess API function that checks CBody against a
+// Digest header value (RFC 3230, e.g. "SHA-256=base64==,MD5=base64=="),
+// using the gateway's own implementation so plugins don't recompute the
+// hash inconsistently. Supports md5 and sha-256; unrecognised algorithm
+// tokens in the header are ignored, but the header must contain at least
+// one supported one. Returns "true"/"false" on success, or
+// "error: <reason>" if the header doesn't parse or names no supported
+// algorithm.
+//
+//export TykVerifyDigest
+func TykVerifyDigest(CBody, CDigestHeader *C.char) *C.char {
+	body := C.GoString(CBody)
+	digestHeader := C.GoString(CDigestHeader)
+
+	ok, err := verifyDigest([]byte(body), digestHeader)
+	if err != nil {
+		return C.CString("error: " + err.Error())
+	}
+	return C.CString(strconv.FormatBool(ok))
+}
+
+// TykValidateEmail is a CoProcess API function that checks CEmail is a
+// single valid RFC 5322 address and normalizes it to lowercase, so plugins
+// validate consistently instead of reimplementing it with their own regex.
+// Returns JSON: {"valid":bool,"normalized":string}.
+//
+//export TykValidateEmail
+func TykValidateEmail(CEmail *C.char) *C.char {
+	result, err := json.Marshal(validateEmail(C.GoString(CEmail)))
+	if err != nil {
+		return C.CString("error: " + err.Error())
+	}
+	return C.CString(string(result))
+}
+
+// TykValidatePhone is a CoProcess API function that checks CPhone is a
+// plausible phone number and normalizes it to E.164 form. CRegion is an
+// ISO 3166-1 alpha-2 country code (e.g. "US"), used to qualify a number that
+// wasn't already given with a leading "+"; ignored if the number is already
+// international. See validatePhone's doc comment for the limits of this
+// check - it validates shape, not that the number is actually assigned.
+// Returns JSON: {"valid":bool,"normalized":string}.
+//
+//export TykValidatePhone
+func TykValidatePhone(CPhone, CRegion *C.char) *C.char {
+	result, err := json.Marshal(validatePhone(C.GoString(CPhone), C.GoString(CRegion)))
+	if err != nil {
+		return C.CString("error: " + err.Error())
+	}
+	return C.CString(string(result))
+}
+
+// TykPresign is a CoProcess API function that signs a URL with an expiry,
+// for plugins serving time-limited links (e.g. to object storage). CExpiry
+// is the lifetime of the link in seconds. CKeyRef names the secret in the
+// gateway's secrets store used to sign it. Returns the signed URL on
+// success, or "error: <reason>" if key_ref is unknown or the URL doesn't
+// parse.
+//
+//export TykPresign
+func TykPresign(CURL, CExpiry, CKeyRef *C.char) *C.char {
+	rawURL := C.GoString(CURL)
+	keyRef := C.GoString(CKeyRef)
+
+	expirySeconds, err := strconv.ParseInt(C.GoString(CExpiry), 10, 64)
+	if err != nil {
+		return C.CString("error: invalid expiry: " + err.Error())
+	}
+
+	signed, err := presignURL(rawURL, time.Duration(expirySeconds)*time.Second, keyRef)
+	if err != nil {
+		return C.CString("error: " + err.Error())
+	}
+	return C.CString(signed)
+}
+
+// sigV4SignRequest is the JSON shape TykSigV4Sign expects for CRequestJSON.
+type sigV4SignRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+// TykSigV4Sign is a CoProcess API function that signs an outgoing upstream
+// request with AWS Signature Version 4, so plugins fronting AWS services
+// don't need to reimplement SigV4 themselves. CCredentialsRef names an
+// access_key_id/secret_access_key(/session_token) JSON blob in the gateway's
+// secret backend, gated by PluginSecretsAllowlist the same as
+// TykGetSecret. Returns a JSON object of headers (Host, X-Amz-Date,
+// X-Amz-Content-Sha256, Authorization, and X-Amz-Security-Token for
+// temporary credentials) the caller must merge into the outgoing request -
+// it doesn't mutate anything itself. Returns "error: <reason>" if
+// credentials_ref is unknown/not allowlisted or the request JSON/URL don't
+// parse.
+//
+//export TykSigV4Sign
+func TykSigV4Sign(CRequestJSON, CCredentialsRef, CRegion, CService *C.char) *C.char {
+	var reqInput sigV4SignRequest
+	if err := json.Unmarshal([]byte(C.GoString(CRequestJSON)), &reqInput); err != nil {
+		return C.CString("error: invalid request JSON: " + err.Error())
+	}
+
+	headers, err := sigv4Sign(reqInput.Method, reqInput.URL, reqInput.Headers, []byte(reqInput.Body),
+		C.GoString(CCredentialsRef), C.GoString(CRegion), C.GoString(CService))
+	if err != nil {
+		return C.CString("error: " + err.Error())
+	}
+
+	result, err := json.Marshal(headers)
+	if err != nil {
+		return C.CString("error: " + err.Error())
+	}
+	return C.CString(string(result))
+}
+
+// defaultSecretCacheTTL bounds how long a resolved secret is cached, so
+// enrichment-call-heavy plugins don't re-hit env/vault/consul on every
+// request, while stale values still get refreshed reasonably quickly.
+const defaultSecretCacheTTL = 5 * time.Minute
+
+var pluginSecretCache = cache.New(defaultSecretCacheTTL, defaultSecretCacheTTL)
+
+// TykGetSecret is a CoProcess API function giving plugins read-only access
+// to the gateway's configured secret backend (env/vault/consul/secrets://)
+// by reference, e.g. "env://ENRICHMENT_KEY", so they don't need secrets
+// embedded in their own code or config. Only references present in
+// the gateway config's PluginSecretsAllowlist are resolved; anything else, or a
+// name the backend doesn't have, returns "error: ...". Resolved values are
+// never logged and are cached by reference for defaultSecretCacheTTL.
+//
+//export TykGetSecret
+func TykGetSecret(CName *C.char) *C.char {
+	name := C.GoString(CName)
+
+	allowed := false
+	for _, ref := range config.Global().PluginSecretsAllowlist {
+		if ref == name {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return C.CString("error: secret not allowlisted: " + name)
+	}
+
+	if cached, found := pluginSecretCache.Get(name); found {
+		return C.CString(cached.(string))
+	}
+
+	value, err := kvStore(name)
+	if err != nil || value == "" {
+		return C.CString("error: secret not found: " + name)
+	}
+
+	pluginSecretCache.Set(name, value, defaultSecretCacheTTL)
+	return C.CString(value)
+}
+
+// TykLogBlocked is a bridge for a hook to record that it has blocked or
+// dropped a request. It writes to a distinct "security" log channel, rather
+// than going through CoProcessLog's "python" one, so these entries can be
+// filtered and routed separately from ordinary coprocess debug/info logs.
+// It's a standalone, synchronous call made by the hook itself at the moment
+// it decides to block, so it's emitted even when the hook then short-circuits
+// the request - the gateway doesn't need to infer "blocked" from the
+// resulting status code. It also never touches the analytics pipeline, so it
+// can't duplicate the request's normal access log entry.
+//
+//export TykLogBlocked
+func TykLogBlocked(CReason, CDetails *C.char) {
+	reason := C.GoString(CReason)
+	details := C.GoString(CDetails)
+	log.WithFields(logrus.Fields{
+		"prefix":  "security",
+		"reason":  reason,
+		"details": details,
+	}).Warning("Request blocked by hook")
+}
+
+// TykTimeConvert is a CoProcess API function that reparses a timestamp from
+// one format/timezone into another, so hooks bridging systems with
+// different time conventions don't each reimplement parsing. CFromFormat
+// and CToFormat accept a handful of common names ("RFC3339", "unix", ...)
+// or an arbitrary Go reference-time layout; CTz is an IANA zone name
+// applied before formatting, or empty to leave the zone untouched. Returns
+// the converted value on success, or "error: <reason>" if the value,
+// format, or timezone don't parse.
+//
+//export TykTimeConvert
+func TykTimeConvert(CValue, CFromFormat, CToFormat, CTz *C.char) *C.char {
+	value := C.GoString(CValue)
+	fromFormat := C.GoString(CFromFormat)
+	toFormat := C.GoString(CToFormat)
+	tz := C.GoString(CTz)
+
+	converted, err := timeConvert(value, fromFormat, toFormat, tz)
+	if err != nil {
+		return C.CString("error: " + err.Error())
+	}
+	return C.CString(converted)
+}
+
 // CoProcessLog is a bridge for using Tyk log from CP.
+//
 //export CoProcessLog
 func CoProcessLog(CMessage, CLogLevel *C.char) {
 	message := C.GoString(CMessage)
@@ -99,6 +99,7 @@ func (b *Bundle) Verify() error {
 // AddToSpec attaches the custom middleware settings to an API definition.
 func (b *Bundle) AddToSpec() {
 	b.Spec.CustomMiddleware = b.Manifest.CustomMiddleware
+	b.Spec.CompositionRoutes = b.Manifest.Composition
 
 	// Load Python interpreter if the
 	if loadedDrivers[b.Spec.CustomMiddleware.Driver] == nil && b.Spec.CustomMiddleware.Driver == apidef.PythonDriver {
@@ -114,6 +115,21 @@ func (b *Bundle) AddToSpec() {
 			"prefix": "coprocess",
 		}).Info("Python dispatcher was initialized")
 	}
+
+	// Load Lua VM if this bundle requires it and it hasn't been loaded already.
+	if loadedDrivers[b.Spec.CustomMiddleware.Driver] == nil && b.Spec.CustomMiddleware.Driver == apidef.LuaDriver {
+		var err error
+		loadedDrivers[apidef.LuaDriver], err = NewLuaDispatcher()
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"prefix": "coprocess",
+			}).WithError(err).Error("Couldn't load Lua dispatcher")
+			return
+		}
+		log.WithFields(logrus.Fields{
+			"prefix": "coprocess",
+		}).Info("Lua dispatcher was initialized")
+	}
 	dispatcher := loadedDrivers[b.Spec.CustomMiddleware.Driver]
 	if dispatcher != nil {
 		dispatcher.HandleMiddlewareCache(&b.Manifest, b.Path)
@@ -298,6 +314,13 @@ func loadBundleManifest(bundle *Bundle, spec *APISpec, skipVerification bool) er
 		return err
 	}
 
+	if err := validateCompositionRoutes(bundle.Manifest.Composition); err != nil {
+		log.WithFields(logrus.Fields{
+			"prefix": "main",
+		}).Info("----> Invalid composition manifest for bundle: ", spec.CustomMiddlewareBundle, " ", err)
+		return err
+	}
+
 	if skipVerification {
 		return nil
 	}
@@ -318,7 +341,22 @@ func getBundleDestPath(spec *APISpec) string {
 	return filepath.Join(tykBundlePath, bundlePath)
 }
 
-// loadBundle wraps the load and save steps, it will return if an error occurs at any point.
+// loadBundle wraps the fetch, verify and save steps. It always re-fetches
+// and re-verifies the bundle - CustomMiddlewareBundle only names a bundle,
+// it isn't a content hash, so this is what lets a hook author push a new
+// build under the same name and have it picked up the next time the
+// gateway's existing hot-reload mechanism (a group reload, or an API
+// definition change) re-processes this API, without needing to rename the
+// bundle or restart the gateway. The new bundle is extracted to a staging
+// directory and only swapped into place (via os.Rename) once it's fetched
+// and its manifest loads successfully (a failed signature check is logged
+// by loadBundleManifest but doesn't stop activation), so a request already being served by the old
+// bundle's files keeps running against them - os.Rename doesn't invalidate
+// a file descriptor opened against the old directory entry - and finishes
+// against the old bundle rather than a half-written new one. If the fetch
+// fails or the manifest doesn't load, whatever was last successfully
+// activated on disk is used instead, so a transient bundle-server outage at reload time
+// doesn't take the middleware down.
 func loadBundle(spec *APISpec) error {
 	// Skip if no custom middleware bundle name is set.
 	if spec.CustomMiddlewareBundle == "" {
@@ -330,71 +368,52 @@ func loadBundle(spec *APISpec) error {
 		return bundleError(spec, nil, "No bundle base URL set, skipping bundle")
 	}
 
-	// get bundle destination on disk
 	destPath := getBundleDestPath(spec)
 
-	// Skip if the bundle destination path already exists.
-	// The bundle exists, load and return:
-	if _, err := os.Stat(destPath); err == nil {
-		log.WithFields(logrus.Fields{
-			"prefix": "main",
-		}).Info("Loading existing bundle: ", spec.CustomMiddlewareBundle)
-
-		bundle := Bundle{
-			Name: spec.CustomMiddlewareBundle,
-			Path: destPath,
-			Spec: spec,
-		}
-
-		err = loadBundleManifest(&bundle, spec, true)
-		if err != nil {
-			log.WithFields(logrus.Fields{
-				"prefix": "main",
-			}).Info("----> Couldn't load bundle: ", spec.CustomMiddlewareBundle, " ", err)
-		}
-
-		log.WithFields(logrus.Fields{
-			"prefix": "main",
-		}).Info("----> Using bundle: ", spec.CustomMiddlewareBundle)
-
-		bundle.AddToSpec()
-
-		return nil
-	}
-
 	log.WithFields(logrus.Fields{
 		"prefix": "main",
 	}).Info("----> Fetching Bundle: ", spec.CustomMiddlewareBundle)
 
 	bundle, err := fetchBundle(spec)
 	if err != nil {
+		if _, statErr := os.Stat(destPath); statErr == nil {
+			log.WithFields(logrus.Fields{
+				"prefix": "main",
+			}).Warning("----> Couldn't fetch bundle, using bundle already on disk: ", spec.CustomMiddlewareBundle, " ", err)
+			return loadActiveBundle(spec, destPath)
+		}
 		return bundleError(spec, err, "Couldn't fetch bundle")
 	}
 
-	if err := os.MkdirAll(destPath, 0700); err != nil {
-		return bundleError(spec, err, "Couldn't create bundle directory")
+	stagingPath := destPath + ".staging"
+	os.RemoveAll(stagingPath)
+	if err := os.MkdirAll(stagingPath, 0700); err != nil {
+		return bundleError(spec, err, "Couldn't create bundle staging directory")
 	}
 
-	if err := saveBundle(&bundle, destPath, spec); err != nil {
+	if err := saveBundle(&bundle, stagingPath, spec); err != nil {
+		os.RemoveAll(stagingPath)
 		return bundleError(spec, err, "Couldn't save bundle")
 	}
 
-	log.WithFields(logrus.Fields{
-		"prefix": "main",
-	}).Debug("----> Saving Bundle: ", spec.CustomMiddlewareBundle)
-
-	// Set the destination path:
-	bundle.Path = destPath
-
+	bundle.Path = stagingPath
 	if err := loadBundleManifest(&bundle, spec, false); err != nil {
+		os.RemoveAll(stagingPath)
 		bundleError(spec, err, "Couldn't load bundle")
 
-		if err := os.RemoveAll(bundle.Path); err != nil {
-			bundleError(spec, err, "Couldn't remove bundle")
+		if _, statErr := os.Stat(destPath); statErr == nil {
+			return loadActiveBundle(spec, destPath)
 		}
 		return nil
 	}
 
+	os.RemoveAll(destPath)
+	if err := os.Rename(stagingPath, destPath); err != nil {
+		os.RemoveAll(stagingPath)
+		return bundleError(spec, err, "Couldn't activate bundle")
+	}
+	bundle.Path = destPath
+
 	log.WithFields(logrus.Fields{
 		"prefix": "main",
 	}).Info("----> Bundle is valid, adding to spec: ", spec.CustomMiddlewareBundle)
@@ -404,6 +423,31 @@ func loadBundle(spec *APISpec) error {
 	return nil
 }
 
+// loadActiveBundle loads the bundle already sitting at destPath, without
+// re-verifying it (it was verified the last time it was activated), for use
+// when a fresh fetch isn't available.
+func loadActiveBundle(spec *APISpec, destPath string) error {
+	bundle := Bundle{
+		Name: spec.CustomMiddlewareBundle,
+		Path: destPath,
+		Spec: spec,
+	}
+
+	if err := loadBundleManifest(&bundle, spec, true); err != nil {
+		log.WithFields(logrus.Fields{
+			"prefix": "main",
+		}).Info("----> Couldn't load bundle: ", spec.CustomMiddlewareBundle, " ", err)
+	}
+
+	log.WithFields(logrus.Fields{
+		"prefix": "main",
+	}).Info("----> Using bundle: ", spec.CustomMiddlewareBundle)
+
+	bundle.AddToSpec()
+
+	return nil
+}
+
 // bundleError is a log helper.
 func bundleError(spec *APISpec, err error, message string) error {
 	if err != nil {
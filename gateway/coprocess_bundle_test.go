@@ -4,6 +4,7 @@ import (
 	"crypto/md5"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -238,3 +239,106 @@ func TestResponseOverride(t *testing.T) {
 		testOverride(t, RegisterBundle("jsvm_override", overrideResponseJSVM))
 	})
 }
+
+var slowPreHookPython = map[string]string{
+	"manifest.json": `
+		{
+		    "file_list": [
+		        "middleware.py"
+		    ],
+		    "custom_middleware": {
+		        "driver": "python",
+		        "pre": [{
+		            "name": "MyPreHook"
+		        }]
+		    }
+		}
+	`,
+	"middleware.py": `
+import time
+from tyk.decorators import *
+from gateway import TykGateway as tyk
+
+@Hook
+def MyPreHook(request, response, session, metadata, spec):
+	time.sleep(2)
+	return request, session
+`,
+}
+
+func TestHookTimeout(t *testing.T) {
+	ts := StartTest(TestConfig{
+		CoprocessConfig: config.CoProcessConfig{
+			EnableCoProcess:  true,
+			PythonPathPrefix: pkgPath,
+			HookTimeout:      1,
+		}})
+	defer ts.Close()
+
+	bundleID := RegisterBundle("slow_pre_hook", slowPreHookPython)
+	BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/test/"
+		spec.UseKeylessAccess = true
+		spec.CustomMiddlewareBundle = bundleID
+	})
+
+	time.Sleep(1 * time.Second)
+
+	ts.Run(t, test.TestCase{Path: "/test/", Code: http.StatusGatewayTimeout})
+}
+
+func hotReloadBundleJSVM(version string) map[string]string {
+	return map[string]string{
+		"manifest.json": `
+{
+    "file_list": [],
+    "custom_middleware": {
+        "driver": "otto",
+        "pre": [{
+            "name": "pre",
+            "path": "pre.js"
+        }]
+    }
+}
+`,
+		"pre.js": `
+var pre = new TykJS.TykMiddleware.NewMiddleware({});
+
+pre.NewProcessRequest(function(request, session) {
+	request.ReturnOverrides.ResponseBody = '` + version + `'
+	request.ReturnOverrides.ResponseCode = 200
+	return pre.ReturnData(request, {});
+});
+`,
+	}
+}
+
+func TestBundleHotReload(t *testing.T) {
+	ts := StartTest()
+	defer ts.Close()
+
+	bundleID := RegisterBundle("hot_reload_jsvm", hotReloadBundleJSVM("v1"))
+
+	BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/hot-reload/"
+		spec.UseKeylessAccess = true
+		spec.CustomMiddlewareBundle = bundleID
+	})
+
+	ts.Run(t, test.TestCase{Path: "/hot-reload/", Code: http.StatusOK, BodyMatch: "v1"})
+
+	// Swap in v2 of the same bundle (same name, new contents) and trigger a
+	// reload the same way a config change or a group reload signal would -
+	// no gateway restart.
+	testBundleMu.Lock()
+	testBundles[bundleID] = hotReloadBundleJSVM("v2")
+	testBundleMu.Unlock()
+
+	BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/hot-reload/"
+		spec.UseKeylessAccess = true
+		spec.CustomMiddlewareBundle = bundleID
+	})
+
+	ts.Run(t, test.TestCase{Path: "/hot-reload/", Code: http.StatusOK, BodyMatch: "v2"})
+}
@@ -0,0 +1,49 @@
+package gateway
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/headers"
+)
+
+// applyHookCompression compresses a response hook's body when it asked to via
+// ReturnOverrides.CompressResponse. It's a no-op whenever compressing would be
+// wrong or pointless: an unsupported encoding, a client that didn't advertise
+// support for it in Accept-Encoding, or a response that's already compressed
+// (Content-Encoding already set, which would otherwise double-compress it).
+func applyHookCompression(req *http.Request, res *http.Response, encoding string, body *bytes.Buffer) {
+	if encoding == "" {
+		return
+	}
+
+	if encoding != "gzip" {
+		log.Warningf("Response hook requested unsupported compression %q, ignoring", encoding)
+		return
+	}
+
+	if res.Header.Get(headers.ContentEncoding) != "" {
+		return
+	}
+
+	if !acceptsEncoding(req, encoding) {
+		return
+	}
+
+	compressed := compressBuffer(*body, encoding)
+	*body = compressed
+	res.Header.Set(headers.ContentEncoding, encoding)
+	res.ContentLength = int64(body.Len())
+	res.Header.Set(headers.ContentLength, strconv.Itoa(body.Len()))
+}
+
+func acceptsEncoding(req *http.Request, encoding string) bool {
+	for _, accepted := range strings.Split(req.Header.Get(headers.AcceptEncoding), ",") {
+		if strings.TrimSpace(accepted) == encoding {
+			return true
+		}
+	}
+	return false
+}
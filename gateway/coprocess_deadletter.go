@@ -0,0 +1,49 @@
+package gateway
+
+import "C"
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+// deadLetterKeyPrefix namespaces dead-letter queue entries in the gateway's
+// shared Redis store, separate from other coprocess-stored data.
+const deadLetterKeyPrefix = "coprocess-dlq:"
+
+// deadLetterQueueKey is the single list every dead-lettered request is
+// pushed onto; a replay worker reads it back with storage.GetListRange.
+const deadLetterQueueKey = "queue"
+
+// maxDeadLetterPayloadBytes bounds how large a single dead-lettered request
+// can be, so a hook enqueueing a request with a large body can't blow up the
+// DLQ's memory footprint. An oversized payload is dropped rather than
+// truncated, since a partial request body would be useless to whatever
+// replays it later.
+const maxDeadLetterPayloadBytes = 64 * 1024
+
+var deadLetterStore = storage.RedisCluster{KeyPrefix: deadLetterKeyPrefix}
+
+// TykDeadLetter is a CoProcess API function that enqueues a request a
+// response/error hook couldn't recover from, for later replay. CPayload is
+// whatever the plugin serialized the request to (JSON). An oversized
+// payload is dropped with a logged warning, as is an enqueue failure -
+// either way nothing is ever returned to the caller, since a hook calling
+// this is already handling one failure and shouldn't have that handling
+// short-circuited by a second, unrelated one.
+//
+//export TykDeadLetter
+func TykDeadLetter(CPayload *C.char) {
+	payload := C.GoString(CPayload)
+
+	if len(payload) > maxDeadLetterPayloadBytes {
+		log.WithFields(logrus.Fields{
+			"prefix": "coprocess",
+			"size":   len(payload),
+		}).Warning("Dead-lettered request exceeds size limit, dropping")
+		return
+	}
+
+	deadLetterStore.AppendToSet(deadLetterQueueKey, payload)
+}
@@ -0,0 +1,66 @@
+package gateway
+
+import (
+	"encoding/json"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/coprocess"
+)
+
+// applyEventPayloadHook runs the gateway-level event hook, configured via
+// CoProcessOptions.EventPayloadHookDriver/EventPayloadHookName, giving a
+// plugin the chance to enrich or reroute an event's payload before it
+// reaches the configured event handlers (e.g. the webhook notifier). Like
+// dispatchNotFoundHook it has no APISpec or bundle to run against - this is
+// a single, process-wide hook - so it talks to the driver's loaded
+// dispatcher directly. Whenever the hook isn't configured, isn't loaded, or
+// fails, em is returned unchanged and the failure is logged, so a broken or
+// slow plugin never suppresses the event itself.
+func applyEventPayloadHook(em config.EventMessage) config.EventMessage {
+	hookName := config.Global().CoProcessOptions.EventPayloadHookName
+	if hookName == "" {
+		return em
+	}
+
+	dispatcher := loadedDrivers[config.Global().CoProcessOptions.EventPayloadHookDriver]
+	if dispatcher == nil {
+		return em
+	}
+
+	payload, err := json.Marshal(em)
+	if err != nil {
+		log.WithError(err).Warning("couldn't marshal event payload for event hook, using default payload")
+		return em
+	}
+
+	object := &coprocess.Object{
+		HookType: coprocess.HookType_Event,
+		HookName: hookName,
+		Request: &coprocess.MiniRequestObject{
+			Body: string(payload),
+			ReturnOverrides: &coprocess.ReturnOverrides{
+				ResponseCode: -1,
+			},
+		},
+		Spec: map[string]string{"event_type": string(em.Type)},
+	}
+
+	retObject, err := dispatcher.Dispatch(object)
+	if err != nil {
+		log.WithError(err).Warning("event hook dispatch failed, falling back to default payload")
+		return em
+	}
+
+	overrides := retObject.GetRequest().GetReturnOverrides()
+	if overrides == nil || overrides.ResponseBody == "" {
+		return em
+	}
+
+	enriched := em
+	if err := json.Unmarshal([]byte(overrides.ResponseBody), &enriched); err != nil {
+		log.WithError(err).Warning("event hook returned an unparsable payload, falling back to default payload")
+		return em
+	}
+
+	return enriched
+}
@@ -0,0 +1,96 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/coprocess"
+)
+
+// fakeEventHookDispatcher is a minimal coprocess.Dispatcher used to exercise
+// applyEventPayloadHook without a real coprocess behind it.
+type fakeEventHookDispatcher struct {
+	coprocess.Dispatcher
+	dispatch func(*coprocess.Object) (*coprocess.Object, error)
+}
+
+func (f *fakeEventHookDispatcher) Dispatch(object *coprocess.Object) (*coprocess.Object, error) {
+	return f.dispatch(object)
+}
+
+func TestApplyEventPayloadHook(t *testing.T) {
+	em := config.EventMessage{
+		Type:      EventKeyExpired,
+		Meta:      EventKeyFailureMeta{Key: "abc123"},
+		TimeStamp: "then",
+	}
+
+	t.Run("no hook configured returns the payload unchanged", func(t *testing.T) {
+		globalConf := config.Global()
+		globalConf.CoProcessOptions.EventPayloadHookName = ""
+		config.SetGlobal(globalConf)
+
+		if got := applyEventPayloadHook(em); got.TimeStamp != em.TimeStamp {
+			t.Fatalf("expected unchanged payload, got %+v", got)
+		}
+	})
+
+	t.Run("hook enriches the payload", func(t *testing.T) {
+		globalConf := config.Global()
+		globalConf.CoProcessOptions.EventPayloadHookDriver = apidef.GrpcDriver
+		globalConf.CoProcessOptions.EventPayloadHookName = "enrich_expiry"
+		config.SetGlobal(globalConf)
+
+		loadedDrivers[apidef.GrpcDriver] = &fakeEventHookDispatcher{
+			dispatch: func(object *coprocess.Object) (*coprocess.Object, error) {
+				var decoded config.EventMessage
+				if err := json.Unmarshal([]byte(object.Request.Body), &decoded); err != nil {
+					t.Fatalf("hook received unparsable payload: %s", err.Error())
+				}
+				decoded.TimeStamp = "enriched"
+				enrichedBody, _ := json.Marshal(decoded)
+				object.Request.ReturnOverrides.ResponseBody = string(enrichedBody)
+				return object, nil
+			},
+		}
+		defer func() {
+			delete(loadedDrivers, apidef.GrpcDriver)
+			globalConf := config.Global()
+			globalConf.CoProcessOptions.EventPayloadHookDriver = ""
+			globalConf.CoProcessOptions.EventPayloadHookName = ""
+			config.SetGlobal(globalConf)
+		}()
+
+		got := applyEventPayloadHook(em)
+		if got.TimeStamp != "enriched" {
+			t.Fatalf("expected hook to enrich the payload, got %+v", got)
+		}
+	})
+
+	t.Run("a failing hook falls back to the default payload", func(t *testing.T) {
+		globalConf := config.Global()
+		globalConf.CoProcessOptions.EventPayloadHookDriver = apidef.GrpcDriver
+		globalConf.CoProcessOptions.EventPayloadHookName = "enrich_expiry"
+		config.SetGlobal(globalConf)
+
+		loadedDrivers[apidef.GrpcDriver] = &fakeEventHookDispatcher{
+			dispatch: func(object *coprocess.Object) (*coprocess.Object, error) {
+				return nil, errors.New("dispatch failed")
+			},
+		}
+		defer func() {
+			delete(loadedDrivers, apidef.GrpcDriver)
+			globalConf := config.Global()
+			globalConf.CoProcessOptions.EventPayloadHookDriver = ""
+			globalConf.CoProcessOptions.EventPayloadHookName = ""
+			config.SetGlobal(globalConf)
+		}()
+
+		if got := applyEventPayloadHook(em); got.TimeStamp != em.TimeStamp {
+			t.Fatalf("expected fallback to default payload, got %+v", got)
+		}
+	})
+}
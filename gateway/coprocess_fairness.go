@@ -0,0 +1,82 @@
+package gateway
+
+import (
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+// DefaultTenantFairnessWindowSeconds is used when TenantFairnessConfig.WindowSeconds is unset.
+const DefaultTenantFairnessWindowSeconds = 10
+
+// DefaultTenantFairnessOverageMultiplier is used when
+// TenantFairnessConfig.OverageMultiplier is unset. A tenant is admitted as
+// long as its usage within the window doesn't exceed its weighted fair share
+// by more than this factor.
+const DefaultTenantFairnessOverageMultiplier = 1.5
+
+// tenantFairnessSetKey is the sorted set tenantFairnessAdmit keeps each
+// tenant's current-window usage in, scored by request count, so the fair
+// share of every active tenant can be read back in a single round trip.
+const tenantFairnessSetKey = "tenant-fairness-usage"
+
+var tenantFairnessStore = storage.RedisCluster{KeyPrefix: "tenant-fairness-"}
+
+// tenantFairnessAdmit decides whether a request from tenantID should be
+// admitted under a weighted fair queuing policy: a tenant may use up to
+// OverageMultiplier times its weighted share of the gateway-wide request
+// volume seen across all tenants in the current window. Any failure talking
+// to store - the fairness computation is advisory, not a hard limit - admits
+// the request rather than blocking traffic.
+func tenantFairnessAdmit(tenantID string, store storage.Handler) bool {
+	if tenantID == "" {
+		return true
+	}
+
+	cfg := config.Global().TenantFairness
+
+	window := cfg.WindowSeconds
+	if window <= 0 {
+		window = DefaultTenantFairnessWindowSeconds
+	}
+
+	overage := cfg.OverageMultiplier
+	if overage <= 0 {
+		overage = DefaultTenantFairnessOverageMultiplier
+	}
+
+	usageKey := "usage-" + tenantID
+	count, _ := store.SetRollingWindow(usageKey, window, "-1", false)
+	if count < 0 {
+		// A store error surfaces as a negative count - admit rather than
+		// penalise the tenant for a store outage.
+		return true
+	}
+
+	store.AddToSortedSet(tenantFairnessSetKey, tenantID, float64(count))
+
+	tenants, usages, err := store.GetSortedSetRange(tenantFairnessSetKey, "-inf", "+inf")
+	if err != nil || len(tenants) <= 1 {
+		return true
+	}
+
+	var total, totalWeight float64
+	weight := 1.0
+	for i, t := range tenants {
+		w := cfg.Weights[t]
+		if w <= 0 {
+			w = 1.0
+		}
+		totalWeight += w
+		total += usages[i]
+		if t == tenantID {
+			weight = w
+		}
+	}
+
+	if totalWeight <= 0 {
+		return true
+	}
+
+	fairShare := (weight / totalWeight) * total
+	return float64(count) <= fairShare*overage
+}
@@ -1,7 +1,10 @@
 package gateway
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
+	"io/ioutil"
 	"net"
 	"net/url"
 	"time"
@@ -9,6 +12,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/config"
@@ -72,6 +76,58 @@ func (d *GRPCDispatcher) Reload() {}
 // HandleMiddlewareCache isn't used by gRPC.
 func (d *GRPCDispatcher) HandleMiddlewareCache(b *apidef.BundleManifest, basePath string) {}
 
+// grpcClientTLSConfig builds the *tls.Config for the coprocess gRPC
+// connection from a GRPCClientTLSConfig. CAFile is optional even when
+// CertFile is set: leaving it empty leaves RootCAs nil, which makes the Go
+// TLS stack verify the coprocess server's certificate against the system
+// root pool - correct when that certificate is signed by a public/
+// system-trusted CA and only a client certificate is needed for mTLS. Set
+// CAFile to verify against a private/self-signed CA instead. Split out from
+// grpcTransportCredentials so it can be unit tested without a real gRPC
+// dial - credentials.TransportCredentials doesn't expose its *tls.Config.
+func grpcClientTLSConfig(tlsConfig config.GRPCClientTLSConfig) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(tlsConfig.CertFile, tlsConfig.KeyFile)
+	if err != nil {
+		return nil, errors.New("couldn't load gRPC client certificate: " + err.Error())
+	}
+
+	var rootCAs *x509.CertPool
+	if tlsConfig.CAFile != "" {
+		rootCAs = x509.NewCertPool()
+		caCert, err := ioutil.ReadFile(tlsConfig.CAFile)
+		if err != nil {
+			return nil, errors.New("couldn't read gRPC client CA file: " + err.Error())
+		}
+		if !rootCAs.AppendCertsFromPEM(caCert) {
+			return nil, errors.New("couldn't parse gRPC client CA file")
+		}
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      rootCAs,
+		ServerName:   tlsConfig.ServerName,
+	}, nil
+}
+
+// grpcTransportCredentials builds the TLS dial option for the coprocess
+// gRPC connection from CoProcessOptions.GRPCClientTLS. An empty CertFile
+// means mTLS isn't configured, in which case it returns grpc.WithInsecure()
+// so existing plaintext deployments keep working unchanged.
+func grpcTransportCredentials() (grpc.DialOption, error) {
+	tlsConfig := config.Global().CoProcessOptions.GRPCClientTLS
+	if tlsConfig.CertFile == "" {
+		return grpc.WithInsecure(), nil
+	}
+
+	cfg, err := grpcClientTLSConfig(tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return grpc.WithTransportCredentials(credentials.NewTLS(cfg)), nil
+}
+
 func grpcCallOpts() grpc.DialOption {
 	recvSize := config.Global().CoProcessOptions.GRPCRecvMaxSize
 	sendSize := config.Global().CoProcessOptions.GRPCSendMaxSize
@@ -90,10 +146,17 @@ func NewGRPCDispatcher() (coprocess.Dispatcher, error) {
 	if config.Global().CoProcessOptions.CoProcessGRPCServer == "" {
 		return nil, errors.New("No gRPC URL is set")
 	}
-	var err error
+	transportCreds, err := grpcTransportCredentials()
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"prefix": "coprocess",
+		}).Error(err)
+		return nil, err
+	}
+
 	grpcConnection, err = grpc.Dial("",
 		grpcCallOpts(),
-		grpc.WithInsecure(),
+		transportCreds,
 		grpc.WithDialer(dialer),
 	)
 	grpcClient = coprocess.NewDispatcherClient(grpcConnection)
@@ -0,0 +1,96 @@
+package gateway
+
+import (
+	"context"
+	"sync"
+
+	"github.com/TykTechnologies/tyk/coprocess"
+)
+
+// GRPCStreamSession wraps a single, long-lived bidirectional DispatchStream
+// call, letting a hook observe and transform each frame of a proxied stream
+// instead of being limited to one request/response pair per connection.
+type GRPCStreamSession struct {
+	stream coprocess.Dispatcher_DispatchStreamClient
+	mu     sync.Mutex
+}
+
+// Forward sends object on the stream and returns the frame the hook sends
+// back for it. Frame order is preserved in both directions as long as
+// callers serialise their own Forward calls for a given session (a gRPC
+// stream allows one in-flight Send and one in-flight Recv, but not two
+// concurrent Sends or two concurrent Recvs).
+func (s *GRPCStreamSession) Forward(object *coprocess.Object) (*coprocess.Object, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.stream.Send(object); err != nil {
+		return nil, err
+	}
+	return s.stream.Recv()
+}
+
+// Close ends the underlying stream.
+func (s *GRPCStreamSession) Close() error {
+	return s.stream.CloseSend()
+}
+
+// grpcStreamSessions multiplexes DispatchStream calls, keyed by the proxied
+// connection they belong to, so each connection reuses a single stream
+// instead of every frame opening and tearing down a fresh one.
+type grpcStreamSessions struct {
+	mu       sync.Mutex
+	sessions map[string]*GRPCStreamSession
+}
+
+var sharedGRPCStreamSessions = &grpcStreamSessions{sessions: map[string]*GRPCStreamSession{}}
+
+// OpenStream returns the stream session for key, opening a new DispatchStream
+// call against the coprocess gRPC server if one doesn't already exist for
+// this connection.
+func (s *grpcStreamSessions) OpenStream(key string) (*GRPCStreamSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session, ok := s.sessions[key]; ok {
+		return session, nil
+	}
+
+	stream, err := grpcClient.DispatchStream(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	session := &GRPCStreamSession{stream: stream}
+	s.sessions[key] = session
+	return session, nil
+}
+
+// CloseStream closes and forgets the stream session for key, called once its
+// underlying connection goes away.
+func (s *grpcStreamSessions) CloseStream(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session, ok := s.sessions[key]; ok {
+		session.Close()
+		delete(s.sessions, key)
+	}
+}
+
+// DispatchStreamFrame forwards a single frame of the proxied connection
+// identified by key through the coprocess gRPC dispatcher's bidirectional
+// stream, opening that stream on its first frame.
+func (d *GRPCDispatcher) DispatchStreamFrame(key string, object *coprocess.Object) (*coprocess.Object, error) {
+	session, err := sharedGRPCStreamSessions.OpenStream(key)
+	if err != nil {
+		return nil, err
+	}
+	return session.Forward(object)
+}
+
+// CloseStreamFrame ends the stream session for a proxied connection that has
+// gone away, so its underlying gRPC stream doesn't linger.
+func (d *GRPCDispatcher) CloseStreamFrame(key string) {
+	sharedGRPCStreamSessions.CloseStream(key)
+}
@@ -0,0 +1,200 @@
+package gateway
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/coprocess"
+)
+
+// mockDispatcherServer is a minimal coprocess.DispatcherServer used to
+// exercise the gRPC connection without a real coprocess behind it.
+type mockDispatcherServer struct {
+	coprocess.UnimplementedDispatcherServer
+}
+
+func (m *mockDispatcherServer) Dispatch(ctx context.Context, obj *coprocess.Object) (*coprocess.Object, error) {
+	return obj, nil
+}
+
+func writeTempPEM(t *testing.T, pem []byte) string {
+	t.Helper()
+	f, err := ioutil.TempFile("", "coprocess-grpc-tls-*.pem")
+	if err != nil {
+		t.Fatalf("couldn't create temp file: %s", err.Error())
+	}
+	if _, err := f.Write(pem); err != nil {
+		t.Fatalf("couldn't write temp file: %s", err.Error())
+	}
+	f.Close()
+	t.Cleanup(func() { os.Remove(f.Name()) })
+	return f.Name()
+}
+
+// startMutualTLSDispatcherServer starts a mock coprocess gRPC server on an
+// ephemeral port, requiring and verifying a client certificate signed by
+// clientCACert.
+func startMutualTLSDispatcherServer(t *testing.T, serverCert tls.Certificate, clientCACert *x509.Certificate) (address string, stop func()) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err.Error())
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(clientCACert)
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}
+
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(tlsConfig)))
+	coprocess.RegisterDispatcherServer(server, &mockDispatcherServer{})
+
+	go server.Serve(lis)
+
+	return lis.Addr().String(), server.Stop
+}
+
+func TestGRPCClientTLS(t *testing.T) {
+	defer ResetTestConfig()
+
+	_, _, serverCombinedPEM, serverCert := genServerCertificate()
+	clientCertPEM, clientKeyPEM, _, clientCert := genCertificate(&x509.Certificate{})
+
+	clientLeaf, err := x509.ParseCertificate(clientCert.Certificate[0])
+	if err != nil {
+		t.Fatalf("couldn't parse client certificate: %s", err.Error())
+	}
+
+	caFile := writeTempPEM(t, serverCombinedPEM)
+
+	t.Run("dial is rejected without a client certificate", func(t *testing.T) {
+		address, stop := startMutualTLSDispatcherServer(t, serverCert, clientLeaf)
+		defer stop()
+
+		globalConf := config.Global()
+		globalConf.CoProcessOptions.GRPCClientTLS = config.GRPCClientTLSConfig{
+			CAFile:     caFile,
+			ServerName: "localhost",
+		}
+		config.SetGlobal(globalConf)
+
+		opt, err := grpcTransportCredentials()
+		if err != nil {
+			t.Fatalf("grpcTransportCredentials returned an error: %s", err.Error())
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		conn, err := grpc.DialContext(ctx, address, opt, grpc.WithBlock())
+		if err == nil {
+			conn.Close()
+			t.Fatal("expected the dial to fail without a client certificate")
+		}
+	})
+
+	t.Run("dial succeeds with a valid client certificate", func(t *testing.T) {
+		address, stop := startMutualTLSDispatcherServer(t, serverCert, clientLeaf)
+		defer stop()
+
+		globalConf := config.Global()
+		globalConf.CoProcessOptions.GRPCClientTLS = config.GRPCClientTLSConfig{
+			CertFile:   writeTempPEM(t, clientCertPEM),
+			KeyFile:    writeTempPEM(t, clientKeyPEM),
+			CAFile:     caFile,
+			ServerName: "localhost",
+		}
+		config.SetGlobal(globalConf)
+
+		opt, err := grpcTransportCredentials()
+		if err != nil {
+			t.Fatalf("grpcTransportCredentials returned an error: %s", err.Error())
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		conn, err := grpc.DialContext(ctx, address, opt, grpc.WithBlock())
+		if err != nil {
+			t.Fatalf("expected the dial to succeed with a valid client certificate, got: %s", err.Error())
+		}
+		defer conn.Close()
+
+		client := coprocess.NewDispatcherClient(conn)
+		if _, err := client.Dispatch(ctx, &coprocess.Object{}); err != nil {
+			t.Fatalf("Dispatch call failed: %s", err.Error())
+		}
+	})
+}
+
+// TestGRPCClientTLSConfig_EmptyCAFileUsesSystemPool checks that leaving
+// CAFile empty while CertFile is set leaves RootCAs nil, so the server
+// certificate is verified against the system root pool instead of an empty
+// pool that would trust nothing and reject every dial outright.
+func TestGRPCClientTLSConfig_EmptyCAFileUsesSystemPool(t *testing.T) {
+	clientCertPEM, clientKeyPEM, _, _ := genCertificate(&x509.Certificate{})
+
+	cfg, err := grpcClientTLSConfig(config.GRPCClientTLSConfig{
+		CertFile: writeTempPEM(t, clientCertPEM),
+		KeyFile:  writeTempPEM(t, clientKeyPEM),
+	})
+	if err != nil {
+		t.Fatalf("grpcClientTLSConfig returned an error: %s", err.Error())
+	}
+	if cfg.RootCAs != nil {
+		t.Fatal("expected RootCAs to be nil (system pool) when CAFile is empty")
+	}
+}
+
+// TestGRPCClientTLSConfig_CAFileSetsExplicitPool checks the opposite case:
+// a configured CAFile is parsed into a non-nil, non-empty pool.
+func TestGRPCClientTLSConfig_CAFileSetsExplicitPool(t *testing.T) {
+	clientCertPEM, clientKeyPEM, _, _ := genCertificate(&x509.Certificate{})
+	_, _, serverCombinedPEM, _ := genServerCertificate()
+
+	cfg, err := grpcClientTLSConfig(config.GRPCClientTLSConfig{
+		CertFile: writeTempPEM(t, clientCertPEM),
+		KeyFile:  writeTempPEM(t, clientKeyPEM),
+		CAFile:   writeTempPEM(t, serverCombinedPEM),
+	})
+	if err != nil {
+		t.Fatalf("grpcClientTLSConfig returned an error: %s", err.Error())
+	}
+	if cfg.RootCAs == nil {
+		t.Fatal("expected RootCAs to be set when CAFile is configured")
+	}
+	if len(cfg.RootCAs.Subjects()) == 0 {
+		t.Fatal("expected the configured CA to be present in the pool")
+	}
+}
+
+func TestGRPCTransportCredentials_NoClientCertConfigured(t *testing.T) {
+	defer ResetTestConfig()
+
+	globalConf := config.Global()
+	globalConf.CoProcessOptions.GRPCClientTLS = config.GRPCClientTLSConfig{}
+	config.SetGlobal(globalConf)
+
+	opt, err := grpcTransportCredentials()
+	if err != nil {
+		t.Fatalf("grpcTransportCredentials returned an error: %s", err.Error())
+	}
+	if opt == nil {
+		t.Fatal("expected a non-nil dial option")
+	}
+}
@@ -0,0 +1,85 @@
+package gateway
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/connectivity"
+
+	"github.com/TykTechnologies/tyk/config"
+)
+
+var (
+	coProcessHealthMu   sync.Mutex
+	coProcessHealthItem HealthCheckItem
+)
+
+// startCoProcessHealthCheck periodically records the gRPC coprocess
+// connection's state, so a coprocess that's gone away shows up as a
+// degraded "coprocess" component in the /hello health-check endpoint
+// instead of only surfacing the next time a coprocess-auth API request
+// fails with a confusing 500. Disabled when CoProcessPingInterval is zero,
+// and a no-op for any driver other than grpc - the in-process drivers have
+// no separate connection that can go stale.
+func startCoProcessHealthCheck(ctx context.Context) {
+	opts := config.Global().CoProcessOptions
+	if opts.CoProcessPingInterval <= 0 || opts.CoProcessGRPCServer == "" {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(opts.CoProcessPingInterval) * time.Second)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				pingCoProcess()
+			}
+		}
+	}()
+}
+
+// pingCoProcess checks the current gRPC connection state and records it for
+// gatherHealthChecks to pick up.
+func pingCoProcess() {
+	item := HealthCheckItem{
+		Status:        Pass,
+		ComponentType: System,
+		ComponentID:   "coprocess",
+		Time:          time.Now().Format(time.RFC3339),
+	}
+
+	switch {
+	case grpcConnection == nil:
+		item.Status = Fail
+		item.Output = "gRPC coprocess connection not established"
+	default:
+		switch state := grpcConnection.GetState(); state {
+		case connectivity.Ready, connectivity.Idle:
+			// Idle just means no RPC has been made recently; gRPC reconnects
+			// lazily on the next call, so it isn't a failure on its own.
+		default:
+			item.Status = Fail
+			item.Output = "gRPC coprocess connection state: " + state.String()
+		}
+	}
+
+	coProcessHealthMu.Lock()
+	coProcessHealthItem = item
+	coProcessHealthMu.Unlock()
+}
+
+// getCoProcessHealth returns the last recorded coprocess health item, and
+// whether the check has run at least once (e.g. it hasn't if the check is
+// disabled or the driver isn't grpc).
+func getCoProcessHealth() (HealthCheckItem, bool) {
+	coProcessHealthMu.Lock()
+	defer coProcessHealthMu.Unlock()
+	if coProcessHealthItem.Time == "" {
+		return HealthCheckItem{}, false
+	}
+	return coProcessHealthItem, true
+}
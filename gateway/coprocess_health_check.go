@@ -0,0 +1,81 @@
+package gateway
+
+import (
+	"time"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/coprocess"
+)
+
+// dispatchHealthCheckHook runs the gateway-level health-check hook, configured
+// via CoProcessOptions.HealthCheckHookDriver/HealthCheckHookName, letting a
+// plugin contribute its own sub-status to the /hello response alongside the
+// built-in redis/dashboard/rpc checks. Like dispatchNotFoundHook it's a
+// single, process-wide hook with no APISpec or bundle to run against, so it
+// talks to the driver's loaded dispatcher directly.
+//
+// The dispatch is bounded by HealthCheckHookTimeout (default 2s): a slow or
+// hung hook reports as a failed component instead of blocking the rest of
+// gatherHealthChecks's wg.Wait(). ok is false when the hook isn't configured
+// or isn't loaded, in which case the caller should skip publishing an item
+// for it at all.
+func dispatchHealthCheckHook() (item HealthCheckItem, ok bool) {
+	hookName := config.Global().CoProcessOptions.HealthCheckHookName
+	if hookName == "" {
+		return HealthCheckItem{}, false
+	}
+
+	dispatcher := loadedDrivers[config.Global().CoProcessOptions.HealthCheckHookDriver]
+	if dispatcher == nil {
+		return HealthCheckItem{}, false
+	}
+
+	timeout := config.Global().CoProcessOptions.HealthCheckHookTimeout
+	if timeout == 0 {
+		timeout = 2
+	}
+
+	object := &coprocess.Object{
+		HookType: coprocess.HookType_HealthCheck,
+		HookName: hookName,
+		Request:  &coprocess.MiniRequestObject{},
+		Spec:     map[string]string{},
+	}
+
+	result := make(chan *coprocess.Object, 1)
+	go func() {
+		retObject, err := dispatcher.Dispatch(object)
+		if err != nil {
+			mainLog.WithField("liveness-check", true).WithError(err).Error("health-check hook dispatch failed")
+			return
+		}
+		result <- retObject
+	}()
+
+	checkItem := HealthCheckItem{
+		Status:        Pass,
+		ComponentType: string(Component),
+		Time:          time.Now().Format(time.RFC3339),
+	}
+
+	select {
+	case retObject, received := <-result:
+		if !received || retObject == nil {
+			checkItem.Status = Fail
+			checkItem.Output = "health-check hook failed"
+			return checkItem, true
+		}
+
+		overrides := retObject.GetRequest().GetReturnOverrides()
+		if overrides != nil && overrides.ResponseCode >= 400 {
+			checkItem.Status = Fail
+			checkItem.Output = overrides.ResponseBody
+		}
+
+	case <-time.After(time.Duration(timeout) * time.Second):
+		checkItem.Status = Fail
+		checkItem.Output = "health-check hook timed out"
+	}
+
+	return checkItem, true
+}
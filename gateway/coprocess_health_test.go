@@ -0,0 +1,65 @@
+package gateway
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/TykTechnologies/tyk/coprocess"
+)
+
+// TestPingCoProcess_Degraded starts a plaintext mock coprocess, dials it the
+// same way NewGRPCDispatcher would, then kills the mock server and checks
+// that pingCoProcess (and therefore the /hello health-check endpoint)
+// reports the coprocess component as degraded.
+func TestPingCoProcess_Degraded(t *testing.T) {
+	origConn := grpcConnection
+	defer func() {
+		grpcConnection = origConn
+		coProcessHealthMu.Lock()
+		coProcessHealthItem = HealthCheckItem{}
+		coProcessHealthMu.Unlock()
+	}()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err.Error())
+	}
+
+	server := grpc.NewServer()
+	coprocess.RegisterDispatcherServer(server, &mockDispatcherServer{})
+	go server.Serve(lis)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, lis.Addr().String(), grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		t.Fatalf("couldn't dial mock coprocess: %s", err.Error())
+	}
+	defer conn.Close()
+
+	grpcConnection = conn
+
+	pingCoProcess()
+	if item, ok := getCoProcessHealth(); !ok || item.Status != Pass {
+		t.Fatalf("expected a healthy coprocess, got %+v (ok=%v)", item, ok)
+	}
+
+	server.Stop()
+	lis.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		pingCoProcess()
+		if item, _ := getCoProcessHealth(); item.Status == Fail {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	t.Fatal("expected the coprocess health check to report the killed coprocess as degraded")
+}
@@ -2,9 +2,14 @@ package gateway
 
 import (
 	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/coprocess"
 	"github.com/TykTechnologies/tyk/user"
 )
@@ -56,7 +61,7 @@ func TykSessionState(session *coprocess.SessionState) *user.SessionState {
 	metadata := make(map[string]interface{})
 	if session.Metadata != nil {
 		for k, v := range session.Metadata {
-			metadata[k] = v
+			metadata[k] = decodeMetadataValue(v)
 		}
 	}
 
@@ -91,9 +96,30 @@ func TykSessionState(session *coprocess.SessionState) *user.SessionState {
 		LastUpdated:             session.LastUpdated,
 		IdExtractorDeadline:     session.IdExtractorDeadline,
 		SessionLifetime:         session.SessionLifetime,
+		RateLimitAlgorithm:      session.RateAlgorithm,
 	}
 }
 
+// decodeMetadataValue mirrors ProtoSessionState's encoding of non-string
+// session.MetaData values (anything that isn't already a Go string is
+// json.Marshal'd before being sent to a hook as an entry in coprocess's
+// string-valued metadata map), so a value a hook sets - e.g. Python's
+// metadata["limits"] = {"rate": 10} - round-trips back as its native type
+// rather than raw JSON text. A value that doesn't parse as JSON, or that
+// parses to a JSON string, is kept as the original string: that's what lets
+// a hook also still set plain strings like metadata["token"] = "abc"
+// without it being reinterpreted as something else.
+func decodeMetadataValue(raw string) interface{} {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(raw), &decoded); err != nil {
+		return raw
+	}
+	if _, isString := decoded.(string); isString {
+		return raw
+	}
+	return decoded
+}
+
 // ProtoSessionState takes a standard SessionState and outputs a SessionState object compatible with Protocol Buffers.
 func ProtoSessionState(session *user.SessionState) *coprocess.SessionState {
 
@@ -177,7 +203,145 @@ func ProtoSessionState(session *user.SessionState) *coprocess.SessionState {
 		LastUpdated:             session.LastUpdated,
 		IdExtractorDeadline:     session.IdExtractorDeadline,
 		SessionLifetime:         session.SessionLifetime,
+		RateAlgorithm:           session.RateLimitAlgorithm,
+	}
+}
+
+// effectiveAllowedPaths returns the policy-merged allowed path patterns (and
+// their permitted methods) for this API, taken from
+// session.AccessRights[spec.APIID].AllowedURLs - the same field
+// ApplyPolicies already populates by merging every matched policy's
+// AllowedURLs, so a hook doesn't need to know which policy contributed what
+// or re-merge anything itself. This repo's policy model is allow-list only
+// (there's no separate "blocked paths" concept to mirror); an API or key
+// with no path restriction simply has an empty list here, which this always
+// returns as an empty (non-nil) slice, not nil, so a hook checking length
+// sees "no restriction" the same way whether or not a session exists yet
+// (e.g. during a pre hook, before auth has run).
+func effectiveAllowedPaths(spec *APISpec, session *user.SessionState) []user.AccessSpec {
+	allowed := []user.AccessSpec{}
+	if session == nil {
+		return allowed
+	}
+	if rights, ok := session.AccessRights[spec.APIID]; ok {
+		allowed = append(allowed, rights.AllowedURLs...)
+	}
+	return allowed
+}
+
+// retryAfterHeaderValue validates a hook-supplied Retry-After value and
+// returns the string to put in the header. A value is valid if it's a
+// non-negative integer (delta-seconds, passed through unchanged) or
+// anything http.ParseTime accepts (normalised to http.TimeFormat, matching
+// what a Date/Sunset header looks like on the wire). ok is false, and the
+// value should be ignored, for anything else - including the empty string.
+func retryAfterHeaderValue(raw string) (value string, ok bool) {
+	if raw == "" {
+		return "", false
 	}
+	if n, err := strconv.Atoi(raw); err == nil && n >= 0 {
+		return raw, true
+	}
+	if t, err := http.ParseTime(raw); err == nil {
+		return t.UTC().Format(http.TimeFormat), true
+	}
+	return "", false
+}
+
+// tlsOCSPStatus reports whether the request's client connection or the
+// upstream's response connection presented a stapled OCSP response, for
+// hooks doing compliance monitoring. It only looks at whatever crypto/tls
+// already captured during the handshake (ConnectionState.OCSPResponse) -
+// it never performs an OCSP lookup or validates the response itself, so a
+// "present" staple here means only that one was seen, not that it's valid.
+// Returns "unknown" whenever neither connection used stapling, or TLS wasn't
+// used at all.
+func tlsOCSPStatus(req *http.Request, res *http.Response) string {
+	if req != nil && req.TLS != nil && len(req.TLS.OCSPResponse) > 0 {
+		return "present"
+	}
+	if res != nil && res.TLS != nil && len(res.TLS.OCSPResponse) > 0 {
+		return "present"
+	}
+	return "unknown"
+}
+
+// uptimeTestStatus reports the API's upstream targets' latest uptime-test
+// result for hooks doing health-aware routing. It only reads
+// GlobalHostChecker's cached state (the same non-blocking cache HostDown
+// already uses for live traffic decisions) - it never triggers a check of its
+// own. status is "not_tested" whenever uptime tests aren't configured or
+// enabled for this API, or polling hasn't produced a result yet; otherwise
+// it's "up" or "down" (down if any checked target is down). checkedAt is the
+// most recent check time across all targets, RFC3339-formatted, or empty
+// alongside a "not_tested" status.
+func uptimeTestStatus(spec *APISpec) (status string, checkedAt string) {
+	if len(spec.UptimeTests.CheckList) == 0 || !spec.Proxy.CheckHostAgainstUptimeTests {
+		return "not_tested", ""
+	}
+
+	down := false
+	var lastChecked time.Time
+	checked := false
+
+	for _, host := range spec.UptimeTests.CheckList {
+		if GlobalHostChecker.HostDown(host.CheckURL) {
+			down = true
+		}
+		if t, ok := GlobalHostChecker.LastChecked(host.CheckURL); ok {
+			checked = true
+			if t.After(lastChecked) {
+				lastChecked = t
+			}
+		}
+	}
+
+	if !checked {
+		return "not_tested", ""
+	}
+	if down {
+		return "down", lastChecked.UTC().Format(time.RFC3339)
+	}
+	return "up", lastChecked.UTC().Format(time.RFC3339)
+}
+
+// normalizeGraphQLQuery collapses runs of whitespace in a GraphQL query
+// down to a single space and trims the ends, so a hook hashing the result
+// into an analytics signature gets the same value for queries that only
+// differ in formatting (indentation, line breaks, extra spaces).
+func normalizeGraphQLQuery(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+// effectiveMaxBodySize returns the most restrictive applicable limit on this
+// request's body, across the API version's global size limit, any matched
+// per-endpoint size limit (see RequestSizeLimitMiddleware), and mw's own
+// MaxRequestBodySize (see CoProcessMiddleware.enforceMaxRequestBodySize) -
+// so a hook validating body size itself can report the same limit the
+// gateway would actually enforce, rather than guessing or hardcoding one.
+// Returns 0 if none of these are configured, meaning unlimited.
+func effectiveMaxBodySize(spec *APISpec, mw *CoProcessMiddleware, req *http.Request) int64 {
+	var limit int64
+	narrow := func(candidate int64) {
+		if candidate <= 0 {
+			return
+		}
+		if limit == 0 || candidate < limit {
+			limit = candidate
+		}
+	}
+
+	vInfo, versionPaths, _, _ := spec.Version(req)
+	narrow(vInfo.GlobalSizeLimit)
+	if found, meta := spec.CheckSpecMatchesStatus(req, versionPaths, RequestSizeLimit); found {
+		narrow(meta.(*apidef.RequestSizeMeta).SizeLimit)
+	}
+
+	if mw != nil {
+		narrow(mw.MaxRequestBodySize)
+	}
+
+	return limit
 }
 
 // ProtoMap is a helper function for maps with string slice values.
@@ -85,20 +85,6 @@ const (
 	MiddlewareBasePath = "middleware/lua"
 )
 
-func init() {
-	var err error
-	loadedDrivers[apidef.LuaDriver], err = NewLuaDispatcher()
-	if err == nil {
-		log.WithFields(logrus.Fields{
-			"prefix": "coprocess",
-		}).Info("Lua dispatcher was initialized")
-	} else {
-		log.WithFields(logrus.Fields{
-			"prefix": "coprocess",
-		}).WithError(err).Error("Couldn't load Lua dispatcher")
-	}
-}
-
 // gMiddlewareCache will hold LuaDispatcher.gMiddlewareCache.
 var gMiddlewareCache map[string]string
 var gModuleCache map[string]string
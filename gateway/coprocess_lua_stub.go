@@ -0,0 +1,17 @@
+// +build !lua
+
+// This only builds when the "lua" build tag isn't set, since the real Lua
+// driver requires luajit (via pkg-config) which isn't available on every
+// build host. Yet, we have to maintain symbol compatibility for the main
+// package.
+package gateway
+
+import (
+	"errors"
+
+	"github.com/TykTechnologies/tyk/coprocess"
+)
+
+func NewLuaDispatcher() (dispatcher coprocess.Dispatcher, err error) {
+	return nil, errors.New("lua support not compiled")
+}
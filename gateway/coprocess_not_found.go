@@ -0,0 +1,66 @@
+package gateway
+
+import (
+	"net/http"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/coprocess"
+)
+
+// dispatchNotFoundHook runs the gateway-level not-found hook, configured via
+// CoProcessOptions.NotFoundHookDriver/NotFoundHookName, for a request that
+// matched no API. Unlike per-API hooks it has no APISpec or bundle to run
+// against - by definition nothing matched - so it talks to the driver's
+// loaded dispatcher directly rather than going through CoProcessMiddleware.
+// Returns false, without writing anything to rw, whenever the hook isn't
+// configured, isn't loaded, or fails, so the caller falls back to the
+// gateway's default 404.
+func dispatchNotFoundHook(rw http.ResponseWriter, r *http.Request) bool {
+	hookName := config.Global().CoProcessOptions.NotFoundHookName
+	if hookName == "" {
+		return false
+	}
+
+	dispatcher := loadedDrivers[config.Global().CoProcessOptions.NotFoundHookDriver]
+	if dispatcher == nil {
+		return false
+	}
+
+	headers := ProtoMap(r.Header)
+	if r.Host != "" {
+		headers["Host"] = r.Host
+	}
+
+	object := &coprocess.Object{
+		HookType: coprocess.HookType_NotFound,
+		HookName: hookName,
+		Request: &coprocess.MiniRequestObject{
+			Headers:    headers,
+			Url:        r.URL.String(),
+			Method:     r.Method,
+			RequestUri: r.RequestURI,
+			ReturnOverrides: &coprocess.ReturnOverrides{
+				ResponseCode: -1,
+			},
+		},
+		Spec: map[string]string{},
+	}
+
+	retObject, err := dispatcher.Dispatch(object)
+	if err != nil {
+		log.WithError(err).Warning("not-found hook dispatch failed, falling back to default 404")
+		return false
+	}
+
+	overrides := retObject.GetRequest().GetReturnOverrides()
+	if overrides == nil || overrides.ResponseCode <= 0 {
+		return false
+	}
+
+	for h, v := range overrides.Headers {
+		rw.Header().Set(h, v)
+	}
+	rw.WriteHeader(int(overrides.ResponseCode))
+	rw.Write([]byte(overrides.ResponseBody))
+	return true
+}
@@ -26,14 +26,26 @@ import (
 )
 
 var (
-	dispatcherClass    unsafe.Pointer
-	dispatcherInstance unsafe.Pointer
-	pythonLock         = sync.Mutex{}
+	dispatcherClass unsafe.Pointer
+	// pythonLock serialises every call into the embedded Python C API,
+	// across every PythonDispatcher instance. There's a single process-wide
+	// CPython interpreter here, not one per instance, so pooling multiple
+	// PythonDispatchers (see newDispatcherPool) doesn't let two Dispatch
+	// calls run concurrently - it only removes the per-request interpreter
+	// setup/teardown cost of a fresh instance. Genuine concurrent execution
+	// would need per-call PyGILState_Ensure/Release instead of this single
+	// Go-level mutex, which isn't implemented.
+	pythonLock = sync.Mutex{}
 )
 
-// PythonDispatcher implements a coprocess.Dispatcher
+// PythonDispatcher implements a coprocess.Dispatcher. Each instance wraps its
+// own TykDispatcher Python object, so multiple PythonDispatchers can be
+// pooled (see NewPythonDispatcher) without stepping on each other's Python
+// object state - but see pythonLock for why that pooling still can't run
+// two dispatches concurrently.
 type PythonDispatcher struct {
 	coprocess.Dispatcher
+	instance unsafe.Pointer
 }
 
 // Dispatch takes a CoProcessMessage and sends it to the CP.
@@ -46,7 +58,7 @@ func (d *PythonDispatcher) Dispatch(object *coprocess.Object) (*coprocess.Object
 
 	pythonLock.Lock()
 	// Find the dispatch_hook:
-	dispatchHookFunc, err := python.PyObjectGetAttr(dispatcherInstance, "dispatch_hook")
+	dispatchHookFunc, err := python.PyObjectGetAttr(d.instance, "dispatch_hook")
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"prefix": "python",
@@ -151,7 +163,7 @@ func (d *PythonDispatcher) Reload() {
 func (d *PythonDispatcher) HandleMiddlewareCache(b *apidef.BundleManifest, basePath string) {
 	pythonLock.Lock()
 	defer pythonLock.Unlock()
-	dispatcherLoadBundle, err := python.PyObjectGetAttr(dispatcherInstance, "load_bundle")
+	dispatcherLoadBundle, err := python.PyObjectGetAttr(d.instance, "load_bundle")
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"prefix": "python",
@@ -237,7 +249,7 @@ func PythonNewDispatcher(bundleRootPath string) (coprocess.Dispatcher, error) {
 		python.PyErr_Print()
 		return nil, err
 	}
-	dispatcherInstance, err = python.PyObjectCallObject(dispatcherClass, args)
+	instance, err := python.PyObjectCallObject(dispatcherClass, args)
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"prefix": "python",
@@ -245,7 +257,7 @@ func PythonNewDispatcher(bundleRootPath string) (coprocess.Dispatcher, error) {
 		python.PyErr_Print()
 		return nil, err
 	}
-	dispatcher := &PythonDispatcher{}
+	dispatcher := &PythonDispatcher{instance: instance}
 	return dispatcher, nil
 }
 
@@ -301,7 +313,15 @@ func NewPythonDispatcher() (dispatcher coprocess.Dispatcher, err error) {
 			initDone <- err
 			return
 		}
-		dispatcher, err = PythonNewDispatcher(bundleRootPath)
+
+		poolSize := config.Global().CoProcessOptions.PythonWorkerPoolSize
+		if poolSize > 1 {
+			dispatcher, err = newDispatcherPool(poolSize, func() (coprocess.Dispatcher, error) {
+				return PythonNewDispatcher(bundleRootPath)
+			})
+		} else {
+			dispatcher, err = PythonNewDispatcher(bundleRootPath)
+		}
 		if err != nil {
 			log.WithFields(logrus.Fields{
 				"prefix": "coprocess",
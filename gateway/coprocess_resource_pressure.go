@@ -0,0 +1,82 @@
+package gateway
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// resourcePressureSampleInterval is how often sampleResourcePressure refreshes
+// the indicators a Pre hook reads off the spec. Sampling runtime.MemStats is
+// too costly to do on every request, so it's done on this fixed background
+// cadence instead and hooks read whatever the last sample was.
+const resourcePressureSampleInterval = 5 * time.Second
+
+// ResourcePressure is a coarse, advisory snapshot of gateway load a hook can
+// use to decide whether to skip optional enrichment work. MemoryUsedPercent
+// is HeapAlloc as a percentage of HeapSys (how much of the memory the Go
+// runtime has obtained from the OS is actually in live objects) - it isn't a
+// measure of total system memory.
+type ResourcePressure struct {
+	MemoryUsedPercent float64
+	GoroutineCount    int
+	SampledAt         time.Time
+}
+
+var (
+	currentResourcePressure   ResourcePressure
+	currentResourcePressureMu sync.RWMutex
+)
+
+func sampleResourcePressure() {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	var usedPercent float64
+	if mem.HeapSys > 0 {
+		usedPercent = float64(mem.HeapAlloc) / float64(mem.HeapSys) * 100
+	}
+
+	currentResourcePressureMu.Lock()
+	currentResourcePressure = ResourcePressure{
+		MemoryUsedPercent: usedPercent,
+		GoroutineCount:    runtime.NumGoroutine(),
+		SampledAt:         time.Now(),
+	}
+	currentResourcePressureMu.Unlock()
+}
+
+// getResourcePressure returns the most recent background sample. Safe to
+// call per-request - it never itself touches runtime.MemStats.
+func getResourcePressure() ResourcePressure {
+	currentResourcePressureMu.RLock()
+	defer currentResourcePressureMu.RUnlock()
+	return currentResourcePressure
+}
+
+// initResourcePressureSampler starts the background sampler that keeps
+// getResourcePressure's snapshot fresh, refreshing every
+// resourcePressureSampleInterval until ctx is cancelled.
+func initResourcePressureSampler(ctx context.Context) {
+	sampleResourcePressure()
+
+	go func() {
+		ticker := time.NewTicker(resourcePressureSampleInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				mainLog.WithFields(logrus.Fields{
+					"prefix": "coprocess",
+				}).Debug("Stopping resource pressure sampler")
+				return
+			case <-ticker.C:
+				sampleResourcePressure()
+			}
+		}
+	}()
+}
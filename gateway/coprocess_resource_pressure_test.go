@@ -0,0 +1,18 @@
+package gateway
+
+import "testing"
+
+func TestSampleResourcePressure(t *testing.T) {
+	sampleResourcePressure()
+
+	pressure := getResourcePressure()
+	if pressure.SampledAt.IsZero() {
+		t.Fatal("expected a non-zero SampledAt after sampling")
+	}
+	if pressure.GoroutineCount <= 0 {
+		t.Errorf("expected a positive goroutine count, got %d", pressure.GoroutineCount)
+	}
+	if pressure.MemoryUsedPercent < 0 {
+		t.Errorf("expected a non-negative memory used percent, got %f", pressure.MemoryUsedPercent)
+	}
+}
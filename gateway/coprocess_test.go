@@ -0,0 +1,368 @@
+package gateway
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/coprocess"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func TestClientCertDetails(t *testing.T) {
+	t.Run("plaintext request", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if got := clientCertDetails(r); got != nil {
+			t.Fatalf("expected nil for a plaintext request, got %+v", got)
+		}
+	})
+
+	t.Run("mTLS request", func(t *testing.T) {
+		_, _, _, clientCert := genCertificate(&x509.Certificate{
+			Subject:  pkix.Name{CommonName: "test-client"},
+			DNSNames: []string{"client.example.com"},
+		})
+		leaf, err := x509.ParseCertificate(clientCert.Certificate[0])
+		if err != nil {
+			t.Fatalf("couldn't parse generated certificate: %s", err.Error())
+		}
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}
+
+		got := clientCertDetails(r)
+		if got == nil {
+			t.Fatal("expected non-nil TLS details for an mTLS request")
+		}
+		if got.Subject != leaf.Subject.String() {
+			t.Errorf("wrong subject: got %q, expected %q", got.Subject, leaf.Subject.String())
+		}
+		if got.Fingerprint == "" {
+			t.Error("expected a non-empty fingerprint")
+		}
+		found := false
+		for _, san := range got.Sans {
+			if san == "client.example.com" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected sans to include client.example.com, got %v", got.Sans)
+		}
+	})
+}
+
+// TestCoProcessSessionState_MultiplePoliciesFromAuthHook checks that an auth
+// hook can hand back a session with multiple apply_policies IDs - as opposed
+// to the single, legacy apply_policy_id - and have them merged by the same
+// policy-partitioning rules BaseMiddleware.ApplyPolicies already applies to
+// dashboard-created keys.
+func TestCoProcessSessionState_MultiplePoliciesFromAuthHook(t *testing.T) {
+	quotaPolicyID := CreatePolicy(func(p *user.Policy) {
+		p.OrgID = "default"
+		p.Partitions = user.PolicyPartitions{Quota: true}
+		p.QuotaMax = 50
+		p.QuotaRenewalRate = 3600
+		p.AccessRights = map[string]user.AccessDefinition{
+			"coprocess-api": {Versions: []string{"v1"}},
+		}
+	})
+	ratePolicyID := CreatePolicy(func(p *user.Policy) {
+		p.OrgID = "default"
+		p.Partitions = user.PolicyPartitions{RateLimit: true}
+		p.Rate = 10
+		p.Per = 1
+		p.AccessRights = map[string]user.AccessDefinition{
+			"coprocess-api": {Versions: []string{"v1"}},
+		}
+	})
+
+	// As an auth hook would return it: apply_policies lists both IDs.
+	protoSession := &coprocess.SessionState{
+		ApplyPolicies: []string{quotaPolicyID, ratePolicyID},
+		OrgId:         "default",
+	}
+	session := TykSessionState(protoSession)
+
+	mw := BaseMiddleware{Spec: &APISpec{APIDefinition: &apidef.APIDefinition{OrgID: "default"}}}
+	if err := mw.ApplyPolicies(session); err != nil {
+		t.Fatalf("ApplyPolicies returned an error: %s", err.Error())
+	}
+
+	rights, ok := session.AccessRights["coprocess-api"]
+	if !ok || rights.Limit == nil {
+		t.Fatalf("expected access rights with a limit for coprocess-api, got %+v", session.AccessRights)
+	}
+	if rights.Limit.QuotaMax != 50 {
+		t.Errorf("expected quota max 50 (from %s), got %d", quotaPolicyID, rights.Limit.QuotaMax)
+	}
+	if rights.Limit.Rate != 10 {
+		t.Errorf("expected rate 10 (from %s), got %f", ratePolicyID, rights.Limit.Rate)
+	}
+}
+
+func TestJwtHeaderUnverified(t *testing.T) {
+	t.Run("valid JWT", func(t *testing.T) {
+		// Header: {"alg":"HS256","typ":"JWT","kid":"test-kid"}
+		token := "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCIsImtpZCI6InRlc3Qta2lkIn0.eyJzdWIiOiIxMjM0NTY3ODkwIn0.sig"
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+
+		header := jwtHeaderUnverified(r)
+		if header["alg"] != "HS256" {
+			t.Errorf("expected alg HS256, got %q", header["alg"])
+		}
+		if header["kid"] != "test-kid" {
+			t.Errorf("expected kid test-kid, got %q", header["kid"])
+		}
+	})
+
+	t.Run("non-JWT request", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", "not-a-jwt")
+
+		if header := jwtHeaderUnverified(r); len(header) != 0 {
+			t.Errorf("expected an empty header map, got %+v", header)
+		}
+	})
+
+	t.Run("no Authorization header", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		if header := jwtHeaderUnverified(r); len(header) != 0 {
+			t.Errorf("expected an empty header map, got %+v", header)
+		}
+	})
+}
+
+func TestBuildObject_VersionSpec(t *testing.T) {
+	spec := &APISpec{APIDefinition: &apidef.APIDefinition{}}
+	mw := &CoProcessMiddleware{
+		BaseMiddleware: BaseMiddleware{Spec: spec},
+		HookType:       coprocess.HookType_Post,
+		HookName:       "version_spec_hook",
+	}
+	c := &CoProcessor{Middleware: mw}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctxSetVersionInfo(r, &apidef.VersionInfo{Name: "v2"})
+
+	object, err := c.BuildObject(r, nil)
+	if err != nil {
+		t.Fatalf("BuildObject returned an error: %s", err.Error())
+	}
+
+	if got := object.Spec["version_name"]; got != "v2" {
+		t.Errorf("expected version_name %q, got %q", "v2", got)
+	}
+	if object.Spec["version_data"] == "" {
+		t.Error("expected a non-empty version_data")
+	}
+}
+
+func TestEffectiveMaxBodySize(t *testing.T) {
+	t.Run("nothing configured is unlimited", func(t *testing.T) {
+		spec := &APISpec{APIDefinition: &apidef.APIDefinition{}}
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		ctxSetVersionInfo(r, &apidef.VersionInfo{})
+
+		if got := effectiveMaxBodySize(spec, nil, r); got != 0 {
+			t.Errorf("expected 0 (unlimited), got %d", got)
+		}
+	})
+
+	t.Run("picks the most restrictive of the version global limit and the hook's own limit", func(t *testing.T) {
+		spec := &APISpec{APIDefinition: &apidef.APIDefinition{}}
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		ctxSetVersionInfo(r, &apidef.VersionInfo{GlobalSizeLimit: 2048})
+
+		mw := &CoProcessMiddleware{MaxRequestBodySize: 512}
+
+		if got := effectiveMaxBodySize(spec, mw, r); got != 512 {
+			t.Errorf("expected 512, got %d", got)
+		}
+	})
+
+	t.Run("a larger hook limit doesn't widen a narrower global one", func(t *testing.T) {
+		spec := &APISpec{APIDefinition: &apidef.APIDefinition{}}
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		ctxSetVersionInfo(r, &apidef.VersionInfo{GlobalSizeLimit: 256})
+
+		mw := &CoProcessMiddleware{MaxRequestBodySize: 4096}
+
+		if got := effectiveMaxBodySize(spec, mw, r); got != 256 {
+			t.Errorf("expected 256, got %d", got)
+		}
+	})
+}
+
+func TestBuildObject_RequestTiming(t *testing.T) {
+	spec := &APISpec{APIDefinition: &apidef.APIDefinition{}}
+	mw := &CoProcessMiddleware{
+		BaseMiddleware: BaseMiddleware{Spec: spec},
+		HookType:       coprocess.HookType_Post,
+		HookName:       "timing_hook",
+	}
+	c := &CoProcessor{Middleware: mw}
+
+	receivedAt := time.Now()
+	shortDeadline := receivedAt.Add(5 * time.Millisecond)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctxSetRequestReceivedAt(r, receivedAt)
+	ctxSetRequestDeadline(r, shortDeadline)
+
+	// Let the deadline nearly, but not quite, elapse before the hook runs.
+	time.Sleep(4 * time.Millisecond)
+
+	object, err := c.BuildObject(r, nil)
+	if err != nil {
+		t.Fatalf("BuildObject returned an error: %s", err.Error())
+	}
+
+	if object.Request.ReceivedAt != receivedAt.UnixNano() {
+		t.Errorf("expected received_at %d, got %d", receivedAt.UnixNano(), object.Request.ReceivedAt)
+	}
+
+	remaining := time.Unix(0, object.Request.Deadline).Sub(time.Now())
+	if remaining <= 0 {
+		t.Errorf("expected the deadline to be close to exhaustion but still in the future, got %s remaining", remaining)
+	}
+	if remaining > 5*time.Millisecond {
+		t.Errorf("expected the deadline to be nearly exhausted, got %s remaining", remaining)
+	}
+}
+
+func TestBuildObject_ResponseContentLength(t *testing.T) {
+	spec := &APISpec{APIDefinition: &apidef.APIDefinition{}}
+	mw := &CoProcessMiddleware{
+		BaseMiddleware: BaseMiddleware{Spec: spec},
+		HookType:       coprocess.HookType_Response,
+		HookName:       "response_hook",
+	}
+	c := &CoProcessor{Middleware: mw}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	res := &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{},
+		ContentLength: 10,
+		Body:          ioutil.NopCloser(strings.NewReader("0123456789")),
+	}
+	object, err := c.BuildObject(r, res)
+	if err != nil {
+		t.Fatalf("BuildObject returned an error: %s", err.Error())
+	}
+	if object.Response.DeclaredContentLength != 10 {
+		t.Errorf("expected declared_content_length 10, got %d", object.Response.DeclaredContentLength)
+	}
+	if object.Response.ActualContentLength != 10 {
+		t.Errorf("expected actual_content_length 10, got %d", object.Response.ActualContentLength)
+	}
+	if object.Response.ContentLengthUnknown {
+		t.Error("expected content_length_unknown to be false when Content-Length was set")
+	}
+
+	// A chunked response has no declared length (Go reports it as -1), but
+	// the hook still needs to know how many bytes actually came through.
+	truncated := &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{},
+		ContentLength: -1,
+		Body:          ioutil.NopCloser(strings.NewReader("short")),
+	}
+	object, err = c.BuildObject(r, truncated)
+	if err != nil {
+		t.Fatalf("BuildObject returned an error: %s", err.Error())
+	}
+	if !object.Response.ContentLengthUnknown {
+		t.Error("expected content_length_unknown to be true for a response with no declared Content-Length")
+	}
+	if object.Response.ActualContentLength != 5 {
+		t.Errorf("expected actual_content_length 5, got %d", object.Response.ActualContentLength)
+	}
+}
+
+func TestCoProcessMiddleware_EnforceMaxRequestBodySize(t *testing.T) {
+	spec := &APISpec{APIDefinition: &apidef.APIDefinition{}}
+
+	t.Run("Content-Length over the limit is rejected without reading the body", func(t *testing.T) {
+		mw := &CoProcessMiddleware{
+			BaseMiddleware:     BaseMiddleware{Spec: spec},
+			HookType:           coprocess.HookType_Pre,
+			MaxRequestBodySize: 10,
+		}
+
+		r := httptest.NewRequest(http.MethodPost, "/", &blockingReader{})
+		r.ContentLength = 1024
+
+		err, code := mw.enforceMaxRequestBodySize(r)
+		if err == nil {
+			t.Fatal("expected an error for an oversized body")
+		}
+		if code != http.StatusRequestEntityTooLarge {
+			t.Errorf("expected status %d, got %d", http.StatusRequestEntityTooLarge, code)
+		}
+	})
+
+	t.Run("chunked body over the limit is rejected once it crosses it", func(t *testing.T) {
+		mw := &CoProcessMiddleware{
+			BaseMiddleware:     BaseMiddleware{Spec: spec},
+			HookType:           coprocess.HookType_Pre,
+			MaxRequestBodySize: 10,
+		}
+
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("this body is well over ten bytes long"))
+		r.ContentLength = -1
+
+		err, code := mw.enforceMaxRequestBodySize(r)
+		if err == nil {
+			t.Fatal("expected an error for an oversized body")
+		}
+		if code != http.StatusRequestEntityTooLarge {
+			t.Errorf("expected status %d, got %d", http.StatusRequestEntityTooLarge, code)
+		}
+	})
+
+	t.Run("body under the limit passes through untouched", func(t *testing.T) {
+		mw := &CoProcessMiddleware{
+			BaseMiddleware:     BaseMiddleware{Spec: spec},
+			HookType:           coprocess.HookType_Pre,
+			MaxRequestBodySize: 1024,
+		}
+
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("a small body"))
+		r.ContentLength = -1
+
+		err, code := mw.enforceMaxRequestBodySize(r)
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err.Error())
+		}
+		if code != http.StatusOK {
+			t.Errorf("expected status %d, got %d", http.StatusOK, code)
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("couldn't read body back: %s", err.Error())
+		}
+		if string(body) != "a small body" {
+			t.Errorf("expected the body to pass through untouched, got %q", string(body))
+		}
+	})
+}
+
+// blockingReader panics if read from, used to assert a Content-Length-based
+// rejection never touches the body at all.
+type blockingReader struct{}
+
+func (blockingReader) Read([]byte) (int, error) {
+	panic("body should not have been read")
+}
@@ -0,0 +1,105 @@
+package gateway
+
+import (
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/coprocess"
+)
+
+// dispatcherFactory builds a fresh coprocess.Dispatcher worker, used by
+// dispatcherPool both to pre-warm its pool and to replace a worker that has
+// crashed.
+type dispatcherFactory func() (coprocess.Dispatcher, error)
+
+// dispatcherPool spreads dispatch calls for a CoProcess driver (e.g. Python)
+// across a fixed number of pre-warmed worker dispatchers, so a dispatch
+// borrows a worker and returns it instead of paying per-request
+// setup/teardown cost. Borrowing blocks once every worker is checked out,
+// so load beyond the pool size queues rather than erroring. This amortizes
+// construction cost only: whether borrowed workers can actually dispatch
+// concurrently depends on the underlying driver - the Python driver, for
+// one, still serialises every call on a single process-wide interpreter
+// lock regardless of pool size (see pythonLock in coprocess_python.go).
+type dispatcherPool struct {
+	factory dispatcherFactory
+	workers chan coprocess.Dispatcher
+}
+
+// newDispatcherPool builds size workers via factory up front, so a
+// construction failure is reported immediately rather than on first use.
+func newDispatcherPool(size int, factory dispatcherFactory) (*dispatcherPool, error) {
+	if size < 1 {
+		size = 1
+	}
+
+	pool := &dispatcherPool{
+		factory: factory,
+		workers: make(chan coprocess.Dispatcher, size),
+	}
+
+	for i := 0; i < size; i++ {
+		worker, err := factory()
+		if err != nil {
+			return nil, err
+		}
+		pool.workers <- worker
+	}
+
+	return pool, nil
+}
+
+// Dispatch borrows a worker, blocking until one is free. If the borrowed
+// worker's dispatch fails, it's assumed to have crashed: it's replaced with
+// a freshly built one and the dispatch is retried once before giving up.
+func (p *dispatcherPool) Dispatch(object *coprocess.Object) (*coprocess.Object, error) {
+	worker := <-p.workers
+
+	result, err := worker.Dispatch(object)
+	if err != nil {
+		replacement, replaceErr := p.factory()
+		if replaceErr != nil {
+			p.workers <- worker
+			return nil, err
+		}
+		worker = replacement
+		result, err = worker.Dispatch(object)
+	}
+
+	p.workers <- worker
+	return result, err
+}
+
+// DispatchObject is used by the gRPC driver; Python only uses Dispatch, but
+// the pool supports either so it can back any coprocess.Dispatcher.
+func (p *dispatcherPool) DispatchObject(object *coprocess.Object) (*coprocess.Object, error) {
+	return p.Dispatch(object)
+}
+
+func (p *dispatcherPool) DispatchEvent(eventJSON []byte) {
+	worker := <-p.workers
+	worker.DispatchEvent(eventJSON)
+	p.workers <- worker
+}
+
+func (p *dispatcherPool) Reload() {
+	for i := 0; i < cap(p.workers); i++ {
+		worker := <-p.workers
+		worker.Reload()
+		p.workers <- worker
+	}
+}
+
+func (p *dispatcherPool) LoadModules() {
+	worker := <-p.workers
+	worker.LoadModules()
+	p.workers <- worker
+}
+
+// HandleMiddlewareCache loads the bundle into every worker, since a
+// subsequent Dispatch can land on any of them.
+func (p *dispatcherPool) HandleMiddlewareCache(b *apidef.BundleManifest, basePath string) {
+	for i := 0; i < cap(p.workers); i++ {
+		worker := <-p.workers
+		worker.HandleMiddlewareCache(b, basePath)
+		p.workers <- worker
+	}
+}
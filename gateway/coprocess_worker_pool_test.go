@@ -0,0 +1,138 @@
+package gateway
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/coprocess"
+)
+
+// fakeDispatcher is a coprocess.Dispatcher stand-in that simulates the
+// latency and occasional crash of a real Python worker, without needing a
+// cgo-enabled Python build to exercise dispatcherPool's pooling behaviour.
+type fakeDispatcher struct {
+	coprocess.Dispatcher
+	delay  time.Duration
+	failN  int32
+	inUse  int32
+	broken bool
+}
+
+func newFakeDispatcher(delay time.Duration) (coprocess.Dispatcher, error) {
+	return &fakeDispatcher{delay: delay}, nil
+}
+
+func (d *fakeDispatcher) Dispatch(object *coprocess.Object) (*coprocess.Object, error) {
+	if d.broken {
+		return nil, errDispatchCrashed
+	}
+	if atomic.AddInt32(&d.inUse, 1) > 1 {
+		panic("fakeDispatcher used concurrently, pool isn't serialising access")
+	}
+	defer atomic.AddInt32(&d.inUse, -1)
+	time.Sleep(d.delay)
+	return object, nil
+}
+
+func (d *fakeDispatcher) DispatchEvent([]byte)                                 {}
+func (d *fakeDispatcher) Reload()                                              {}
+func (d *fakeDispatcher) LoadModules()                                         {}
+func (d *fakeDispatcher) HandleMiddlewareCache(*apidef.BundleManifest, string) {}
+
+var errDispatchCrashed = &dispatchCrashedError{}
+
+type dispatchCrashedError struct{}
+
+func (*dispatchCrashedError) Error() string { return "worker crashed" }
+
+func TestDispatcherPool_ConcurrencyBeyondPoolSizeBlocks(t *testing.T) {
+	const poolSize = 2
+	pool, err := newDispatcherPool(poolSize, func() (coprocess.Dispatcher, error) {
+		return newFakeDispatcher(50 * time.Millisecond)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error building pool: %s", err.Error())
+	}
+
+	const callers = 6
+	var wg sync.WaitGroup
+	wg.Add(callers)
+	errs := make(chan error, callers)
+
+	start := time.Now()
+	for i := 0; i < callers; i++ {
+		go func() {
+			defer wg.Done()
+			if _, err := pool.Dispatch(&coprocess.Object{}); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("dispatch should block rather than error, got: %s", err.Error())
+	}
+
+	// callers/poolSize batches of 50ms each must have run sequentially per
+	// worker, rather than every caller erroring out immediately.
+	if elapsed := time.Since(start); elapsed < (callers/poolSize)*45*time.Millisecond {
+		t.Errorf("dispatches finished too fast (%s), pool doesn't look like it queued work", elapsed)
+	}
+}
+
+func TestDispatcherPool_ReplacesCrashedWorker(t *testing.T) {
+	built := 0
+	pool, err := newDispatcherPool(1, func() (coprocess.Dispatcher, error) {
+		built++
+		d := &fakeDispatcher{}
+		if built == 1 {
+			d.broken = true
+		}
+		return d, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error building pool: %s", err.Error())
+	}
+
+	if _, err := pool.Dispatch(&coprocess.Object{}); err != nil {
+		t.Fatalf("expected the crashed worker to be replaced and the retry to succeed, got: %s", err.Error())
+	}
+	if built != 2 {
+		t.Fatalf("expected the crashed worker to be rebuilt exactly once, built %d workers", built)
+	}
+}
+
+func BenchmarkDispatch_Pooled(b *testing.B) {
+	pool, err := newDispatcherPool(8, func() (coprocess.Dispatcher, error) {
+		return newFakeDispatcher(time.Millisecond)
+	})
+	if err != nil {
+		b.Fatalf("unexpected error building pool: %s", err.Error())
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			pool.Dispatch(&coprocess.Object{})
+		}
+	})
+}
+
+func BenchmarkDispatch_Unpooled(b *testing.B) {
+	dispatcher, _ := newFakeDispatcher(time.Millisecond)
+	var mu sync.Mutex
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			mu.Lock()
+			dispatcher.Dispatch(&coprocess.Object{})
+			mu.Unlock()
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package gateway
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/TykTechnologies/tyk/coprocess"
+)
+
+// corsMaxAgeOverride lets the API's first configured Pre hook set
+// Access-Control-Max-Age dynamically (e.g. per origin or tenant) on a CORS
+// preflight response, by wrapping the response writer so the override is
+// applied right before the CORS handler flushes its headers. It's a no-op
+// for anything other than a preflight request, and for an API with no Pre
+// hooks configured.
+//
+// This runs the Pre hook itself, rather than leaving that to the API's
+// normal Pre hook middleware further down the chain: a preflight request
+// never reaches that point, since the CORS handler (immediately after this
+// one in the chain) answers it directly and stops the chain there. So this
+// is the only place such a hook ever gets to run for a preflight request -
+// it isn't a second, duplicate dispatch.
+func corsMaxAgeOverride(spec *APISpec) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isPreflightRequest(r) {
+				if maxAge, ok := dispatchCORSMaxAgeHook(spec, r); ok {
+					w = &corsMaxAgeWriter{ResponseWriter: w, maxAge: maxAge}
+				}
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+func isPreflightRequest(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// dispatchCORSMaxAgeHook runs this API's first Pre hook purely to read back
+// ReturnOverrides.CorsMaxAge. ok is false whenever there's no Pre hook
+// configured, its driver isn't loaded, the dispatch fails, or the hook
+// leaves CorsMaxAge at its invalid/unset default - in all of those cases the
+// caller should fall back to the API's statically configured max-age.
+func dispatchCORSMaxAgeHook(spec *APISpec, r *http.Request) (int32, bool) {
+	if len(spec.CustomMiddleware.Pre) == 0 {
+		return 0, false
+	}
+
+	dispatcher := loadedDrivers[spec.CustomMiddleware.Driver]
+	if dispatcher == nil {
+		return 0, false
+	}
+
+	headers := ProtoMap(r.Header)
+	if r.Host != "" {
+		headers["Host"] = r.Host
+	}
+
+	object := &coprocess.Object{
+		HookType: coprocess.HookType_Pre,
+		HookName: spec.CustomMiddleware.Pre[0].Name,
+		Request: &coprocess.MiniRequestObject{
+			Headers:    headers,
+			Url:        r.URL.String(),
+			Method:     r.Method,
+			RequestUri: r.RequestURI,
+			ReturnOverrides: &coprocess.ReturnOverrides{
+				ResponseCode: -1,
+			},
+		},
+		Spec: map[string]string{
+			"OrgID": spec.OrgID,
+			"APIID": spec.APIID,
+		},
+	}
+
+	retObject, err := dispatcher.Dispatch(object)
+	if err != nil {
+		log.WithError(err).Warning("CORS max-age hook dispatch failed, using the configured default")
+		return 0, false
+	}
+
+	maxAge := retObject.GetRequest().GetReturnOverrides().GetCorsMaxAge()
+	if maxAge <= 0 {
+		return 0, false
+	}
+	return maxAge, true
+}
+
+// corsMaxAgeWriter overrides Access-Control-Max-Age right before headers are
+// flushed, so it wins over whatever the CORS handler set, regardless of
+// whether that handler writes the response itself (OptionsPassthrough off)
+// or hands off to the rest of the chain (OptionsPassthrough on).
+type corsMaxAgeWriter struct {
+	http.ResponseWriter
+	maxAge int32
+}
+
+func (w *corsMaxAgeWriter) WriteHeader(statusCode int) {
+	w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(w.maxAge)))
+	w.ResponseWriter.WriteHeader(statusCode)
+}
@@ -0,0 +1,66 @@
+package gateway
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// digestAlgorithms maps the algorithm tokens recognised in a Digest header
+// (RFC 3230) to the hash constructor used to verify them. Keys are matched
+// case-insensitively.
+var digestAlgorithms = map[string]func() hash.Hash{
+	"md5":     md5.New,
+	"sha-256": sha256.New,
+}
+
+// verifyDigest checks body against every algorithm named in a Digest header
+// value (e.g. "SHA-256=base64==,MD5=base64=="), so a hook can trust a
+// client-declared integrity digest without recomputing it inconsistently
+// across plugins. Returns false with a nil error if any declared digest
+// doesn't match, and an error if the header doesn't parse or names no
+// algorithm this gateway supports - the caller is expected to fail the
+// request (e.g. with a 400) in either case.
+func verifyDigest(body []byte, header string) (bool, error) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return false, fmt.Errorf("empty digest header")
+	}
+
+	matched := false
+	for _, entry := range strings.Split(header, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return false, fmt.Errorf("malformed digest entry: %q", entry)
+		}
+
+		alg := strings.ToLower(strings.TrimSpace(parts[0]))
+		newHash, ok := digestAlgorithms[alg]
+		if !ok {
+			continue
+		}
+
+		declared := strings.TrimSpace(parts[1])
+		h := newHash()
+		h.Write(body)
+		computed := base64.StdEncoding.EncodeToString(h.Sum(nil))
+		if computed != declared {
+			return false, nil
+		}
+		matched = true
+	}
+
+	if !matched {
+		return false, fmt.Errorf("no supported digest algorithm in header: %q", header)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,82 @@
+package gateway
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+)
+
+func md5Digest(body []byte) string {
+	sum := md5.Sum(body)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func sha256Digest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestVerifyDigest_SingleAlgorithm(t *testing.T) {
+	body := []byte(`{"hello":"world"}`)
+	header := "SHA-256=" + sha256Digest(body)
+
+	ok, err := verifyDigest(body, header)
+	if err != nil || !ok {
+		t.Fatalf("expected a matching SHA-256 digest to verify, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestVerifyDigest_MultipleAlgorithms(t *testing.T) {
+	body := []byte("multi-algorithm body")
+	header := "MD5=" + md5Digest(body) + ",SHA-256=" + sha256Digest(body)
+
+	ok, err := verifyDigest(body, header)
+	if err != nil || !ok {
+		t.Fatalf("expected every declared digest to verify, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestVerifyDigest_Mismatch(t *testing.T) {
+	body := []byte("actual body")
+	header := "SHA-256=" + sha256Digest([]byte("different body"))
+
+	ok, err := verifyDigest(body, header)
+	if err != nil {
+		t.Fatalf("expected a mismatch to report ok=false, not an error: %s", err.Error())
+	}
+	if ok {
+		t.Fatal("expected a mismatched digest to fail verification")
+	}
+}
+
+func TestVerifyDigest_UnsupportedAlgorithmOnly(t *testing.T) {
+	body := []byte("body")
+	_, err := verifyDigest(body, "crc32c=AAAA==")
+	if err == nil {
+		t.Fatal("expected an error when no declared algorithm is supported")
+	}
+}
+
+func TestVerifyDigest_MalformedHeader(t *testing.T) {
+	cases := []string{
+		"",
+		"sha-256",
+		"  ",
+	}
+	for _, header := range cases {
+		if _, err := verifyDigest([]byte("body"), header); err == nil {
+			t.Fatalf("expected malformed header %q to error", header)
+		}
+	}
+}
+
+func TestVerifyDigest_CaseInsensitiveAlgorithmName(t *testing.T) {
+	body := []byte("case insensitive")
+	header := "sha-256=" + sha256Digest(body)
+
+	ok, err := verifyDigest(body, header)
+	if err != nil || !ok {
+		t.Fatalf("expected a lowercase algorithm token to still match, got ok=%v err=%v", ok, err)
+	}
+}
@@ -33,6 +33,7 @@ const (
 	EventTokenCreated         apidef.TykEvent = "TokenCreated"
 	EventTokenUpdated         apidef.TykEvent = "TokenUpdated"
 	EventTokenDeleted         apidef.TykEvent = "TokenDeleted"
+	EventScopeDenied          apidef.TykEvent = "ScopeDenied"
 )
 
 // EventMetaDefault is a standard embedded struct to be used with custom event metadata types, gives an interface for
@@ -144,6 +145,9 @@ func fireEvent(name apidef.TykEvent, meta interface{}, handlers map[apidef.TykEv
 			Type:      name,
 			TimeStamp: time.Now().Local().String(),
 		}
+		if name == EventKeyExpired {
+			eventMessage = applyEventPayloadHook(eventMessage)
+		}
 		for _, handler := range handlers {
 			log.Debug("FIRING HANDLER: ", handler)
 			go handler.HandleEvent(eventMessage)
@@ -446,6 +446,40 @@ func TestQuota(t *testing.T) {
 	webhookWG.Wait()
 }
 
+// TestQuota_AlignedRenewal checks that a session whose QuotaRenews is set to
+// a fixed absolute epoch (e.g. an auth hook aligning it to a billing
+// boundary) has its quota window reset exactly at that timestamp, rather
+// than QuotaRenewalRate seconds after the bucket happens to first fill.
+func TestQuota_AlignedRenewal(t *testing.T) {
+	ts := StartTest()
+	defer ts.Close()
+
+	BuildAndLoadAPI(func(spec *APISpec) {
+		spec.UseKeylessAccess = false
+		spec.Proxy.ListenPath = "/"
+	})
+
+	renewsAt := time.Now().Add(2 * time.Second)
+	keyID := CreateSession(func(s *user.SessionState) {
+		s.QuotaMax = 1
+		s.QuotaRenewalRate = 300
+		s.QuotaRenews = renewsAt.Unix()
+	})
+
+	authHeaders := map[string]string{"authorization": keyID}
+
+	ts.Run(t, []test.TestCase{
+		{Path: "/", Headers: authHeaders, Code: 200},
+		{Path: "/", Headers: authHeaders, Code: 403, BodyMatch: `"error": "Quota exceeded"`},
+	}...)
+
+	time.Sleep(time.Until(renewsAt) + 500*time.Millisecond)
+
+	ts.Run(t, test.TestCase{
+		Path: "/", Headers: authHeaders, Code: 200,
+	})
+}
+
 func TestAnalytics(t *testing.T) {
 	ts := StartTest(TestConfig{
 		Delay: 20 * time.Millisecond,
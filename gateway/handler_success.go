@@ -3,6 +3,7 @@ package gateway
 import (
 	"bytes"
 	"encoding/base64"
+	"encoding/json"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -76,6 +77,24 @@ func tagHeaders(r *http.Request, th []string, tags []string) []string {
 	return tags
 }
 
+// removeTagsFromSet drops any tag in remove from tags, regardless of which
+// earlier stage (session, tag headers, API config) contributed it.
+func removeTagsFromSet(tags []string, remove []string) []string {
+	drop := make(map[string]bool, len(remove))
+	for _, t := range remove {
+		drop[t] = true
+	}
+
+	kept := tags[:0]
+	for _, t := range tags {
+		if !drop[t] {
+			kept = append(kept, t)
+		}
+	}
+
+	return kept
+}
+
 func addVersionHeader(w http.ResponseWriter, r *http.Request, globalConf config.Config) {
 	if ctxGetDefaultVersion(r) {
 		if vinfo := ctxGetVersionInfo(r); vinfo != nil {
@@ -105,6 +124,7 @@ func estimateTagsCapacity(session *user.SessionState, apiSpec *APISpec) int {
 	}
 
 	size += len(apiSpec.TagHeaders)
+	size += len(apiSpec.Tags)
 
 	return size
 }
@@ -163,14 +183,65 @@ func (s *SuccessHandler) RecordHit(r *http.Request, timing Latency, code int, re
 			tags = tagHeaders(r, s.Spec.TagHeaders, tags)
 		}
 
+		if len(s.Spec.Tags) > 0 {
+			tags = append(tags, s.Spec.Tags...)
+		}
+
+		// A hook's add_tags/remove_tags (see ReturnOverrides) are the final,
+		// highest-precedence layer: they can add tags of their own and strip
+		// any tag contributed above, but never touch tags they don't name.
+		addTags, removeTags := ctxGetAnalyticsTagOverrides(r)
+		if len(addTags) > 0 {
+			tags = append(tags, addTags...)
+		}
+		if len(removeTags) > 0 {
+			tags = removeTagsFromSet(tags, removeTags)
+		}
+
 		rawRequest := ""
 		rawResponse := ""
 
 		if recordDetail(r, s.Spec) {
+			// A GraphQL response/post hook may have asked for the query in
+			// this detailed record to be replaced with a normalized/hashed
+			// signature, to keep analytics storage from bloating with full
+			// query text. The swap only lives for the wire-format dump
+			// below - r.Body and r.ContentLength are restored immediately
+			// after, since the real round trip to the upstream has already
+			// happened by the time RecordHit runs, but other fields of this
+			// very function (e.g. the record's own ContentLength) still
+			// need the original values.
+			origBody, origLength := r.Body, r.ContentLength
+			if analyticsBody := ctxGetAnalyticsRequestBodyOverride(r); analyticsBody != nil {
+				// A pre hook masked the body that was actually forwarded
+				// upstream but asked for the original to be recorded
+				// instead - swap it in just for this dump.
+				r.Body = ioutil.NopCloser(bytes.NewReader(analyticsBody))
+				r.ContentLength = int64(len(analyticsBody))
+			}
+			if sig := ctxGetGraphQLAnalyticsSignature(r); sig != "" {
+				if gqlRequest := ctxGetGraphQLRequest(r); gqlRequest != nil {
+					variables := gqlRequest.Variables
+					if ctxGetGraphQLAnalyticsStripVariables(r) {
+						variables = nil
+					}
+					summary, err := json.Marshal(map[string]interface{}{
+						"operationName": gqlRequest.OperationName,
+						"query":         sig,
+						"variables":     variables,
+					})
+					if err == nil {
+						r.Body = ioutil.NopCloser(bytes.NewReader(summary))
+						r.ContentLength = int64(len(summary))
+					}
+				}
+			}
+
 			// Get the wire format representation
 			var wireFormatReq bytes.Buffer
 			r.Write(&wireFormatReq)
 			rawRequest = base64.StdEncoding.EncodeToString(wireFormatReq.Bytes())
+			r.Body, r.ContentLength = origBody, origLength
 			// responseCopy, unlike requestCopy, can be nil
 			// here - if the response was cached in
 			// mw_redis_cache, RecordHit gets passed a nil
@@ -269,6 +340,13 @@ func (s *SuccessHandler) RecordHit(r *http.Request, timing Latency, code int, re
 }
 
 func recordDetail(r *http.Request, spec *APISpec) bool {
+	// A pre/auth hook can override the static detail config for this request.
+	// This only controls whether a detailed record is attempted; size limits and
+	// PII redaction are still applied where the detailed record is built.
+	if detailed, ok := ctxGetAnalyticsDetailOverride(r); ok {
+		return detailed
+	}
+
 	if spec.EnableDetailedRecording {
 		return true
 	}
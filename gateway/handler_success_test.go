@@ -0,0 +1,55 @@
+package gateway
+
+import (
+	"testing"
+
+	"github.com/TykTechnologies/tyk/coprocess"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// TestAuthHookSessionTagsFlowIntoAnalytics verifies the path a MyAuthHook
+// uses to label a session: tags set on the proto SessionState a
+// CustomKeyCheck hook returns come back out of TykSessionState on
+// user.SessionState.Tags, and from there getSessionTags carries them into
+// the tag set recorded on the request's analytics record.
+func TestAuthHookSessionTagsFlowIntoAnalytics(t *testing.T) {
+	protoSession := &coprocess.SessionState{
+		Tags: []string{"tier:gold"},
+	}
+
+	session := TykSessionState(protoSession)
+	if len(session.Tags) != 1 || session.Tags[0] != "tier:gold" {
+		t.Fatalf("expected session.Tags to contain %q, got %v", "tier:gold", session.Tags)
+	}
+
+	tags := getSessionTags(session)
+	found := false
+	for _, tag := range tags {
+		if tag == "tier:gold" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected analytics tags to include %q, got %v", "tier:gold", tags)
+	}
+}
+
+func TestGetSessionTags(t *testing.T) {
+	session := &user.SessionState{
+		Tags:          []string{"tier:gold"},
+		ApplyPolicies: []string{"pol1"},
+	}
+
+	tags := getSessionTags(session)
+	assertTagPresent := func(tag string) {
+		for _, got := range tags {
+			if got == tag {
+				return
+			}
+		}
+		t.Errorf("expected tags %v to contain %q", tags, tag)
+	}
+
+	assertTagPresent("tier:gold")
+	assertTagPresent("pol-pol1")
+}
@@ -189,6 +189,26 @@ func gatherHealthChecks() {
 		}()
 	}
 
+	if config.Global().CoProcessOptions.HealthCheckHookName != "" {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if checkItem, ok := dispatchHealthCheckHook(); ok {
+				allInfos.mux.Lock()
+				allInfos.info["plugin"] = checkItem
+				allInfos.mux.Unlock()
+			}
+		}()
+	}
+
+	if item, ok := getCoProcessHealth(); ok {
+		allInfos.mux.Lock()
+		allInfos.info["coprocess"] = item
+		allInfos.mux.Unlock()
+	}
+
 	wg.Wait()
 
 	allInfos.mux.Lock()
@@ -232,6 +252,12 @@ func liveCheckHandler(w http.ResponseWriter, r *http.Request) {
 		status = Warn
 	}
 
+	if config.Global().CoProcessOptions.RequireCoProcessReady {
+		if item, ok := checks["coprocess"]; ok && item.Status == Fail {
+			status = Fail
+		}
+	}
+
 	res.Status = status
 
 	w.Header().Set("Content-Type", headers.ApplicationJSON)
@@ -31,6 +31,7 @@ type HostCheckerManager struct {
 	unhealthyHostList *sync.Map
 	currentHostList   map[string]HostData
 	resetsInitiated   map[string]bool
+	lastCheckTimes    *sync.Map
 }
 
 type UptimeReportData struct {
@@ -77,6 +78,7 @@ func (hc *HostCheckerManager) Init(store storage.Handler) {
 	hc.store = store
 	hc.unhealthyHostList = new(sync.Map)
 	hc.resetsInitiated = make(map[string]bool)
+	hc.lastCheckTimes = new(sync.Map)
 	// Generate a new ID for ourselves
 	hc.GenerateCheckerId()
 }
@@ -218,6 +220,10 @@ func (hc *HostCheckerManager) getHostKey(report HostHealthReport) string {
 }
 
 func (hc *HostCheckerManager) OnHostReport(ctx context.Context, report HostHealthReport) {
+	if hc.lastCheckTimes != nil {
+		hc.lastCheckTimes.Store(hc.getHostKey(report), time.Now())
+	}
+
 	if config.Global().UptimeTests.Config.EnableUptimeAnalytics {
 		go hc.RecordUptimeAnalytics(report)
 	}
@@ -307,6 +313,27 @@ func (hc *HostCheckerManager) HostDown(urlStr string) bool {
 	return ok
 }
 
+// LastChecked returns when a host was last pinged by the uptime checker, from
+// the same in-memory, non-blocking cache HostDown reads - it never triggers a
+// fresh check. ok is false if the host has never been checked, e.g. uptime
+// tests are disabled or polling hasn't run yet.
+func (hc *HostCheckerManager) LastChecked(urlStr string) (t time.Time, ok bool) {
+	if hc.lastCheckTimes == nil {
+		return time.Time{}, false
+	}
+
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	v, ok := hc.lastCheckTimes.Load(PoolerHostSentinelKeyPrefix + u.Host)
+	if !ok {
+		return time.Time{}, false
+	}
+	return v.(time.Time), true
+}
+
 func (hc *HostCheckerManager) PrepareTrackingHost(checkObject apidef.HostCheckObject, apiID string) (HostData, error) {
 	// Build the check URL:
 	var hostData HostData
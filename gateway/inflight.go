@@ -0,0 +1,20 @@
+package gateway
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// trackInFlightRequests wraps an API's request handler so spec.InFlightRequests
+// reflects how many requests are currently inside the middleware chain for
+// this API, for hook-driven adaptive load shedding (see object.Spec's
+// in_flight_requests). The decrement is deferred so it still fires if the
+// chain panics or the client cancels mid-request - the counter never leaks,
+// and reading it back (a plain atomic load) stays lock-free.
+func trackInFlightRequests(spec *APISpec, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&spec.InFlightRequests, 1)
+		defer atomic.AddInt64(&spec.InFlightRequests, -1)
+		next.ServeHTTP(w, r)
+	})
+}
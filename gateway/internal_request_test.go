@@ -0,0 +1,43 @@
+package gateway
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestMakeInternalAPIRequest checks that a post hook's internal sub-request
+// (as exposed to Python via tyk.make_request) reaches another loaded API
+// in-process and gets its response back directly, without any redirect
+// round trip through the client.
+func TestMakeInternalAPIRequest(t *testing.T) {
+	ts := StartTest()
+	defer ts.Close()
+
+	BuildAndLoadAPI(
+		func(spec *APISpec) {
+			spec.APIID = "primary"
+			spec.Proxy.ListenPath = "/primary/"
+		},
+		func(spec *APISpec) {
+			spec.APIID = "test-api-2"
+			spec.Proxy.ListenPath = "/test-api-2/"
+		},
+	)
+
+	resp, err := makeInternalAPIRequest("test-api-2", "/get", "GET", nil)
+	if err != nil {
+		t.Fatalf("makeInternalAPIRequest failed: %s", err.Error())
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200 from the internal sub-request, got %d", resp.StatusCode)
+	}
+
+	if !strings.Contains(resp.Body, `"Url":"/get"`) {
+		t.Fatalf("expected the sub-request's own upstream echo in the body, got: %s", resp.Body)
+	}
+
+	if _, err := makeInternalAPIRequest("no-such-api", "/get", "GET", nil); err == nil {
+		t.Fatal("expected an error for an API that isn't loaded")
+	}
+}
@@ -0,0 +1,285 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// jsonPatchOp is a single RFC 6902 JSON Patch operation.
+type jsonPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// applyJSONPatch applies an RFC 6902 JSON Patch document to doc, returning
+// the patched document. It operates on the generic JSON tree (maps, slices,
+// scalars) rather than a typed struct, so it works against arbitrary
+// response bodies. Any invalid path, unknown op, or failed "test" aborts
+// and returns an error describing which operation failed, so the caller can
+// act on it rather than silently patching nothing.
+func applyJSONPatch(doc, patch []byte) ([]byte, error) {
+	var root interface{}
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+
+	var ops []jsonPatchOp
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("invalid patch: %w", err)
+	}
+
+	for i, op := range ops {
+		var err error
+		root, err = applyJSONPatchOp(root, op)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	return json.Marshal(root)
+}
+
+func applyJSONPatchOp(root interface{}, op jsonPatchOp) (interface{}, error) {
+	switch op.Op {
+	case "add":
+		var value interface{}
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, fmt.Errorf("invalid value: %w", err)
+		}
+		return jsonPointerAdd(root, op.Path, value)
+	case "remove":
+		return jsonPointerRemove(root, op.Path)
+	case "replace":
+		var value interface{}
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, fmt.Errorf("invalid value: %w", err)
+		}
+		if _, err := jsonPointerGet(root, op.Path); err != nil {
+			return nil, err
+		}
+		if op.Path == "" {
+			return value, nil
+		}
+		removed, err := jsonPointerRemove(root, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		return jsonPointerAdd(removed, op.Path, value)
+	case "move":
+		value, err := jsonPointerGet(root, op.From)
+		if err != nil {
+			return nil, err
+		}
+		root, err = jsonPointerRemove(root, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return jsonPointerAdd(root, op.Path, value)
+	case "copy":
+		value, err := jsonPointerGet(root, op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err = deepCopyJSONValue(value)
+		if err != nil {
+			return nil, err
+		}
+		return jsonPointerAdd(root, op.Path, value)
+	case "test":
+		var expected interface{}
+		if err := json.Unmarshal(op.Value, &expected); err != nil {
+			return nil, fmt.Errorf("invalid value: %w", err)
+		}
+		actual, err := jsonPointerGet(root, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			return nil, fmt.Errorf("test failed: value does not match")
+		}
+		return root, nil
+	default:
+		return nil, fmt.Errorf("unsupported op %q", op.Op)
+	}
+}
+
+// deepCopyJSONValue clones a value decoded from encoding/json (maps, slices,
+// and scalars) so a "copy" op hands the destination its own tree instead of
+// aliasing the source's - round-tripping through json.Marshal/Unmarshal is
+// the simplest way to deep-copy an interface{} built only from JSON types.
+func deepCopyJSONValue(value interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("could not copy value: %w", err)
+	}
+	var copied interface{}
+	if err := json.Unmarshal(encoded, &copied); err != nil {
+		return nil, fmt.Errorf("could not copy value: %w", err)
+	}
+	return copied, nil
+}
+
+func splitJSONPointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("invalid path: must start with '/'")
+	}
+	parts := strings.Split(path[1:], "/")
+	for i, p := range parts {
+		p = strings.ReplaceAll(p, "~1", "/")
+		p = strings.ReplaceAll(p, "~0", "~")
+		parts[i] = p
+	}
+	return parts, nil
+}
+
+func jsonPointerGet(root interface{}, path string) (interface{}, error) {
+	parts, err := splitJSONPointer(path)
+	if err != nil {
+		return nil, err
+	}
+	cur := root
+	for _, p := range parts {
+		switch node := cur.(type) {
+		case map[string]interface{}:
+			v, ok := node[p]
+			if !ok {
+				return nil, fmt.Errorf("path %q not found", path)
+			}
+			cur = v
+		case []interface{}:
+			idx, err := strconv.Atoi(p)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return nil, fmt.Errorf("invalid array index %q at %q", p, path)
+			}
+			cur = node[idx]
+		default:
+			return nil, fmt.Errorf("cannot descend into scalar at %q", path)
+		}
+	}
+	return cur, nil
+}
+
+// jsonPointerAdd sets the value at path, growing arrays/objects as RFC 6902
+// requires ("-" appends; an out-of-range index elsewhere is an error).
+func jsonPointerAdd(root interface{}, path string, value interface{}) (interface{}, error) {
+	parts, err := splitJSONPointer(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return value, nil
+	}
+	return setAtPointer(root, parts, value)
+}
+
+func setAtPointer(node interface{}, parts []string, value interface{}) (interface{}, error) {
+	key := parts[0]
+	last := len(parts) == 1
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if last {
+			n[key] = value
+			return n, nil
+		}
+		child, ok := n[key]
+		if !ok {
+			return nil, fmt.Errorf("path segment %q not found", key)
+		}
+		newChild, err := setAtPointer(child, parts[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[key] = newChild
+		return n, nil
+	case []interface{}:
+		var idx int
+		if key == "-" {
+			idx = len(n)
+		} else {
+			var err error
+			idx, err = strconv.Atoi(key)
+			if err != nil || idx < 0 || idx > len(n) {
+				return nil, fmt.Errorf("invalid array index %q", key)
+			}
+		}
+		if last {
+			n = append(n, nil)
+			copy(n[idx+1:], n[idx:])
+			n[idx] = value
+			return n, nil
+		}
+		if idx >= len(n) {
+			return nil, fmt.Errorf("invalid array index %q", key)
+		}
+		newChild, err := setAtPointer(n[idx], parts[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[idx] = newChild
+		return n, nil
+	default:
+		return nil, fmt.Errorf("cannot set path segment %q on scalar", key)
+	}
+}
+
+func jsonPointerRemove(root interface{}, path string) (interface{}, error) {
+	parts, err := splitJSONPointer(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("cannot remove document root")
+	}
+	return removeAtPointer(root, parts)
+}
+
+func removeAtPointer(node interface{}, parts []string) (interface{}, error) {
+	key := parts[0]
+	last := len(parts) == 1
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if last {
+			if _, ok := n[key]; !ok {
+				return nil, fmt.Errorf("path segment %q not found", key)
+			}
+			delete(n, key)
+			return n, nil
+		}
+		child, ok := n[key]
+		if !ok {
+			return nil, fmt.Errorf("path segment %q not found", key)
+		}
+		newChild, err := removeAtPointer(child, parts[1:])
+		if err != nil {
+			return nil, err
+		}
+		n[key] = newChild
+		return n, nil
+	case []interface{}:
+		idx, err := strconv.Atoi(key)
+		if err != nil || idx < 0 || idx >= len(n) {
+			return nil, fmt.Errorf("invalid array index %q", key)
+		}
+		if last {
+			return append(n[:idx], n[idx+1:]...), nil
+		}
+		newChild, err := removeAtPointer(n[idx], parts[1:])
+		if err != nil {
+			return nil, err
+		}
+		n[idx] = newChild
+		return n, nil
+	default:
+		return nil, fmt.Errorf("cannot remove path segment %q from scalar", key)
+	}
+}
@@ -0,0 +1,148 @@
+package gateway
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func mustApplyJSONPatch(t *testing.T, doc, patch string) map[string]interface{} {
+	t.Helper()
+	out, err := applyJSONPatch([]byte(doc), []byte(patch))
+	if err != nil {
+		t.Fatalf("applyJSONPatch returned an error: %s", err.Error())
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(out, &result); err != nil {
+		t.Fatalf("patched document isn't valid JSON: %s", err.Error())
+	}
+	return result
+}
+
+func TestApplyJSONPatch_Add(t *testing.T) {
+	result := mustApplyJSONPatch(t, `{"a":1}`, `[{"op":"add","path":"/b","value":2}]`)
+	if result["b"] != float64(2) {
+		t.Fatalf("expected b to be added, got %v", result)
+	}
+}
+
+func TestApplyJSONPatch_Remove(t *testing.T) {
+	result := mustApplyJSONPatch(t, `{"a":1,"b":2}`, `[{"op":"remove","path":"/b"}]`)
+	if _, ok := result["b"]; ok {
+		t.Fatalf("expected b to be removed, got %v", result)
+	}
+}
+
+func TestApplyJSONPatch_Replace(t *testing.T) {
+	result := mustApplyJSONPatch(t, `{"a":1}`, `[{"op":"replace","path":"/a","value":"one"}]`)
+	if result["a"] != "one" {
+		t.Fatalf("expected a to be replaced, got %v", result)
+	}
+}
+
+func TestApplyJSONPatch_ReplaceMissingPathFails(t *testing.T) {
+	_, err := applyJSONPatch([]byte(`{"a":1}`), []byte(`[{"op":"replace","path":"/missing","value":1}]`))
+	if err == nil {
+		t.Fatal("expected replacing a missing path to error")
+	}
+}
+
+func TestApplyJSONPatch_Move(t *testing.T) {
+	result := mustApplyJSONPatch(t, `{"a":1}`, `[{"op":"move","from":"/a","path":"/b"}]`)
+	if _, ok := result["a"]; ok {
+		t.Fatalf("expected a to be gone after move, got %v", result)
+	}
+	if result["b"] != float64(1) {
+		t.Fatalf("expected b to hold the moved value, got %v", result)
+	}
+}
+
+func TestApplyJSONPatch_Copy(t *testing.T) {
+	result := mustApplyJSONPatch(t, `{"a":1}`, `[{"op":"copy","from":"/a","path":"/b"}]`)
+	if result["a"] != float64(1) || result["b"] != float64(1) {
+		t.Fatalf("expected both a and b to hold the value after copy, got %v", result)
+	}
+}
+
+// TestApplyJSONPatch_CopyIsIndependentOfSource guards against a copy op
+// aliasing the source's underlying map/slice: mutating the copy (via a later
+// op in the same patch) must not be visible through the original path.
+func TestApplyJSONPatch_CopyIsIndependentOfSource(t *testing.T) {
+	result := mustApplyJSONPatch(t, `{"a":{"x":1},"b":{}}`,
+		`[{"op":"copy","from":"/a","path":"/b"},{"op":"replace","path":"/b/x","value":999}]`)
+
+	a := result["a"].(map[string]interface{})
+	if a["x"] != float64(1) {
+		t.Fatalf("expected /a to be untouched by a later edit to /b, got %v", result)
+	}
+	b := result["b"].(map[string]interface{})
+	if b["x"] != float64(999) {
+		t.Fatalf("expected /b to hold the edited value, got %v", result)
+	}
+}
+
+func TestApplyJSONPatch_TestOp(t *testing.T) {
+	_, err := applyJSONPatch([]byte(`{"a":1}`), []byte(`[{"op":"test","path":"/a","value":1}]`))
+	if err != nil {
+		t.Fatalf("expected a matching test op to pass, got: %s", err.Error())
+	}
+
+	_, err = applyJSONPatch([]byte(`{"a":1}`), []byte(`[{"op":"test","path":"/a","value":2}]`))
+	if err == nil {
+		t.Fatal("expected a mismatched test op to fail the whole patch")
+	}
+}
+
+func TestApplyJSONPatch_PointerEscaping(t *testing.T) {
+	// "~1" decodes to "/" and "~0" decodes to "~", applied in that order so
+	// "~01" decodes to a literal "~1" rather than to "/".
+	doc := `{"a/b":1,"c~d":2}`
+	result := mustApplyJSONPatch(t, doc, `[{"op":"replace","path":"/a~1b","value":"slash"},{"op":"replace","path":"/c~0d","value":"tilde"}]`)
+	if result["a/b"] != "slash" {
+		t.Fatalf("expected ~1 to decode to '/', got %v", result)
+	}
+	if result["c~d"] != "tilde" {
+		t.Fatalf("expected ~0 to decode to '~', got %v", result)
+	}
+}
+
+func TestApplyJSONPatch_ArrayAppend(t *testing.T) {
+	out, err := applyJSONPatch([]byte(`{"items":[1,2]}`), []byte(`[{"op":"add","path":"/items/-","value":3}]`))
+	if err != nil {
+		t.Fatalf("applyJSONPatch returned an error: %s", err.Error())
+	}
+	var result struct {
+		Items []int `json:"items"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Items) != 3 || result.Items[2] != 3 {
+		t.Fatalf("expected '-' to append to the array, got %v", result.Items)
+	}
+}
+
+func TestApplyJSONPatch_ArrayOutOfRangeIndex(t *testing.T) {
+	_, err := applyJSONPatch([]byte(`{"items":[1,2]}`), []byte(`[{"op":"add","path":"/items/5","value":3}]`))
+	if err == nil {
+		t.Fatal("expected an out-of-range array index to error")
+	}
+
+	_, err = applyJSONPatch([]byte(`{"items":[1,2]}`), []byte(`[{"op":"remove","path":"/items/5"}]`))
+	if err == nil {
+		t.Fatal("expected removing an out-of-range array index to error")
+	}
+}
+
+func TestApplyJSONPatch_UnknownOp(t *testing.T) {
+	_, err := applyJSONPatch([]byte(`{"a":1}`), []byte(`[{"op":"bogus","path":"/a"}]`))
+	if err == nil {
+		t.Fatal("expected an unsupported op to error")
+	}
+}
+
+func TestApplyJSONPatch_InvalidPatchJSON(t *testing.T) {
+	_, err := applyJSONPatch([]byte(`{"a":1}`), []byte(`not json`))
+	if err == nil {
+		t.Fatal("expected an invalid patch document to error")
+	}
+}
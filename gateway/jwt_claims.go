@@ -0,0 +1,29 @@
+package gateway
+
+import (
+	"errors"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// decodeUnverifiedJWTClaims pulls the claims out of a JWT without checking
+// its signature, so a Pre hook can route on something like a region claim
+// for keyless APIs that only carry an informational JWT and never enable
+// JWTMiddleware. The claims returned here are UNTRUSTED: nothing has
+// verified the token was issued by who it claims to be issued by, or that
+// it hasn't been tampered with, so callers must never use them to make an
+// authorization decision - only for things like routing that are safe to
+// get wrong.
+func decodeUnverifiedJWTClaims(rawJWT string) (jwt.MapClaims, error) {
+	rawJWT = stripBearer(rawJWT)
+	if rawJWT == "" {
+		return nil, errors.New("no JWT provided")
+	}
+
+	claims := jwt.MapClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(rawJWT, claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
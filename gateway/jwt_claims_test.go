@@ -0,0 +1,29 @@
+package gateway
+
+import (
+	"testing"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+func TestDecodeUnverifiedJWTClaims(t *testing.T) {
+	jwtToken := CreateJWKToken(func(t *jwt.Token) {
+		t.Claims.(jwt.MapClaims)["region"] = "eu-west-1"
+	})
+
+	claims, err := decodeUnverifiedJWTClaims("Bearer " + jwtToken)
+	if err != nil {
+		t.Fatalf("decodeUnverifiedJWTClaims failed: %s", err.Error())
+	}
+	if claims["region"] != "eu-west-1" {
+		t.Fatalf("expected region claim to be readable, got %+v", claims)
+	}
+
+	if _, err := decodeUnverifiedJWTClaims(""); err == nil {
+		t.Fatal("expected an error for an empty token")
+	}
+
+	if _, err := decodeUnverifiedJWTClaims("not-a-jwt"); err == nil {
+		t.Fatal("expected an error for a malformed token")
+	}
+}
@@ -0,0 +1,59 @@
+package gateway
+
+import (
+	"math/rand"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+// weightedTarget picks one of targetData's configured hosts according to
+// weights, for a pre hook that wants per-request canary-style routing
+// instead of plain round robin. Entries in weights that don't name a
+// configured target, or that are zero/negative, are dropped with a logged
+// warning rather than failing the request; the remaining weights are
+// sum-normalized before picking. Returns ok=false if nothing usable is
+// left, so the caller can fall back to its normal selection.
+func weightedTarget(targetData *apidef.HostList, weights map[string]float64) (host string, ok bool) {
+	all := targetData.All()
+	valid := make(map[string]float64, len(weights))
+	var total float64
+
+	for target, weight := range weights {
+		if weight <= 0 {
+			log.Warningf("target weight for %q is not positive, dropping", target)
+			continue
+		}
+
+		configured := false
+		for _, h := range all {
+			if h == target {
+				configured = true
+				break
+			}
+		}
+		if !configured {
+			log.Warningf("target weight references unconfigured target %q, dropping", target)
+			continue
+		}
+
+		valid[target] = weight
+		total += weight
+	}
+
+	if len(valid) == 0 {
+		return "", false
+	}
+
+	pick := rand.Float64() * total
+	var cumulative float64
+	for target, weight := range valid {
+		cumulative += weight
+		if pick <= cumulative {
+			return target, true
+		}
+		host = target // last one seen, used as a fallback below
+	}
+	// Floating point rounding can leave pick just past the final
+	// cumulative boundary; fall back to whichever target we saw last.
+	return host, true
+}
@@ -45,6 +45,42 @@ var (
 	orgSessionExpiryCache singleflight.Group
 )
 
+// securityCriticalMiddleware can never be named in ReturnOverrides.SkipMiddleware
+// (see sanitizeSkipMiddlewareNames): it's every middleware that authenticates
+// the request or enforces an access grant, so a hook - malicious or buggy -
+// can't disable authentication for a request it doesn't control the outcome
+// of.
+var securityCriticalMiddleware = map[string]bool{
+	"AuthKey":                           true,
+	"BasicAuthKeyIsValid":               true,
+	"HTTPSignatureValidationMiddleware": true,
+	"JWTMiddleware":                     true,
+	"OpenIDMW":                          true,
+	"Oauth2KeyExists":                   true,
+	"CoProcessMiddleware":               true,
+	"AccessRightsCheck":                 true,
+	"GranularAccessMiddleware":          true,
+	"CertificateCheckMW":                true,
+	"ScopeCheck":                        true,
+	"KeyExpired":                        true,
+}
+
+// sanitizeSkipMiddlewareNames drops any name in securityCriticalMiddleware
+// from a hook-supplied skip list, logging a warning for each one dropped, so
+// a compromised or buggy hook can't use ReturnOverrides.SkipMiddleware to
+// disable authentication or access control for a request.
+func sanitizeSkipMiddlewareNames(names []string) map[string]bool {
+	skip := make(map[string]bool, len(names))
+	for _, name := range names {
+		if securityCriticalMiddleware[name] {
+			mainLog.Warningf("ignoring request to skip security-critical middleware %q", name)
+			continue
+		}
+		skip[name] = true
+	}
+	return skip
+}
+
 type TykMiddleware interface {
 	Init()
 	Base() *BaseMiddleware
@@ -104,6 +140,11 @@ func createMiddleware(actualMW TykMiddleware) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			mw.SetRequestLogger(r)
 
+			if ctxGetSkipMiddleware(r)[mw.Name()] {
+				h.ServeHTTP(w, r)
+				return
+			}
+
 			if config.Global().NewRelic.AppName != "" {
 				if txn, ok := w.(newrelic.Transaction); ok {
 					defer newrelic.StartSegment(txn, mw.Name()).End()
@@ -103,3 +103,13 @@ func TestBaseMiddleware_getAuthType(t *testing.T) {
 	assert.Equal(t, "t6", getToken(oauth.getAuthType(), oauth.getAuthToken))
 	assert.Equal(t, "t7", getToken(oidc.getAuthType(), oidc.getAuthToken))
 }
+
+func TestSanitizeSkipMiddlewareNames(t *testing.T) {
+	skip := sanitizeSkipMiddlewareNames([]string{"ResponseTransformMiddleware", "AuthKey", "JWTMiddleware", "SomeCacheMiddleware"})
+
+	assert.True(t, skip["ResponseTransformMiddleware"])
+	assert.True(t, skip["SomeCacheMiddleware"])
+	assert.False(t, skip["AuthKey"])
+	assert.False(t, skip["JWTMiddleware"])
+	assert.Len(t, skip, 2)
+}
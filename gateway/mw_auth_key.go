@@ -161,6 +161,7 @@ func stripBearer(token string) string {
 }
 
 func AuthFailed(m TykMiddleware, r *http.Request, token string) {
+	ctxSetTerminationReason(r, TerminationAuthFailed)
 	m.Base().FireEvent(EventAuthFailure, EventKeyFailureMeta{
 		EventMetaDefault: EventMetaDefault{Message: "Auth Failure", OriginatingRequest: EncodeRequestToEvent(r)},
 		Path:             r.URL.Path,
@@ -0,0 +1,55 @@
+package gateway
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// ContentTypeAllowListMiddleware enforces a Content-Type allow-list set by a
+// Pre hook for this specific request (see ReturnOverrides.AllowedContentTypes),
+// rather than a static per-API setting. It runs immediately after the Pre
+// hooks, ahead of any middleware that would parse the body, so a rejected
+// request never pays for that work.
+type ContentTypeAllowListMiddleware struct {
+	BaseMiddleware
+}
+
+func (m *ContentTypeAllowListMiddleware) Name() string {
+	return "ContentTypeAllowListMiddleware"
+}
+
+func (m *ContentTypeAllowListMiddleware) EnabledForSpec() bool {
+	// Only a Pre hook can ever populate the allow-list, so there's nothing to
+	// check for an API that doesn't have one.
+	return len(m.Spec.CustomMiddleware.Pre) > 0
+}
+
+func (m *ContentTypeAllowListMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	allowed := ctxGetAllowedContentTypes(r)
+	if len(allowed) == 0 {
+		return nil, http.StatusOK
+	}
+
+	mediaType := requestMediaType(r)
+	for _, a := range allowed {
+		if strings.EqualFold(mediaType, strings.TrimSpace(a)) {
+			return nil, http.StatusOK
+		}
+	}
+
+	return fmt.Errorf("unsupported content type %q, allowed: %s", mediaType, strings.Join(allowed, ", ")), http.StatusUnsupportedMediaType
+}
+
+// requestMediaType returns the request's Content-Type with any parameters
+// (e.g. charset) stripped, so "application/json; charset=utf-8" matches an
+// allow-list entry of "application/json".
+func requestMediaType(r *http.Request) string {
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return mediaType
+}
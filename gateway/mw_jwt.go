@@ -443,6 +443,8 @@ func (k *JWTMiddleware) processCentralisedJWT(r *http.Request, token *jwt.Token)
 		}
 
 		if scope := getScopeFromClaim(claims, scopeClaimName); scope != nil {
+			ctxSetPresentedScopes(r, scope)
+
 			polIDs := []string{
 				basePolicyID, // add base policy as a first one
 			}
@@ -0,0 +1,31 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+)
+
+// PresignedURLCheck enforces pre-signed URL verification for APIs that opt
+// in via URLSigning.Enabled, rejecting missing, invalid, or expired
+// signatures with 403. It runs after the Pre hooks and the version, IP and
+// certificate checks, and before authentication and the rest of the chain.
+type PresignedURLCheck struct {
+	BaseMiddleware
+}
+
+func (k *PresignedURLCheck) Name() string {
+	return "PresignedURLCheck"
+}
+
+func (k *PresignedURLCheck) EnabledForSpec() bool {
+	return k.Spec.URLSigning.Enabled
+}
+
+func (k *PresignedURLCheck) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	if err := verifyPresignedURL(r.URL, k.Spec.URLSigning.KeyRef); err != nil {
+		k.Logger().WithError(err).Info("presigned URL verification failed")
+		return errors.New("access to this resource has expired or is invalid"), http.StatusForbidden
+	}
+
+	return nil, http.StatusOK
+}
@@ -1,17 +1,26 @@
 package gateway
 
 import (
+	"bytes"
 	"errors"
 	"net/http"
 	"time"
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/TykTechnologies/tyk/headers"
 	"github.com/TykTechnologies/tyk/request"
 
 	gql "github.com/jensneuse/graphql-go-tools/pkg/graphql"
 )
 
+// rateLimitTemplateName is the template a Pre hook's
+// ReturnOverrides.RateLimitTemplateVars can customise (see
+// writeRateLimitTemplate). Named separately from handler_error.go's
+// "error_<code>" convention since it's scoped to rate-limit rejections only,
+// not every 429 the gateway might ever return.
+const rateLimitTemplateName = "error_ratelimit.json"
+
 var sessionLimiter = SessionLimiter{}
 var sessionMonitor = Monitor{}
 
@@ -29,7 +38,7 @@ func (k *RateLimitAndQuotaCheck) EnabledForSpec() bool {
 	return !k.Spec.DisableRateLimit || !k.Spec.DisableQuota
 }
 
-func (k *RateLimitAndQuotaCheck) handleRateLimitFailure(r *http.Request, token string) (error, int) {
+func (k *RateLimitAndQuotaCheck) handleRateLimitFailure(w http.ResponseWriter, r *http.Request, token string, rate, per float64) (error, int) {
 	k.Logger().WithField("key", obfuscateKey(token)).Info("Key rate limit exceeded.")
 
 	// Fire a rate limit exceeded event
@@ -43,9 +52,50 @@ func (k *RateLimitAndQuotaCheck) handleRateLimitFailure(r *http.Request, token s
 	// Report in health check
 	reportHealthValue(k.Spec, Throttle, "-1")
 
+	ctxSetTerminationReason(r, TerminationRateLimited)
+
+	if k.writeRateLimitTemplate(w, r, rate, per) {
+		return errCustomBodyResponse, http.StatusTooManyRequests
+	}
+
 	return errors.New("Rate limit exceeded"), http.StatusTooManyRequests
 }
 
+// writeRateLimitTemplate renders the optional error_ratelimit template (see
+// rateLimitTemplateName) with this request's rate-limit details and writes it
+// directly to w, returning true. The gateway fills in "limit" (requests
+// allowed per "per" seconds) and "retry_after" (seconds until the window
+// resets - approximated as "per", since individual request timestamps within
+// the window aren't tracked here); a Pre hook can add further values via
+// ReturnOverrides.RateLimitTemplateVars (see ctxGetRateLimitTemplateVars).
+// Returns false without writing anything if no such template is registered,
+// or if it fails to render, so the caller falls back to the default body.
+func (k *RateLimitAndQuotaCheck) writeRateLimitTemplate(w http.ResponseWriter, r *http.Request, rate, per float64) bool {
+	tmpl := templates.Lookup(rateLimitTemplateName)
+	if tmpl == nil {
+		return false
+	}
+
+	vars := map[string]interface{}{
+		"limit":       rate,
+		"retry_after": per,
+	}
+	for key, value := range ctxGetRateLimitTemplateVars(r) {
+		vars[key] = value
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		k.Logger().WithError(err).Error("Couldn't render rate limit template, falling back to default body")
+		return false
+	}
+
+	w.Header().Set(headers.ContentType, headers.ApplicationJSON)
+	w.WriteHeader(http.StatusTooManyRequests)
+	w.Write(buf.Bytes())
+	return true
+}
+
 func (k *RateLimitAndQuotaCheck) handleQuotaFailure(r *http.Request, token string) (error, int) {
 	k.Logger().WithField("key", obfuscateKey(token)).Info("Key quota limit exceeded.")
 
@@ -92,7 +142,7 @@ func (k *RateLimitAndQuotaCheck) ProcessRequest(w http.ResponseWriter, r *http.R
 		}
 	}
 
-	storeRef := GlobalSessionManager.Store()
+	storeRef := rateLimitStoreFor(session, GlobalSessionManager.Store())
 	reason := sessionLimiter.ForwardMessage(
 		r,
 		session,
@@ -107,12 +157,16 @@ func (k *RateLimitAndQuotaCheck) ProcessRequest(w http.ResponseWriter, r *http.R
 
 	throttleRetryLimit := session.ThrottleRetryLimit
 	throttleInterval := session.ThrottleInterval
+	rate := session.Rate
+	per := session.Per
 
 	if len(session.AccessRights) > 0 {
 		if rights, ok := session.AccessRights[k.Spec.APIID]; ok {
 			if rights.Limit != nil {
 				throttleInterval = rights.Limit.ThrottleInterval
 				throttleRetryLimit = rights.Limit.ThrottleRetryLimit
+				rate = rights.Limit.Rate
+				per = rights.Limit.Per
 			}
 		}
 	}
@@ -120,7 +174,7 @@ func (k *RateLimitAndQuotaCheck) ProcessRequest(w http.ResponseWriter, r *http.R
 	switch reason {
 	case sessionFailNone:
 	case sessionFailRateLimit:
-		err, errCode := k.handleRateLimitFailure(r, token)
+		err, errCode := k.handleRateLimitFailure(w, r, token, rate, per)
 		if throttleRetryLimit > 0 {
 			for {
 				ctxIncThrottleLevel(r, throttleRetryLimit)
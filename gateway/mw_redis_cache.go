@@ -29,6 +29,39 @@ const (
 	upstreamCacheTTLHeader = "x-tyk-cache-action-set-ttl"
 )
 
+// cacheBackendMemory and cacheBackendRedis are the backend names a hook can
+// request via return_overrides.cache_backend (see coprocess.go). memory
+// keeps entries in this node's own process, never shared with the rest of
+// the cluster; redis uses the gateway's existing shared cache pool. The
+// override is only ever set by a response hook, since picking a backend by
+// response size - the documented use case - needs the response, which
+// isn't available yet when this middleware does its initial cache lookup.
+const (
+	cacheBackendMemory = "memory"
+	cacheBackendRedis  = "redis"
+)
+
+// memoryCacheStore backs per-request opt-in to node-local caching. It is a
+// single shared instance, keyed the same way as the Redis cache store, so
+// entries from different APIs don't collide.
+var memoryCacheStore = &storage.MemoryCache{KeyPrefix: "cache-memory-"}
+
+// cacheStoreFor resolves the backend override set on the request context, if
+// any, against the API's default store. Called twice per request: once
+// before the upstream call, when there's nothing to override yet, and again
+// right before the result is written, once a response hook has had a chance
+// to set one based on the actual response.
+func cacheStoreFor(defaultStore storage.Handler, r *http.Request) storage.Handler {
+	switch ctxGetCacheBackendOverride(r) {
+	case cacheBackendMemory:
+		return memoryCacheStore
+	case cacheBackendRedis:
+		return defaultStore
+	default:
+		return defaultStore
+	}
+}
+
 // RedisCacheMiddleware is a caching middleware that will pull data from Redis instead of the upstream proxy
 type RedisCacheMiddleware struct {
 	BaseMiddleware
@@ -195,6 +228,13 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 	if stat != StatusCached {
 		return nil, http.StatusOK
 	}
+
+	// No response hook has run yet at this point, so there's no override to
+	// read for the lookup - a cache miss falls through to the proxy call
+	// below, and cacheStoreFor is consulted again afterwards to pick where
+	// the result actually gets written.
+	cacheStore := cacheStoreFor(m.CacheStore, r)
+
 	token := ctxGetAuthToken(r)
 
 	// No authentication data? use the IP.
@@ -210,7 +250,7 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 		errCreatingChecksum = true
 	} else {
 		v, sfErr, _ := m.singleFlight.Do(key, func() (interface{}, error) {
-			return m.CacheStore.GetKey(key)
+			return cacheStore.GetKey(key)
 		})
 		retBlob = v.(string)
 		err = sfErr
@@ -303,7 +343,11 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 			log.Debug("Cache TTL is:", cacheTTL)
 			ts := m.getTimeTTL(cacheTTL)
 			toStore := m.encodePayload(wireFormatReq.String(), ts)
-			go m.CacheStore.SetKey(key, toStore, cacheTTL)
+			// Re-resolve the backend: the proxy call above may have run a
+			// response hook that set an override now that the response (and
+			// its size) is actually known.
+			writeStore := cacheStoreFor(m.CacheStore, r)
+			go writeStore.SetKey(key, toStore, cacheTTL)
 		}
 
 		return nil, mwStatusRespond
@@ -312,12 +356,12 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 	cachedData, timestamp, err := m.decodePayload(retBlob)
 	if err != nil {
 		// Tere was an issue with this cache entry - lets remove it:
-		m.CacheStore.DeleteKey(key)
+		cacheStore.DeleteKey(key)
 		return nil, http.StatusOK
 	}
 
 	if m.isTimeStampExpired(timestamp) || len(cachedData) == 0 {
-		m.CacheStore.DeleteKey(key)
+		cacheStore.DeleteKey(key)
 		return nil, http.StatusOK
 	}
 
@@ -372,10 +416,20 @@ func isSafeMethod(method string) bool {
 	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
 }
 
+// getCacheKeyFromHeaders folds the API's statically configured
+// CacheOptions.CacheByHeaders together with any headers a pre hook added via
+// ReturnOverrides.CacheVaryHeaders into the cache key material. Since the
+// same hook runs, and so contributes the same vary headers, on both the
+// request that misses and writes an entry and the later request that hits
+// it, the two stay consistent for a given resource as long as the hook's own
+// logic for choosing vary headers is itself deterministic for that resource.
 func (m *RedisCacheMiddleware) getCacheKeyFromHeaders(r *http.Request) (key string) {
 	key = ""
 	for _, header := range m.Spec.CacheOptions.CacheByHeaders {
 		key += header + "-" + r.Header.Get(header)
 	}
+	for _, header := range ctxGetCacheVaryHeaders(r) {
+		key += header + "-" + r.Header.Get(header)
+	}
 	return
 }
@@ -0,0 +1,35 @@
+package gateway
+
+import (
+	"net/http"
+	"time"
+)
+
+// RequestTimingMiddleware stamps every request with when the gateway started
+// processing it, and - when the API has a hard timeout configured for the
+// matched endpoint, or a default timeout otherwise - the point by which it's
+// expected to finish. Hooks read these back off request.object (see
+// BuildObject) to make budget-aware decisions, e.g. a post hook skipping
+// expensive enrichment on a request that's already blown its deadline.
+type RequestTimingMiddleware struct {
+	BaseMiddleware
+}
+
+func (m *RequestTimingMiddleware) Name() string {
+	return "RequestTimingMiddleware"
+}
+
+func (m *RequestTimingMiddleware) EnabledForSpec() bool {
+	return true
+}
+
+func (m *RequestTimingMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	receivedAt := time.Now()
+	ctxSetRequestReceivedAt(r, receivedAt)
+
+	if _, timeout := hardTimeoutFor(m.Spec, r); timeout > 0 {
+		ctxSetRequestDeadline(r, receivedAt.Add(time.Duration(timeout*float64(time.Second))))
+	}
+
+	return nil, http.StatusOK
+}
@@ -0,0 +1,115 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/request"
+)
+
+// ScopeCheck enforces any per-endpoint OAuth/OIDC scope requirements
+// configured via ExtendedPaths.RequiredScopes against the scopes presented
+// by the validated token, after auth has already run.
+//
+// Presented scopes are only ever populated by processCentralisedJWT, when
+// EnableJWT, JWTSource and JWTScopeToPolicyMapping are all configured and
+// the token carries a scope claim (see ctxSetPresentedScopes in mw_jwt.go).
+// No other auth mechanism - OAuth2/OSIN bearer tokens, API keys, HMAC,
+// basic auth, or one-to-one-mapped JWT - populates it, so EnabledForSpec
+// requires that same combination; otherwise every request to an endpoint
+// with RequiredScopes configured would see an empty presented list and be
+// rejected outright regardless of the token it carried.
+type ScopeCheck struct {
+	BaseMiddleware
+}
+
+func (k *ScopeCheck) Name() string {
+	return "ScopeCheck"
+}
+
+func (k *ScopeCheck) EnabledForSpec() bool {
+	if !k.populatesPresentedScopes() {
+		return false
+	}
+	for _, v := range k.Spec.VersionData.Versions {
+		if len(v.ExtendedPaths.RequiredScopes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// populatesPresentedScopes reports whether this API's auth configuration is
+// the one path (centralised JWT with a scope-to-policy mapping) that ever
+// calls ctxSetPresentedScopes. See the ScopeCheck doc comment.
+func (k *ScopeCheck) populatesPresentedScopes() bool {
+	return k.Spec.EnableJWT && k.Spec.JWTSource != "" && len(k.Spec.JWTScopeToPolicyMapping) > 0
+}
+
+func (k *ScopeCheck) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	_, versionPaths, _, _ := k.Spec.Version(r)
+	found, meta := k.Spec.CheckSpecMatchesStatus(r, versionPaths, RequiredScope)
+	if !found {
+		return nil, http.StatusOK
+	}
+
+	scopeMeta := meta.(*apidef.ScopeMeta)
+	presented := ctxGetPresentedScopes(r)
+
+	allowed, err := k.validate(scopeMeta.Scopes, presented)
+	if err != nil {
+		k.Logger().WithError(err).Error("scope validation failed")
+		return errors.New("there was a problem proxying the request"), http.StatusInternalServerError
+	}
+
+	k.audit(r, scopeMeta.Scopes, presented, allowed)
+
+	if !allowed {
+		return errors.New("access to this resource requires additional OAuth scope"), http.StatusForbidden
+	}
+
+	return nil, http.StatusOK
+}
+
+// validate defers to the API's configured ScopeValidatorHook if one was
+// loaded, otherwise falls back to requiring presented to be a superset of
+// required.
+func (k *ScopeCheck) validate(required, presented []string) (bool, error) {
+	if k.Spec.ScopeValidator != nil {
+		return k.Spec.ScopeValidator(required, presented)
+	}
+
+	have := make(map[string]bool, len(presented))
+	for _, s := range presented {
+		have[s] = true
+	}
+	for _, s := range required {
+		if !have[s] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (k *ScopeCheck) audit(r *http.Request, required, presented []string, allowed bool) {
+	decision := "denied"
+	if allowed {
+		decision = "allowed"
+	}
+	k.Logger().WithFields(map[string]interface{}{
+		"required":  required,
+		"presented": presented,
+		"decision":  decision,
+		"path":      r.URL.Path,
+		"origin":    request.RealIP(r),
+	}).Info("scope validation decision")
+
+	if !allowed {
+		k.FireEvent(EventScopeDenied, EventKeyFailureMeta{
+			EventMetaDefault: EventMetaDefault{Message: "Required OAuth scope not presented", OriginatingRequest: EncodeRequestToEvent(r)},
+			Path:             r.URL.Path,
+			Origin:           request.RealIP(r),
+		})
+	}
+}
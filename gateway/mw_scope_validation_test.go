@@ -0,0 +1,78 @@
+package gateway
+
+import (
+	"testing"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+func newScopeCheckSpec(enableJWT bool, jwtSource string, scopeMapping map[string]string) *ScopeCheck {
+	def := &apidef.APIDefinition{
+		EnableJWT:               enableJWT,
+		JWTSource:               jwtSource,
+		JWTScopeToPolicyMapping: scopeMapping,
+	}
+	def.VersionData.Versions = map[string]apidef.VersionInfo{
+		"v1": {
+			ExtendedPaths: apidef.ExtendedPathsSet{
+				RequiredScopes: []apidef.ScopeMeta{
+					{Path: "/resource", Method: "GET", Scopes: []string{"read"}},
+				},
+			},
+		},
+	}
+	return &ScopeCheck{BaseMiddleware{Spec: &APISpec{APIDefinition: def}, Proxy: nil}}
+}
+
+// TestScopeCheck_EnabledForSpec checks that ScopeCheck only turns itself on
+// for the one auth path that ever populates presented scopes (centralised
+// JWT with a scope-to-policy mapping) - any other auth mechanism leaves
+// ctxGetPresentedScopes empty, and turning the check on regardless would
+// 403 every request to an endpoint with RequiredScopes configured.
+func TestScopeCheck_EnabledForSpec(t *testing.T) {
+	tests := []struct {
+		name         string
+		enableJWT    bool
+		jwtSource    string
+		scopeMapping map[string]string
+		want         bool
+	}{
+		{"centralised JWT with scope mapping", true, "https://example.com/jwk.json", map[string]string{"read": "pol1"}, true},
+		{"JWT enabled but one-to-one mapping (no JWTSource)", true, "", map[string]string{"read": "pol1"}, false},
+		{"centralised JWT without a scope mapping", true, "https://example.com/jwk.json", nil, false},
+		{"JWT not enabled at all", false, "", nil, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			k := newScopeCheckSpec(tc.enableJWT, tc.jwtSource, tc.scopeMapping)
+			if got := k.EnabledForSpec(); got != tc.want {
+				t.Fatalf("EnabledForSpec() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestScopeCheck_Validate checks the default superset rule and that a
+// configured Spec.ScopeValidator overrides it.
+func TestScopeCheck_Validate(t *testing.T) {
+	k := &ScopeCheck{BaseMiddleware{Spec: &APISpec{APIDefinition: &apidef.APIDefinition{}}}}
+
+	allowed, err := k.validate([]string{"read"}, []string{"read", "write"})
+	if err != nil || !allowed {
+		t.Fatalf("expected presented superset of required to be allowed, got allowed=%v err=%v", allowed, err)
+	}
+
+	allowed, err = k.validate([]string{"read", "write"}, []string{"read"})
+	if err != nil || allowed {
+		t.Fatalf("expected a missing required scope to be denied, got allowed=%v err=%v", allowed, err)
+	}
+
+	k.Spec.ScopeValidator = func(required, presented []string) (bool, error) {
+		return true, nil
+	}
+	allowed, err = k.validate([]string{"read", "write"}, nil)
+	if err != nil || !allowed {
+		t.Fatalf("expected the configured ScopeValidator to override the default check, got allowed=%v err=%v", allowed, err)
+	}
+}
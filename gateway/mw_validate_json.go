@@ -1,6 +1,7 @@
 package gateway
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -48,12 +49,16 @@ func (k *ValidateJSON) ProcessRequest(w http.ResponseWriter, r *http.Request, _
 		}
 	}
 
-	// Load input body into gojsonschema
+	// Load input body into gojsonschema, then put it back so downstream
+	// middleware (coprocess hooks, transforms, the upstream request itself)
+	// still see the full body - this runs ahead of them in the chain
+	// specifically so they never see a request that fails validation.
 	bodyBytes, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		return err, http.StatusBadRequest
 	}
-	defer r.Body.Close()
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(bodyBytes))
 	inputLoader := gojsonschema.NewBytesLoader(bodyBytes)
 
 	// Perform validation
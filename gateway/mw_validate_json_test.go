@@ -64,6 +64,72 @@ func TestValidateJSONSchema(t *testing.T) {
 	}...)
 }
 
+// TestValidateJSONSchema_BodyPreserved checks that a request which passes
+// validation still has its original body by the time it reaches the
+// upstream - a regression check for ValidateJSON now running ahead of the
+// rest of the chain instead of right before TransformMiddleware.
+func TestValidateJSONSchema_BodyPreserved(t *testing.T) {
+	ts := StartTest()
+	defer ts.Close()
+
+	testPrepareValidateJSONSchema()
+
+	ts.Run(t, test.TestCase{
+		Method:    "POST",
+		Path:      "/v",
+		Data:      `{"firstName": "Harry", "lastName": "Potter"}`,
+		Code:      http.StatusOK,
+		BodyMatch: `"Body":"{\\"firstName\\": \\"Harry\\", \\"lastName\\": \\"Potter\\"}"`,
+	})
+}
+
+var testJsonSchemaWithRef = `{
+    "title": "Person",
+    "type": "object",
+    "definitions": {
+        "name": {
+            "type": "string",
+            "minLength": 1
+        }
+    },
+    "properties": {
+        "firstName": {"$ref": "#/definitions/name"},
+        "lastName": {"$ref": "#/definitions/name"}
+    },
+    "required": ["firstName", "lastName"]
+}`
+
+func testPrepareValidateJSONSchemaWithRef() {
+	BuildAndLoadAPI(func(spec *APISpec) {
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			json.Unmarshal([]byte(`[
+				{
+					"path": "/v",
+					"method": "POST",
+					"schema": `+testJsonSchemaWithRef+`
+				}
+			]`), &v.ExtendedPaths.ValidateJSON)
+		})
+
+		spec.Proxy.ListenPath = "/"
+	})
+}
+
+// TestValidateJSONSchema_Ref checks that a schema using a local $ref into its
+// own "definitions" is resolved correctly, both for a passing and a failing
+// document.
+func TestValidateJSONSchema_Ref(t *testing.T) {
+	ts := StartTest()
+	defer ts.Close()
+
+	testPrepareValidateJSONSchemaWithRef()
+
+	ts.Run(t, []test.TestCase{
+		{Method: "POST", Path: "/v", Data: `{"firstName": "Harry", "lastName": "Potter"}`, Code: http.StatusOK},
+		{Method: "POST", Path: "/v", Data: `{"firstName": "", "lastName": "Potter"}`, Code: http.StatusUnprocessableEntity, BodyMatch: `firstName: String length must be greater than or equal to 1`},
+	}...)
+}
+
 func BenchmarkValidateJSONSchema(b *testing.B) {
 	b.ReportAllocs()
 
@@ -14,6 +14,7 @@ import (
 
 	"github.com/lonelycode/osin"
 	uuid "github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
 
 	"strconv"
@@ -133,6 +134,10 @@ func (o *OAuthHandlers) notifyClientOfNewOauth(notification NewOAuthNotification
 
 // HandleGenerateAuthCodeData handles a resource provider approving an OAuth request from a client
 func (o *OAuthHandlers) HandleGenerateAuthCodeData(w http.ResponseWriter, r *http.Request) {
+	if o.Manager.runAuthorizeHook(w, r) {
+		return
+	}
+
 	// On AUTH grab session state data and add to UserData (not validated, not good!)
 	sessionJSONData := r.FormValue("key_rules")
 	if sessionJSONData == "" {
@@ -228,8 +233,8 @@ const (
 	refreshToken = "refresh_token"
 )
 
-//in compliance with https://tools.ietf.org/html/rfc7009#section-2.1
-//ToDo: set an authentication mechanism
+// in compliance with https://tools.ietf.org/html/rfc7009#section-2.1
+// ToDo: set an authentication mechanism
 func (o *OAuthHandlers) HandleRevokeToken(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
@@ -330,8 +335,45 @@ func RevokeAllTokens(storage ExtendedOsinStorageInterface, clientId, clientSecre
 
 // OAuthManager handles and wraps osin OAuth2 functions to handle authorise and access requests
 type OAuthManager struct {
-	API        *APISpec
-	OsinServer *TykOsinServer
+	API           *APISpec
+	OsinServer    *TykOsinServer
+	authorizeHook http.HandlerFunc
+}
+
+// runAuthorizeHook runs the API's configured Oauth2Meta.AuthorizeHook, if
+// any, against the incoming authorize request. The hook is a Go-plugin
+// handler, the same extension point GoPluginMiddleware uses elsewhere: it
+// can rewrite r.Form in place to modify the request (e.g. inject or correct
+// a param) or reject it outright by writing a response of its own, which
+// runAuthorizeHook detects the same way GoPluginMiddleware does, via
+// customResponseWriter. It never weakens config.Global().OauthRequirePKCE -
+// that's checked separately, after the hook has had its say either way.
+// Every rejection, and every PKCE enforcement, is logged to the "security"
+// log channel so these decisions are auditable.
+func (o *OAuthManager) runAuthorizeHook(w http.ResponseWriter, r *http.Request) (rejected bool) {
+	r.ParseForm()
+
+	securityLog := log.WithFields(logrus.Fields{
+		"prefix": "security",
+		"api_id": o.API.APIID,
+	})
+
+	if o.authorizeHook != nil {
+		rw := &customResponseWriter{ResponseWriter: w}
+		o.authorizeHook(rw, r)
+		if rw.responseSent {
+			securityLog.Warning("Authorize request rejected by authorize hook")
+			return true
+		}
+	}
+
+	if config.Global().OauthRequirePKCE && r.FormValue("code_challenge") == "" {
+		securityLog.Warning("Authorize request rejected: code_challenge is required by OauthRequirePKCE")
+		doJSONWrite(w, http.StatusBadRequest, apiError("code_challenge is required"))
+		return true
+	}
+
+	return false
 }
 
 // HandleAuthorisation creates the authorisation data for the request
@@ -67,22 +67,16 @@ func buildTestOAuthSpec(apiGens ...func(spec *APISpec)) *APISpec {
 		}
 		spec.UseKeylessAccess = false
 		spec.UseOauth2 = true
-		spec.Oauth2Meta = struct {
-			AllowedAccessTypes     []osin.AccessRequestType    `bson:"allowed_access_types" json:"allowed_access_types"`
-			AllowedAuthorizeTypes  []osin.AuthorizeRequestType `bson:"allowed_authorize_types" json:"allowed_authorize_types"`
-			AuthorizeLoginRedirect string                      `bson:"auth_login_redirect" json:"auth_login_redirect"`
-		}{
-			AllowedAccessTypes: []osin.AccessRequestType{
-				"authorization_code",
-				"refresh_token",
-				"client_credentials",
-			},
-			AllowedAuthorizeTypes: []osin.AuthorizeRequestType{
-				"code",
-				"token",
-			},
-			AuthorizeLoginRedirect: testHttpPost,
+		spec.Oauth2Meta.AllowedAccessTypes = []osin.AccessRequestType{
+			"authorization_code",
+			"refresh_token",
+			"client_credentials",
+		}
+		spec.Oauth2Meta.AllowedAuthorizeTypes = []osin.AuthorizeRequestType{
+			"code",
+			"token",
 		}
+		spec.Oauth2Meta.AuthorizeLoginRedirect = testHttpPost
 		spec.NotificationsDetails = apidef.NotificationsManager{
 			SharedSecret:      "9878767657654343123434556564444",
 			OAuthKeyChangeURL: testHttpPost,
@@ -0,0 +1,106 @@
+package gateway
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/TykTechnologies/tyk/config"
+)
+
+func withPresignSecret(t *testing.T, keyRef, secret string) {
+	t.Helper()
+	globalConf := config.Global()
+	if globalConf.Secrets == nil {
+		globalConf.Secrets = map[string]string{}
+	}
+	globalConf.Secrets[keyRef] = secret
+	config.SetGlobal(globalConf)
+	t.Cleanup(ResetTestConfig)
+}
+
+func TestPresignURL_RoundTrip(t *testing.T) {
+	withPresignSecret(t, "presign-key", "topsecret")
+
+	signed, err := presignURL("https://example.org/download/file.zip", time.Hour, "presign-key")
+	if err != nil {
+		t.Fatalf("presignURL returned an error: %s", err.Error())
+	}
+
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := verifyPresignedURL(u, "presign-key"); err != nil {
+		t.Fatalf("expected a freshly signed URL to verify, got: %s", err.Error())
+	}
+}
+
+func TestVerifyPresignedURL_Expired(t *testing.T) {
+	withPresignSecret(t, "presign-key", "topsecret")
+
+	signed, err := presignURL("https://example.org/download/file.zip", -time.Hour, "presign-key")
+	if err != nil {
+		t.Fatalf("presignURL returned an error: %s", err.Error())
+	}
+	u, _ := url.Parse(signed)
+
+	if err := verifyPresignedURL(u, "presign-key"); err != errPresignExpired {
+		t.Fatalf("expected errPresignExpired, got %v", err)
+	}
+}
+
+func TestVerifyPresignedURL_Tampered(t *testing.T) {
+	withPresignSecret(t, "presign-key", "topsecret")
+
+	signed, err := presignURL("https://example.org/download/file.zip", time.Hour, "presign-key")
+	if err != nil {
+		t.Fatalf("presignURL returned an error: %s", err.Error())
+	}
+	u, _ := url.Parse(signed)
+	u.Path = "/download/other-file.zip"
+
+	if err := verifyPresignedURL(u, "presign-key"); err != errPresignInvalid {
+		t.Fatalf("expected errPresignInvalid for a tampered path, got %v", err)
+	}
+}
+
+func TestVerifyPresignedURL_QueryTampered(t *testing.T) {
+	withPresignSecret(t, "presign-key", "topsecret")
+
+	signed, err := presignURL("https://example.org/download/file.zip?user=alice", time.Hour, "presign-key")
+	if err != nil {
+		t.Fatalf("presignURL returned an error: %s", err.Error())
+	}
+	u, _ := url.Parse(signed)
+
+	q := u.Query()
+	q.Set("user", "bob")
+	u.RawQuery = q.Encode()
+
+	if err := verifyPresignedURL(u, "presign-key"); err != errPresignInvalid {
+		t.Fatalf("expected errPresignInvalid for a tampered query parameter, got %v", err)
+	}
+}
+
+func TestVerifyPresignedURL_MissingParams(t *testing.T) {
+	withPresignSecret(t, "presign-key", "topsecret")
+
+	u, _ := url.Parse("https://example.org/download/file.zip")
+	if err := verifyPresignedURL(u, "presign-key"); err != errPresignMissing {
+		t.Fatalf("expected errPresignMissing for a plain URL, got %v", err)
+	}
+}
+
+func TestVerifyPresignedURL_UnknownKeyRef(t *testing.T) {
+	defer ResetTestConfig()
+	globalConf := config.Global()
+	globalConf.Secrets = map[string]string{}
+	config.SetGlobal(globalConf)
+
+	u, _ := url.Parse("https://example.org/download/file.zip?tyk_expires=9999999999&tyk_signature=deadbeef")
+	if err := verifyPresignedURL(u, "missing-key"); err != errPresignUnknownKeyRef {
+		t.Fatalf("expected errPresignUnknownKeyRef, got %v", err)
+	}
+}
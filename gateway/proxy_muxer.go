@@ -32,6 +32,7 @@ type handleWrapper struct {
 func (h *handleWrapper) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// make request body to be nopCloser and re-readable before serve it through chain of middlewares
 	nopCloseRequestBody(r)
+	ctxSetStreamMultiplexInfo(r, sharedConnMultiplexTracker.track(r))
 	if NewRelicApplication != nil {
 		txn := NewRelicApplication.StartTransaction(r.URL.Path, w, r)
 		defer txn.End()
@@ -155,6 +156,10 @@ func (m *proxyMux) handle404(w http.ResponseWriter, r *http.Request) {
 			Error(http.StatusText(http.StatusNotFound))
 	}
 
+	if dispatchNotFoundHook(w, r) {
+		return
+	}
+
 	w.WriteHeader(http.StatusNotFound)
 	_, _ = fmt.Fprint(w, http.StatusText(http.StatusNotFound))
 }
@@ -396,6 +401,7 @@ func (m *proxyMux) serve() {
 				ReadTimeout:  readTimeout,
 				WriteTimeout: writeTimeout,
 				Handler:      &handleWrapper{p.router},
+				ConnState:    trackConnState,
 			}
 
 			if config.Global().CloseConnections {
@@ -0,0 +1,75 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// RateLimitDecision is a structured, read-only view of a key's current
+// rate-limit state, for a hook that wants to emit its own headers or error
+// body instead of the gateway's default 429.
+type RateLimitDecision struct {
+	Allowed   bool    `json:"allowed"`
+	Limit     float64 `json:"limit"`
+	Remaining float64 `json:"remaining"`
+	Reset     int64   `json:"reset"`
+	Reason    string  `json:"reason,omitempty"`
+}
+
+// rateCheck reports whether key is currently within its rate limit for
+// spec, without it counting as a hit - a hook asking "would this be
+// allowed" shouldn't itself consume the caller's allowance. It goes through
+// SessionLimiter.ForwardMessage's existing dryRun path, the exact same one
+// the enforcing RateLimitAndQuotaCheck middleware uses, so the decision can
+// never drift from what that middleware is about to do with the same key.
+func rateCheck(spec *APISpec, key string) (*RateLimitDecision, error) {
+	session, found := GlobalSessionManager.SessionDetail(spec.OrgID, key, false)
+	if !found {
+		return nil, errors.New("key not found")
+	}
+
+	rate, per := session.Rate, session.Per
+	if len(session.AccessRights) > 0 {
+		if rights, ok := session.AccessRights[spec.APIID]; ok && rights.Limit != nil {
+			rate, per = rights.Limit.Rate, rights.Limit.Per
+		}
+	}
+
+	decision := &RateLimitDecision{
+		Limit: rate,
+		Reset: time.Now().Add(time.Duration(per) * time.Second).Unix(),
+	}
+
+	if spec.DisableRateLimit || rate <= 0 {
+		decision.Allowed = true
+		decision.Remaining = rate
+		decision.Reason = "rate limiting disabled"
+		return decision, nil
+	}
+
+	storeRef := rateLimitStoreFor(&session, GlobalSessionManager.Store())
+	reason := sessionLimiter.ForwardMessage(
+		&http.Request{},
+		&session,
+		key,
+		storeRef,
+		true,
+		false,
+		&spec.GlobalConfig,
+		spec.APIID,
+		true, // dryRun: read the counters, don't record a hit
+	)
+
+	switch reason {
+	case sessionFailRateLimit:
+		decision.Allowed = false
+		decision.Remaining = 0
+		decision.Reason = "rate limit exceeded"
+	default:
+		decision.Allowed = true
+		decision.Remaining = rate
+	}
+
+	return decision, nil
+}
@@ -0,0 +1,98 @@
+package gateway
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// TestRateCheck checks that rateCheck reflects the same rolling-window
+// counters RateLimitAndQuotaCheck itself enforces, without rateCheck's own
+// reads counting as a hit.
+func TestRateCheck(t *testing.T) {
+	defer ResetTestConfig()
+	ts := StartTest()
+	defer ts.Close()
+
+	specs := BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID += "_" + time.Now().String()
+		spec.UseKeylessAccess = false
+		spec.DisableRateLimit = false
+		spec.OrgID = "default"
+		spec.Proxy.ListenPath = "/"
+	})
+	spec := specs[0]
+
+	token := CreateSession(func(s *user.SessionState) {
+		s.Rate = 1
+		s.Per = 60
+	})
+	defer GlobalSessionManager.RemoveSession("default", token, false)
+
+	decision, err := rateCheck(spec, token)
+	if err != nil {
+		t.Fatalf("rateCheck failed: %s", err.Error())
+	}
+	if !decision.Allowed {
+		t.Fatalf("expected a fresh key to be allowed, got %+v", decision)
+	}
+
+	// A rateCheck read must not itself count as a hit - checking again
+	// should still say allowed.
+	decision, err = rateCheck(spec, token)
+	if err != nil {
+		t.Fatalf("rateCheck failed: %s", err.Error())
+	}
+	if !decision.Allowed {
+		t.Fatalf("expected rateCheck to not consume the allowance, got %+v", decision)
+	}
+
+	ts.Run(t, test.TestCase{
+		Headers: map[string]string{"Authorization": token}, Code: http.StatusOK, Path: "/", Delay: 100 * time.Millisecond,
+	})
+
+	decision, err = rateCheck(spec, token)
+	if err != nil {
+		t.Fatalf("rateCheck failed: %s", err.Error())
+	}
+	if decision.Allowed {
+		t.Fatalf("expected the key to be over its limit after one real request, got %+v", decision)
+	}
+	if decision.Reason == "" {
+		t.Fatal("expected a reason to be set for a denied check")
+	}
+
+	if _, err := rateCheck(spec, "no-such-key"); err == nil {
+		t.Fatal("expected an error for an unknown key")
+	}
+}
+
+func TestRateCheck_Unlimited(t *testing.T) {
+	defer ResetTestConfig()
+	ts := StartTest()
+	defer ts.Close()
+
+	specs := BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID += "_" + time.Now().String()
+		spec.UseKeylessAccess = false
+		spec.OrgID = "default"
+		spec.Proxy.ListenPath = "/"
+	})
+	spec := specs[0]
+
+	token := CreateSession(func(s *user.SessionState) {
+		s.Rate = 0
+	})
+	defer GlobalSessionManager.RemoveSession("default", token, false)
+
+	decision, err := rateCheck(spec, token)
+	if err != nil {
+		t.Fatalf("rateCheck failed: %s", err.Error())
+	}
+	if !decision.Allowed || decision.Reason == "" {
+		t.Fatalf("expected an unlimited key to be allowed with a reason, got %+v", decision)
+	}
+}
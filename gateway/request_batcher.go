@@ -0,0 +1,114 @@
+package gateway
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// errBatchItemMissing is returned to a caller whose item the upstream didn't
+// return a corresponding result for, so a partial batch failure surfaces as
+// a per-item error rather than silently returning someone else's response.
+var errBatchItemMissing = errors.New("upstream batch response did not include a result for this item")
+
+// batchItem is a single caller's payload waiting to be flushed as part of a
+// batch, along with the channel used to hand its result back.
+type batchItem struct {
+	body   string
+	result chan batchItemResult
+}
+
+type batchItemResult struct {
+	body string
+	err  error
+}
+
+// RequestBatcher collects payloads submitted by concurrent callers within a
+// short window and flushes them to the upstream together via sendBatch, then
+// fans the per-item results back out. It exists so hook-driven plugins can
+// coalesce many small upstream calls into fewer batch-endpoint calls without
+// each hook invocation managing its own coordination.
+type RequestBatcher struct {
+	window    time.Duration
+	maxBatch  int
+	sendBatch func(bodies []string) ([]batchItemResult, error)
+
+	mu      sync.Mutex
+	pending []*batchItem
+	timer   *time.Timer
+}
+
+// NewRequestBatcher creates a batcher that flushes whenever either maxBatch
+// items have accumulated or window has elapsed since the first item in the
+// current batch arrived, whichever comes first.
+func NewRequestBatcher(window time.Duration, maxBatch int, sendBatch func(bodies []string) ([]batchItemResult, error)) *RequestBatcher {
+	if maxBatch < 1 {
+		maxBatch = 1
+	}
+	return &RequestBatcher{
+		window:    window,
+		maxBatch:  maxBatch,
+		sendBatch: sendBatch,
+	}
+}
+
+// Submit adds body to the current batch and blocks until that item's result
+// is available, either because the batch flushed or the window expired.
+func (b *RequestBatcher) Submit(body string) (string, error) {
+	item := &batchItem{body: body, result: make(chan batchItemResult, 1)}
+
+	b.mu.Lock()
+	b.pending = append(b.pending, item)
+	flushNow := len(b.pending) >= b.maxBatch
+	if flushNow {
+		if b.timer != nil {
+			b.timer.Stop()
+			b.timer = nil
+		}
+	} else if b.timer == nil {
+		b.timer = time.AfterFunc(b.window, b.flush)
+	}
+	b.mu.Unlock()
+
+	if flushNow {
+		b.flush()
+	}
+
+	res := <-item.result
+	return res.body, res.err
+}
+
+// flush sends whatever is currently pending as one batch. Called at most
+// once per batch, either by the item that filled it or by the window timer.
+func (b *RequestBatcher) flush() {
+	b.mu.Lock()
+	items := b.pending
+	b.pending = nil
+	b.timer = nil
+	b.mu.Unlock()
+
+	if len(items) == 0 {
+		return
+	}
+
+	bodies := make([]string, len(items))
+	for i, item := range items {
+		bodies[i] = item.body
+	}
+
+	results, err := b.sendBatch(bodies)
+	if err != nil {
+		for _, item := range items {
+			item.result <- batchItemResult{err: err}
+		}
+		return
+	}
+
+	for i, item := range items {
+		if i >= len(results) {
+			item.result <- batchItemResult{err: errBatchItemMissing}
+			continue
+		}
+		item.result <- results[i]
+	}
+}
@@ -0,0 +1,75 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRequestBatcher_FlushesOnMaxBatch(t *testing.T) {
+	var got [][]string
+	b := NewRequestBatcher(time.Minute, 2, func(bodies []string) ([]batchItemResult, error) {
+		got = append(got, append([]string{}, bodies...))
+		results := make([]batchItemResult, len(bodies))
+		for i, body := range bodies {
+			results[i] = batchItemResult{body: body + "-ok"}
+		}
+		return results, nil
+	})
+
+	results := make(chan string, 2)
+	for _, body := range []string{"a", "b"} {
+		body := body
+		go func() {
+			res, err := b.Submit(body)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			results <- res
+		}()
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case r := <-results:
+			seen[r] = true
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for batch result")
+		}
+	}
+
+	if !seen["a-ok"] || !seen["b-ok"] {
+		t.Errorf("unexpected results: %v", seen)
+	}
+	if len(got) != 1 || len(got[0]) != 2 {
+		t.Errorf("expected a single batch of 2, got %v", got)
+	}
+}
+
+func TestRequestBatcher_FlushesOnWindow(t *testing.T) {
+	b := NewRequestBatcher(20*time.Millisecond, 10, func(bodies []string) ([]batchItemResult, error) {
+		results := make([]batchItemResult, len(bodies))
+		for i, body := range bodies {
+			results[i] = batchItemResult{body: body}
+		}
+		return results, nil
+	})
+
+	res, err := b.Submit("solo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res != "solo" {
+		t.Errorf("expected solo, got %q", res)
+	}
+}
+
+func TestRequestBatcher_MissingResultIsPerItemError(t *testing.T) {
+	b := NewRequestBatcher(time.Minute, 1, func(bodies []string) ([]batchItemResult, error) {
+		return nil, nil
+	})
+
+	if _, err := b.Submit("a"); err != errBatchItemMissing {
+		t.Errorf("expected errBatchItemMissing, got %v", err)
+	}
+}
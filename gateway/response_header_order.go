@@ -0,0 +1,80 @@
+package gateway
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// writeOrderedResponse writes res to rw preserving the exact header casing
+// and order a response hook requested via HeaderOrder (see
+// CustomMiddlewareResponseHook.HandleResponse), by hijacking the underlying
+// connection and writing the status line and headers by hand. It only does
+// this for HTTP/1.1: HTTP/2 frames headers via HPACK, which always
+// lowercases names and controls its own ordering, so there's nothing for
+// the gateway to override there. Returns false without touching rw or res
+// whenever ordering can't be honoured, so the caller can fall back to the
+// normal write path.
+func writeOrderedResponse(rw http.ResponseWriter, req *http.Request, res *http.Response, order []string) bool {
+	if req.ProtoMajor != 1 || req.ProtoMinor != 1 {
+		return false
+	}
+
+	hj, ok := rw.(http.Hijacker)
+	if !ok {
+		return false
+	}
+
+	conn, buf, err := hj.Hijack()
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+
+	writeStatusAndHeaders(buf, res, order)
+
+	if res.Body != nil {
+		io.Copy(buf, res.Body)
+	}
+
+	buf.Flush()
+	return true
+}
+
+func writeStatusAndHeaders(buf *bufio.ReadWriter, res *http.Response, order []string) {
+	fmt.Fprintf(buf, "HTTP/1.1 %d %s\r\n", res.StatusCode, http.StatusText(res.StatusCode))
+
+	// Connection: close, since we've bypassed net/http's own bookkeeping for
+	// this connection and can no longer safely hand it back for reuse.
+	wroteConnectionClose := false
+
+	written := make(map[string]bool, len(order))
+	for _, k := range order {
+		for _, v := range res.Header[k] {
+			fmt.Fprintf(buf, "%s: %s\r\n", k, v)
+		}
+		written[k] = true
+		if k == "Connection" {
+			wroteConnectionClose = true
+		}
+	}
+
+	for k, vv := range res.Header {
+		if written[k] {
+			continue
+		}
+		for _, v := range vv {
+			fmt.Fprintf(buf, "%s: %s\r\n", k, v)
+		}
+		if k == "Connection" {
+			wroteConnectionClose = true
+		}
+	}
+
+	if !wroteConnectionClose {
+		fmt.Fprintf(buf, "Connection: close\r\n")
+	}
+
+	fmt.Fprint(buf, "\r\n")
+}
@@ -136,7 +136,44 @@ func EnsureTransport(host, protocol string) string {
 }
 
 func nextTarget(targetData *apidef.HostList, spec *APISpec) (string, error) {
+	return nextTargetWithAffinity(targetData, spec, "", nil)
+}
+
+// nextTargetWithAffinity is nextTarget, plus support for a hook-pinned
+// upstream (see ReturnOverrides.AffinityKey / setUpstreamAffinity) and
+// hook-supplied per-request target weights (see
+// ReturnOverrides.TargetWeights / weightedTarget). When affinityKey is set
+// and load balancing is enabled, a previously pinned, still-healthy
+// upstream is reused. Otherwise, if weights are set, a weighted pick is
+// made; if that fails (no valid weights), or weights weren't set, the
+// normal round-robin pick is made. A successful pick is recorded as the
+// new pin when affinityKey is set, so subsequent requests with the same
+// key follow it.
+func nextTargetWithAffinity(targetData *apidef.HostList, spec *APISpec, affinityKey string, weights map[string]float64) (string, error) {
+	if spec.Proxy.EnableLoadBalancing && affinityKey != "" {
+		if pinned := getUpstreamAffinity(spec.APIID, affinityKey); pinned != "" {
+			if !spec.Proxy.CheckHostAgainstUptimeTests || !GlobalHostChecker.HostDown(pinned) {
+				return pinned, nil
+			}
+			// Pinned instance is unhealthy: fall through to the normal pick
+			// below, which will fail over and overwrite the pin.
+		}
+	}
+
 	if spec.Proxy.EnableLoadBalancing {
+		if len(weights) > 0 {
+			if picked, ok := weightedTarget(targetData, weights); ok {
+				host := EnsureTransport(picked, spec.Protocol)
+				if !spec.Proxy.CheckHostAgainstUptimeTests || !GlobalHostChecker.HostDown(host) {
+					if affinityKey != "" {
+						setUpstreamAffinity(spec.APIID, affinityKey, host)
+					}
+					return host, nil
+				}
+				// Weighted pick is unhealthy: fall through to round robin.
+			}
+		}
+
 		log.Debug("[PROXY] [LOAD BALANCING] Load balancer enabled, getting upstream target")
 		// Use a HostList
 		startPos := spec.RoundRobin.WithLen(targetData.Len())
@@ -149,11 +186,11 @@ func nextTarget(targetData *apidef.HostList, spec *APISpec) (string, error) {
 
 			host := EnsureTransport(gotHost, spec.Protocol)
 
-			if !spec.Proxy.CheckHostAgainstUptimeTests {
-				return host, nil // we don't care if it's up
-			}
-			if !GlobalHostChecker.HostDown(host) {
-				return host, nil // we do care and it's up
+			if !spec.Proxy.CheckHostAgainstUptimeTests || !GlobalHostChecker.HostDown(host) {
+				if affinityKey != "" {
+					setUpstreamAffinity(spec.APIID, affinityKey, host)
+				}
+				return host, nil // either we don't care if it's up, or we do and it is
 			}
 			// if the host is down, keep trying all the rest
 			// in order from where we started.
@@ -232,7 +269,7 @@ func TykNewSingleHostReverseProxy(target *url.URL, spec *APISpec, logger *logrus
 			}
 			fallthrough // implies load balancing, with replaced host list
 		case spec.Proxy.EnableLoadBalancing:
-			host, err := nextTarget(hostList, spec)
+			host, err := nextTargetWithAffinity(hostList, spec, ctxGetUpstreamAffinityKey(req), ctxGetTargetWeights(req))
 			if err != nil {
 				log.Error("[PROXY] [LOAD BALANCING] ", err)
 				host = allHostsDownURL
@@ -268,9 +305,9 @@ func TykNewSingleHostReverseProxy(target *url.URL, spec *APISpec, logger *logrus
 		if targetToUse == target {
 			req.URL.Scheme = targetToUse.Scheme
 			req.URL.Host = targetToUse.Host
-			req.URL.Path = singleJoiningSlash(targetToUse.Path, req.URL.Path, spec.Proxy.DisableStripSlash)
+			req.URL.Path = singleJoiningSlash(targetToUse.Path, applyUpstreamPathPrefixOverride(req, req.URL.Path), spec.Proxy.DisableStripSlash)
 			if req.URL.RawPath != "" {
-				req.URL.RawPath = singleJoiningSlash(targetToUse.Path, req.URL.RawPath, spec.Proxy.DisableStripSlash)
+				req.URL.RawPath = singleJoiningSlash(targetToUse.Path, applyUpstreamPathPrefixOverride(req, req.URL.RawPath), spec.Proxy.DisableStripSlash)
 			}
 		}
 
@@ -306,6 +343,10 @@ func TykNewSingleHostReverseProxy(target *url.URL, spec *APISpec, logger *logrus
 		case "wss":
 			req.URL.Scheme = "https"
 		}
+
+		if spec.Proxy.BasicAuth.Enabled {
+			req.SetBasicAuth(spec.Proxy.BasicAuth.Username, spec.Proxy.BasicAuth.Password)
+		}
 	}
 
 	if logger == nil {
@@ -399,6 +440,22 @@ func singleJoiningSlash(a, b string, disableStripSlash bool) string {
 	return a
 }
 
+// applyUpstreamPathPrefixOverride strips and/or prepends a pre hook-set path
+// prefix (see ReturnOverrides.UpstreamPathPrefixStrip/Prepend) before path is
+// joined onto the upstream target's base path. It's a no-op if no hook set
+// an override for this request.
+func applyUpstreamPathPrefixOverride(req *http.Request, path string) string {
+	override, ok := ctxGetUpstreamPathPrefixOverride(req)
+	if !ok {
+		return path
+	}
+
+	if override.Strip != "" {
+		path = strings.TrimPrefix(path, override.Strip)
+	}
+	return override.Prepend + path
+}
+
 func removeDuplicateCORSHeader(dst, src http.Header) {
 	for _, v := range corsHeaders {
 		keyName := http.CanonicalHeaderKey(v)
@@ -451,12 +508,27 @@ func (p *ReverseProxy) ServeHTTP(rw http.ResponseWriter, req *http.Request) Prox
 	finishTime := time.Since(startTime)
 	p.logger.WithField("ns", finishTime.Nanoseconds()).Debug("Finished")
 
+	p.recordUpstreamErrorRate(resp)
+
 	// make response body to be nopCloser and re-readable before serve it through chain of middlewares
 	nopCloseResponseBody(resp.Response)
 
 	return resp
 }
 
+// recordUpstreamErrorRate feeds this response's outcome into the API's
+// sliding-window error counter (see upstream_error_rate.go). A missing
+// response (the upstream couldn't be reached at all) and a 5xx status both
+// count as an error; everything else, including 4xx, doesn't - those are
+// client errors, not upstream health signals.
+func (p *ReverseProxy) recordUpstreamErrorRate(resp ProxyResponse) {
+	if p.TykAPISpec == nil || p.TykAPISpec.UpstreamErrorRate == nil {
+		return
+	}
+	isError := resp.Response == nil || resp.Response.StatusCode >= http.StatusInternalServerError
+	p.TykAPISpec.UpstreamErrorRate.Record(isError)
+}
+
 func (p *ReverseProxy) ServeHTTPForCache(rw http.ResponseWriter, req *http.Request) ProxyResponse {
 	startTime := time.Now()
 	p.logger.WithField("ts", startTime.UnixNano()).Debug("Started")
@@ -470,6 +542,19 @@ func (p *ReverseProxy) ServeHTTPForCache(rw http.ResponseWriter, req *http.Reque
 }
 
 func (p *ReverseProxy) CheckHardTimeoutEnforced(spec *APISpec, req *http.Request) (bool, float64) {
+	found, timeout := hardTimeoutFor(spec, req)
+	if found {
+		p.logger.Debug("HARD TIMEOUT ENFORCED: ", timeout)
+	}
+	return found, timeout
+}
+
+// hardTimeoutFor looks up the per-endpoint hard timeout (or, failing that,
+// the gateway-wide ProxyDefaultTimeout) for
+// a request, without needing a ReverseProxy to log through - used both by
+// CheckHardTimeoutEnforced and as the cap a pre hook's own requested
+// upstream timeout (ReturnOverrides.UpstreamTimeoutSeconds) can't exceed.
+func hardTimeoutFor(spec *APISpec, req *http.Request) (bool, float64) {
 	if !spec.EnforcedTimeoutEnabled {
 		return false, spec.GlobalConfig.ProxyDefaultTimeout
 	}
@@ -478,7 +563,6 @@ func (p *ReverseProxy) CheckHardTimeoutEnforced(spec *APISpec, req *http.Request
 	found, meta := spec.CheckSpecMatchesStatus(req, versionPaths, HardTimeout)
 	if found {
 		intMeta := meta.(*int)
-		p.logger.Debug("HARD TIMEOUT ENFORCED: ", *intMeta)
 		return true, float64(*intMeta)
 	}
 
@@ -617,7 +701,7 @@ func httpTransport(timeOut float64, rw http.ResponseWriter, req *http.Request, p
 		http2.ConfigureTransport(transport)
 	}
 
-	return &TykRoundTripper{transport, p.logger}
+	return &TykRoundTripper{transport: transport, logger: p.logger}
 }
 
 func (p *ReverseProxy) setCommonNameVerifyPeerCertificate(tlsConfig *tls.Config, hostName string) {
@@ -672,6 +756,31 @@ func (p *ReverseProxy) setCommonNameVerifyPeerCertificate(tlsConfig *tls.Config,
 type TykRoundTripper struct {
 	transport *http.Transport
 	logger    *logrus.Entry
+
+	h1Once      sync.Once
+	h1Transport *http.Transport
+}
+
+// upstream protocol names accepted in return_overrides.upstream_protocol.
+const (
+	upstreamProtocolHTTP1 = "http/1.1"
+	upstreamProtocolHTTP2 = "h2"
+)
+
+// http1Only returns a RoundTripper cloned from rt that never negotiates
+// HTTP/2 over TLS (ALPN), for requests that asked to be downgraded via
+// return_overrides.upstream_protocol. It's built once per transport and
+// reused, since cloning a transport is relatively expensive and transports
+// are already cached per-API.
+func (rt *TykRoundTripper) http1Only() *TykRoundTripper {
+	rt.h1Once.Do(func() {
+		clone := rt.transport.Clone()
+		// A non-nil, empty TLSNextProto disables ALPN-negotiated HTTP/2
+		// without touching any other transport settings.
+		clone.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+		rt.h1Transport = clone
+	})
+	return &TykRoundTripper{transport: rt.h1Transport, logger: rt.logger}
 }
 
 func (rt *TykRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
@@ -695,10 +804,21 @@ func (rt *TykRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
 
 func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Request, withCache bool) ProxyResponse {
 	if trace.IsEnabled() {
-		span, ctx := trace.Span(req.Context(), req.URL.Path)
-		defer span.Finish()
+		span, spanCtx := trace.Span(req.Context(), req.URL.Path)
+		defer func() {
+			// A response/error hook running in the response chain below may
+			// have retroactively asked for this trace to be kept (see
+			// ReturnOverrides.RetainTrace), e.g. after spotting an error the
+			// head sampler had no way to know about when it made its
+			// decision. Only takes effect if the tracing backend honours a
+			// late sampling-priority tag - otherwise it's a no-op.
+			if ctxGetRetainTrace(req) {
+				ext.SamplingPriority.Set(span, 1)
+			}
+			span.Finish()
+		}()
 		ext.SpanKindRPCClient.Set(span)
-		req = req.WithContext(ctx)
+		req = req.WithContext(spanCtx)
 	}
 	var roundTripper *TykRoundTripper
 
@@ -739,6 +859,19 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 		}()
 	}
 
+	// A pre hook can narrow the upstream timeout for this request (e.g. from
+	// the matched endpoint's own cost tier, already capped at the API's hard
+	// timeout/default). Setting the deadline on req as well as reqCtx means
+	// requestDeadlineRemaining(req) - which gates the retry loop below -
+	// honours it too, so the deadline covers the whole call, retries
+	// included, rather than resetting on each attempt.
+	if timeout := ctxGetUpstreamTimeoutOverride(req); timeout > 0 {
+		var cancel context.CancelFunc
+		reqCtx, cancel = context.WithTimeout(reqCtx, time.Duration(timeout*float64(time.Second)))
+		defer cancel()
+		setContext(req, reqCtx)
+	}
+
 	// Do this before we make a shallow copy
 	session := ctxGetSession(req)
 
@@ -811,6 +944,13 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 		outreq.Header.Set(headers.XForwardFor, addrs)
 	}
 
+	// Set once, on the single outreq object every retry of this request
+	// reuses below, so a gateway-initiated retry sends the upstream the
+	// exact same idempotency key rather than a fresh one.
+	if key := ctxGetUpstreamIdempotencyKey(req); key != "" {
+		outreq.Header.Set(idempotencyKeyHeader, key)
+	}
+
 	// Circuit breaker
 	breakerEnforced, breakerConf := p.CheckCircuitBreakerEnforced(p.TykAPISpec, req)
 
@@ -841,6 +981,19 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 
 	}
 
+	if protocol := ctxGetUpstreamProtocolOverride(req); protocol != "" {
+		switch protocol {
+		case upstreamProtocolHTTP1:
+			roundTripper = roundTripper.http1Only()
+		case upstreamProtocolHTTP2:
+			if roundTripper.transport.TLSNextProto == nil {
+				p.logger.Warning("pre hook requested upstream protocol upgrade to h2, but HTTP/2 isn't enabled for this API; ignoring")
+			}
+		default:
+			p.logger.Warningf("pre hook requested unsupported upstream protocol %q; ignoring", protocol)
+		}
+	}
+
 	// do request round trip
 	var res *http.Response
 	var err error
@@ -873,14 +1026,31 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 		upstreamLatency = time.Since(begin)
 	}
 
+	retriesRemaining := 0
+	if isIdempotentMethod(outreq.Method) {
+		retriesRemaining = ctxGetRetryBudget(req)
+	}
+
 	if breakerEnforced {
 		if !breakerConf.CB.Ready() {
 			p.logger.Debug("ON REQUEST: Circuit Breaker is in OPEN state")
+			// A pre hook can supply a dynamic value (e.g. derived from its own
+			// backoff bookkeeping) via ReturnOverrides.RetryAfter; otherwise fall
+			// back to how long this breaker itself waits before it next resets.
+			retryAfter := ctxGetRetryAfter(req)
+			if retryAfter == "" {
+				retryAfter = strconv.Itoa(breakerConf.ReturnToServiceAfter)
+			}
+			rw.Header().Set("Retry-After", retryAfter)
 			p.ErrorHandler.HandleError(rw, logreq, "Service temporarily unavailable.", 503, true)
 			return ProxyResponse{}
 		}
 		p.logger.Debug("ON REQUEST: Circuit Breaker is in CLOSED or HALF-OPEN state")
 		sendRequestToUpstream()
+		for err != nil && retriesRemaining > 0 && requestDeadlineRemaining(req) {
+			retriesRemaining--
+			sendRequestToUpstream()
+		}
 		if err != nil || res.StatusCode/100 == 5 {
 			breakerConf.CB.Fail()
 		} else {
@@ -888,6 +1058,10 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 		}
 	} else {
 		sendRequestToUpstream()
+		for err != nil && retriesRemaining > 0 && requestDeadlineRemaining(req) {
+			retriesRemaining--
+			sendRequestToUpstream()
+		}
 	}
 
 	if err != nil {
@@ -909,6 +1083,7 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 			"api_id":      p.TykAPISpec.APIID,
 		}).Error("http: proxy error: ", err)
 		if strings.Contains(err.Error(), "timeout awaiting response headers") {
+			ctxSetTerminationReason(req, TerminationUpstreamTimeout)
 			p.ErrorHandler.HandleError(rw, logreq, "Upstream service reached hard timeout.", http.StatusGatewayTimeout, true)
 
 			if p.TykAPISpec.Proxy.ServiceDiscovery.UseDiscoveryService {
@@ -921,14 +1096,17 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 		}
 
 		if strings.Contains(err.Error(), "context canceled") {
+			ctxSetTerminationReason(req, TerminationClientDisconnect)
 			p.ErrorHandler.HandleError(rw, logreq, "Client closed request", 499, true)
 			return ProxyResponse{UpstreamLatency: upstreamLatency}
 		}
 
 		if strings.Contains(err.Error(), "no such host") {
+			ctxSetTerminationReason(req, TerminationUpstreamError)
 			p.ErrorHandler.HandleError(rw, logreq, "Upstream host lookup failed", http.StatusInternalServerError, true)
 			return ProxyResponse{UpstreamLatency: upstreamLatency}
 		}
+		ctxSetTerminationReason(req, TerminationUpstreamError)
 		p.ErrorHandler.HandleError(rw, logreq, "There was a problem proxying the request", http.StatusInternalServerError, true)
 		return ProxyResponse{UpstreamLatency: upstreamLatency}
 
@@ -984,11 +1162,11 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 	// We should at least copy the status code in
 	inres.StatusCode = res.StatusCode
 	inres.ContentLength = res.ContentLength
-	p.HandleResponse(rw, res, ses)
+	p.HandleResponse(rw, req, res, ses)
 	return ProxyResponse{UpstreamLatency: upstreamLatency, Response: inres}
 }
 
-func (p *ReverseProxy) HandleResponse(rw http.ResponseWriter, res *http.Response, ses *user.SessionState) error {
+func (p *ReverseProxy) HandleResponse(rw http.ResponseWriter, req *http.Request, res *http.Response, ses *user.SessionState) error {
 
 	// Remove hop-by-hop headers listed in the
 	// "Connection" header of the response.
@@ -1019,6 +1197,13 @@ func (p *ReverseProxy) HandleResponse(rw http.ResponseWriter, res *http.Response
 		res.Header.Set(headers.XRateLimitReset, strconv.Itoa(int(quotaRenews)))
 	}
 
+	if order := ctxGetResponseHeaderOrder(req); len(order) > 0 && len(res.Trailer) == 0 {
+		if writeOrderedResponse(rw, req, res, order) {
+			return nil
+		}
+		p.logger.Warning("response hook requested header ordering, but it isn't supported for this connection (e.g. HTTP/2); falling back to default header order")
+	}
+
 	copyHeader(rw.Header(), res.Header)
 
 	announcedTrailers := len(res.Trailer)
@@ -1194,6 +1379,29 @@ func (m *maxLatencyWriter) flushLoop() {
 
 func (m *maxLatencyWriter) stop() { m.done <- true }
 
+// isIdempotentMethod reports whether a request with this method can be
+// safely retried against the upstream without risking duplicate side
+// effects.
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// requestDeadlineRemaining reports whether the request's context still has
+// time left, so hook-requested retries never push a call past the overall
+// request timeout.
+func requestDeadlineRemaining(r *http.Request) bool {
+	deadline, ok := r.Context().Deadline()
+	if !ok {
+		return true
+	}
+	return time.Now().Before(deadline)
+}
+
 func requestIPHops(r *http.Request) string {
 	clientIP, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
@@ -378,9 +378,9 @@ func TestCircuitBreaker5xxs(t *testing.T) {
 			{Path: "/errors/500", Code: http.StatusInternalServerError},
 			{Path: "/errors/501", Code: http.StatusNotImplemented},
 			{Path: "/errors/502", Code: http.StatusBadGateway},
-			{Path: "/errors/500", Code: http.StatusServiceUnavailable},
-			{Path: "/errors/501", Code: http.StatusServiceUnavailable},
-			{Path: "/errors/502", Code: http.StatusServiceUnavailable},
+			{Path: "/errors/500", Code: http.StatusServiceUnavailable, HeadersMatch: map[string]string{"Retry-After": "6000"}},
+			{Path: "/errors/501", Code: http.StatusServiceUnavailable, HeadersMatch: map[string]string{"Retry-After": "6000"}},
+			{Path: "/errors/502", Code: http.StatusServiceUnavailable, HeadersMatch: map[string]string{"Retry-After": "6000"}},
 		}...)
 	})
 }
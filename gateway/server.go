@@ -28,6 +28,7 @@ import (
 	"github.com/TykTechnologies/tyk/cli"
 	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/dnscache"
+	"github.com/TykTechnologies/tyk/goplugin"
 	"github.com/TykTechnologies/tyk/headers"
 	logger "github.com/TykTechnologies/tyk/log"
 	"github.com/TykTechnologies/tyk/regexp"
@@ -177,6 +178,7 @@ func setupGlobals(ctx context.Context) {
 	InitHostCheckManager(ctx, &healthCheckStore)
 
 	initHealthCheck(ctx)
+	initResourcePressureSampler(ctx)
 
 	redisStore := storage.RedisCluster{KeyPrefix: "apikey-", HashKeys: config.Global().HashKeys}
 	GlobalSessionManager.Init(&redisStore)
@@ -215,6 +217,7 @@ func setupGlobals(ctx context.Context) {
 	templates = template.Must(template.ParseGlob(templatesDir))
 
 	CoProcessInit()
+	startCoProcessHealthCheck(ctx)
 
 	// Get the notifier ready
 	mainLog.Debug("Notifier will not work in hybrid mode")
@@ -520,7 +523,15 @@ func addOAuthHandlers(spec *APISpec, muxer *mux.Router) *OAuthManager {
 
 	osinServer := TykOsinNewServer(serverConfig, osinStorage)
 
-	oauthManager := OAuthManager{spec, osinServer}
+	oauthManager := OAuthManager{API: spec, OsinServer: osinServer}
+	if spec.Oauth2Meta.AuthorizeHook.Name != "" {
+		hook, err := goplugin.GetHandler(spec.Oauth2Meta.AuthorizeHook.Path, spec.Oauth2Meta.AuthorizeHook.Name)
+		if err != nil {
+			mainLog.WithError(err).Error("Could not load OAuth authorize hook")
+		} else {
+			oauthManager.authorizeHook = hook
+		}
+	}
 	oauthHandlers := OAuthHandlers{oauthManager}
 
 	muxer.Handle(apiAuthorizePath, checkIsAPIOwner(allowMethods(oauthHandlers.HandleGenerateAuthCodeData, "POST")))
@@ -538,6 +549,13 @@ func addBatchEndpoint(spec *APISpec, muxer *mux.Router) {
 	muxer.HandleFunc(apiBatchPath, batchHandler.HandleBatchRequest)
 }
 
+func addStreamingFanoutEndpoint(spec *APISpec, muxer *mux.Router) {
+	mainLog.Debug("Streaming fan-out requests enabled for API")
+	apiStreamBatchPath := spec.Proxy.ListenPath + "tyk/stream-batch/"
+	streamHandler := StreamingFanoutHandler{API: spec}
+	muxer.HandleFunc(apiStreamBatchPath, streamHandler.HandleStreamingFanout)
+}
+
 func loadCustomMiddleware(spec *APISpec) ([]string, apidef.MiddlewareDefinition, []apidef.MiddlewareDefinition, []apidef.MiddlewareDefinition, []apidef.MiddlewareDefinition, []apidef.MiddlewareDefinition, apidef.MiddlewareDriver) {
 	mwPaths := []string{}
 	var mwAuthCheckFunc apidef.MiddlewareDefinition
@@ -678,10 +696,32 @@ func handleCORS(chain *[]alice.Constructor, spec *APISpec) {
 			Debug:              spec.CORS.Debug,
 		})
 
+		*chain = append(*chain, corsMaxAgeOverride(spec))
 		*chain = append(*chain, c.Handler)
+		*chain = append(*chain, recordCORSDecision)
 	}
 }
 
+// recordCORSDecision runs immediately after the CORS handler and records
+// whether the request was allowed, so hooks can read it back as the
+// "cors_decision" entry of object.Spec. It trusts the header the CORS handler
+// actually emitted rather than re-implementing origin matching, so the
+// decision can never drift from what the client really saw.
+func recordCORSDecision(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		decision := CORSNotApplicable
+		if r.Header.Get("Origin") != "" {
+			if w.Header().Get("Access-Control-Allow-Origin") != "" {
+				decision = CORSAllowed
+			} else {
+				decision = CORSDenied
+			}
+		}
+		ctxSetCORSDecision(r, decision)
+		h.ServeHTTP(w, r)
+	})
+}
+
 func isRPCMode() bool {
 	return config.Global().AuthOverride.ForceAuthProvider &&
 		config.Global().AuthOverride.AuthProvider.StorageEngine == RPCStorageEngine
@@ -1261,6 +1301,7 @@ func Start() {
 	}
 	again.Wait(&defaultProxyMux.again)
 	mainLog.Info("Stop signal received.")
+	markGatewayShuttingDown()
 	if err := defaultProxyMux.again.Close(); err != nil {
 		mainLog.Error("Closing listeners: ", err)
 	}
@@ -3,6 +3,7 @@ package gateway
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/TykTechnologies/leakybucket"
@@ -29,6 +30,76 @@ const (
 	RateLimitKeyPrefix = "rate-limit-"
 )
 
+// RateLimitAlgorithmSlidingWindow is the value an auth hook sets on
+// session.rate_algorithm to request a strict sliding-window limiter for that
+// key, overriding the gateway-wide default (sentinel/rolling-window/DRL).
+// Unlike the DRL token-bucket default, it enforces Rate requests per Per
+// seconds with no burst allowance: a session can't save up unused allowance
+// and spend it all at once.
+const RateLimitAlgorithmSlidingWindow = "sliding_window"
+
+// RateLimitAlgorithmGCRA is the value an auth hook sets on
+// session.rate_algorithm to request a GCRA (generic cell rate algorithm)
+// limiter for that key, overriding the gateway-wide default. Unlike the
+// rolling-window counters the other algorithms use, GCRA tracks a single
+// "theoretical arrival time" per key, which smooths enforcement across
+// gateway nodes under concurrent load instead of letting a burst of
+// requests race each other to fill the same window. Config.EnableGCRARateLimiter
+// selects it gateway-wide without needing this per-session override.
+const RateLimitAlgorithmGCRA = "gcra"
+
+// RateLimitBackendMetaDataKey is the session.MetaData key an auth hook sets
+// to request a non-default rate-limit storage backend for this session, by
+// name, as configured in config.RateLimitStorageBackends.
+const RateLimitBackendMetaDataKey = "rate_limit_backend"
+
+// rateLimitStoreFor resolves which storage.Handler rate-limit and quota
+// counters should be read from and written to for this session, honouring
+// an auth-hook-selected backend name. An unknown or unconfigured name falls
+// back to defaultStore so quota counters stay consistent rather than
+// silently failing.
+func rateLimitStoreFor(session *user.SessionState, defaultStore storage.Handler) storage.Handler {
+	name, _ := session.MetaData[RateLimitBackendMetaDataKey].(string)
+	if name == "" {
+		return defaultStore
+	}
+
+	backend, ok := config.Global().RateLimitStorageBackends[name]
+	if !ok {
+		mainLog.Warningf("unknown rate-limit storage backend %q requested, using default", name)
+		return defaultStore
+	}
+
+	switch backend {
+	case "default", "":
+		return defaultStore
+	case "cache":
+		return &storage.RedisCluster{KeyPrefix: defaultStore.GetKeyPrefix(), IsCache: true}
+	default:
+		mainLog.Warningf("unsupported rate-limit storage backend %q for name %q, using default", backend, name)
+		return defaultStore
+	}
+}
+
+// sessionRequestRateWindowSeconds is the sliding window size used to compute
+// the per-session request rate exposed to hooks (see recordRequestRate).
+// It's independent of the API's own Rate/Per limits, so the metric stays
+// comparable across sessions with different rate-limit configuration.
+const sessionRequestRateWindowSeconds = 10
+
+// recordRequestRate samples the current session's sliding-window request
+// count and exposes it to hooks as requests/sec, using the same rolling
+// window primitive the rate limiter itself uses. It's deliberately tracked
+// under its own key so it works whether or not rate limiting is enabled for
+// this session. The count SetRollingWindow returns reflects the window
+// before this request's own entry is added, so a session's first request
+// always sees a rate of 0.
+func (l *SessionLimiter) recordRequestRate(r *http.Request, currentSession *user.SessionState, store storage.Handler) {
+	key := RateLimitKeyPrefix + "metric-" + currentSession.KeyHash()
+	count, _ := store.SetRollingWindow(key, sessionRequestRateWindowSeconds, "-1", false)
+	ctxSetSessionRequestRate(r, float64(count)/float64(sessionRequestRateWindowSeconds))
+}
+
 // SessionLimiter is the rate limiter for the API, use ForwardMessage() to
 // check if a message should pass through or not
 type SessionLimiter struct {
@@ -94,11 +165,28 @@ const (
 	sessionFailQuota
 )
 
-func (l *SessionLimiter) limitSentinel(currentSession *user.SessionState, key string, rateScope string, store storage.Handler,
+// rateLimitIdentity is what actually namespaces a request's rate limit
+// counters in the distributed store. It defaults to the session's key hash,
+// but a hook (auth or pre) may ask for a composite key instead - e.g.
+// tenant+endpoint+method rather than per-key or per-IP - via
+// ReturnOverrides.RateLimitKey. The "custom:" prefix keeps a hook-supplied
+// key in its own namespace, so it can never collide with a key hash (which
+// is always a fixed-width hex digest and never starts with that prefix).
+// Quota is unaffected either way: RedisQuotaExceeded derives its own key
+// straight from the session, never from this one.
+func (l *SessionLimiter) rateLimitIdentity(r *http.Request, currentSession *user.SessionState) string {
+	if custom := ctxGetCustomRateLimitKey(r); custom != "" {
+		return "custom:" + custom
+	}
+	return currentSession.KeyHash()
+}
+
+func (l *SessionLimiter) limitSentinel(r *http.Request, currentSession *user.SessionState, key string, rateScope string, store storage.Handler,
 	globalConf *config.Config, apiLimit *user.APILimit, dryRun bool) bool {
 
-	rateLimiterKey := RateLimitKeyPrefix + rateScope + currentSession.KeyHash()
-	rateLimiterSentinelKey := RateLimitKeyPrefix + rateScope + currentSession.KeyHash() + ".BLOCKED"
+	identity := l.rateLimitIdentity(r, currentSession)
+	rateLimiterKey := RateLimitKeyPrefix + rateScope + identity
+	rateLimiterSentinelKey := RateLimitKeyPrefix + rateScope + identity + ".BLOCKED"
 
 	go l.doRollingWindowWrite(key, rateLimiterKey, rateLimiterSentinelKey, currentSession, store, globalConf, apiLimit, dryRun)
 
@@ -111,11 +199,12 @@ func (l *SessionLimiter) limitSentinel(currentSession *user.SessionState, key st
 	return false
 }
 
-func (l *SessionLimiter) limitRedis(currentSession *user.SessionState, key string, rateScope string, store storage.Handler,
+func (l *SessionLimiter) limitRedis(r *http.Request, currentSession *user.SessionState, key string, rateScope string, store storage.Handler,
 	globalConf *config.Config, apiLimit *user.APILimit, dryRun bool) bool {
 
-	rateLimiterKey := RateLimitKeyPrefix + rateScope + currentSession.KeyHash()
-	rateLimiterSentinelKey := RateLimitKeyPrefix + rateScope + currentSession.KeyHash() + ".BLOCKED"
+	identity := l.rateLimitIdentity(r, currentSession)
+	rateLimiterKey := RateLimitKeyPrefix + rateScope + identity
+	rateLimiterSentinelKey := RateLimitKeyPrefix + rateScope + identity + ".BLOCKED"
 
 	if l.doRollingWindowWrite(key, rateLimiterKey, rateLimiterSentinelKey, currentSession, store, globalConf, apiLimit, dryRun) {
 		return true
@@ -123,6 +212,65 @@ func (l *SessionLimiter) limitRedis(currentSession *user.SessionState, key strin
 	return false
 }
 
+// gcraTATSuffix namespaces GCRA's theoretical-arrival-time value so it never
+// collides with the rolling-window sorted-set key the other algorithms keep
+// under the same identity.
+const gcraTATSuffix = ".gcra-tat"
+
+// limitGCRA enforces apiLimit.Rate requests per apiLimit.Per seconds using
+// the generic cell rate algorithm: a single "theoretical arrival time" (TAT)
+// is kept per key in Redis, and each request either advances it and is let
+// through or is rejected if it arrived before the TAT minus the burst
+// allowance. Unlike the sorted-set rolling window, there's only ever one
+// value to read and write, so concurrent requests across gateway nodes
+// converge on a smooth, evenly spaced admission rate instead of all racing
+// to read the same window before any of them writes to it.
+//
+// The read-then-write against store isn't wrapped in a transaction - like
+// limitDRL's in-memory bucket, a burst of truly simultaneous requests can
+// both read the same TAT and both be admitted. This mirrors the rest of the
+// package's tolerance for a small race window in exchange for not needing a
+// scripting facility on storage.Handler.
+func (l *SessionLimiter) limitGCRA(r *http.Request, currentSession *user.SessionState, key string, rateScope string, store storage.Handler,
+	apiLimit *user.APILimit, dryRun bool) bool {
+
+	rate := apiLimit.Rate
+	per := apiLimit.Per
+	if rate <= 0 || per <= 0 {
+		return false
+	}
+
+	identity := l.rateLimitIdentity(r, currentSession)
+	tatKey := RateLimitKeyPrefix + rateScope + identity + gcraTATSuffix
+
+	emissionInterval := per / rate
+	burstOffset := emissionInterval * rate // one Per-seconds-worth of burst allowance
+
+	now := time.Now()
+
+	tat := now
+	if raw, err := store.GetRawKey(tatKey); err == nil {
+		if nanos, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			if stored := time.Unix(0, nanos); stored.After(tat) {
+				tat = stored
+			}
+		}
+	}
+
+	newTat := tat.Add(time.Duration(emissionInterval * float64(time.Second)))
+	allowAt := newTat.Add(-time.Duration(burstOffset * float64(time.Second)))
+
+	if now.Before(allowAt) {
+		return true
+	}
+
+	if !dryRun {
+		store.SetRawKey(tatKey, strconv.FormatInt(newTat.UnixNano(), 10), int64(per)+1)
+	}
+
+	return false
+}
+
 func (l *SessionLimiter) limitDRL(currentSession *user.SessionState, key string, rateScope string,
 	apiLimit *user.APILimit, dryRun bool) bool {
 
@@ -178,6 +326,10 @@ func (sfr sessionFailReason) String() string {
 // Key values to manage rate are Rate and Per, e.g. Rate of 10 messages
 // Per 10 seconds
 func (l *SessionLimiter) ForwardMessage(r *http.Request, currentSession *user.SessionState, key string, store storage.Handler, enableRL, enableQ bool, globalConf *config.Config, apiID string, dryRun bool) sessionFailReason {
+	if !dryRun {
+		l.recordRequestRate(r, currentSession, store)
+	}
+
 	// check for limit on API level (set to session by ApplyPolicies)
 	var apiLimit *user.APILimit
 	var allowanceScope string
@@ -209,12 +361,20 @@ func (l *SessionLimiter) ForwardMessage(r *http.Request, currentSession *user.Se
 		if allowanceScope != "" {
 			rateScope = allowanceScope + "-"
 		}
-		if globalConf.EnableSentinelRateLimiter {
-			if l.limitSentinel(currentSession, key, rateScope, store, globalConf, apiLimit, dryRun) {
+		if currentSession.RateLimitAlgorithm == RateLimitAlgorithmSlidingWindow {
+			if l.limitRedis(r, currentSession, key, rateScope, store, globalConf, apiLimit, dryRun) {
+				return sessionFailRateLimit
+			}
+		} else if currentSession.RateLimitAlgorithm == RateLimitAlgorithmGCRA || (currentSession.RateLimitAlgorithm == "" && globalConf.EnableGCRARateLimiter) {
+			if l.limitGCRA(r, currentSession, key, rateScope, store, apiLimit, dryRun) {
+				return sessionFailRateLimit
+			}
+		} else if globalConf.EnableSentinelRateLimiter {
+			if l.limitSentinel(r, currentSession, key, rateScope, store, globalConf, apiLimit, dryRun) {
 				return sessionFailRateLimit
 			}
 		} else if globalConf.EnableRedisRollingLimiter {
-			if l.limitRedis(currentSession, key, rateScope, store, globalConf, apiLimit, dryRun) {
+			if l.limitRedis(r, currentSession, key, rateScope, store, globalConf, apiLimit, dryRun) {
 				return sessionFailRateLimit
 			}
 		} else {
@@ -236,7 +396,7 @@ func (l *SessionLimiter) ForwardMessage(r *http.Request, currentSession *user.Se
 					return sessionFailRateLimit
 				}
 			} else {
-				if l.limitRedis(currentSession, key, rateScope, store, globalConf, apiLimit, dryRun) {
+				if l.limitRedis(r, currentSession, key, rateScope, store, globalConf, apiLimit, dryRun) {
 					return sessionFailRateLimit
 				}
 			}
@@ -274,10 +434,24 @@ func (l *SessionLimiter) RedisQuotaExceeded(r *http.Request, currentSession *use
 	quotaRenews := limit.QuotaRenews
 	quotaMax := limit.QuotaMax
 
+	// A hook or policy can set quota_renews to an absolute epoch (rather than
+	// relying on quota_renewal_rate alone) to align the next reset to a
+	// billing boundary, e.g. midnight on the 1st of the month, instead of
+	// "quotaRenewalRate seconds from whenever the bucket happens to fill
+	// first". Only honoured while that boundary is still in the future - once
+	// it's passed, this falls back to the regular rolling quotaRenewalRate
+	// cadence below.
+	ttl := quotaRenewalRate
+	if quotaRenews > 0 {
+		if untilRenewal := quotaRenews - time.Now().Unix(); untilRenewal > 0 {
+			ttl = untilRenewal
+		}
+	}
+
 	log.Debug("[QUOTA] Quota limiter key is: ", rawKey)
-	log.Debug("Renewing with TTL: ", quotaRenewalRate)
+	log.Debug("Renewing with TTL: ", ttl)
 	// INCR the key (If it equals 1 - set EXPIRE)
-	qInt := store.IncrememntWithExpire(rawKey, quotaRenewalRate)
+	qInt := store.IncrememntWithExpire(rawKey, ttl)
 	// if the returned val is >= quota: block
 	if qInt-1 >= quotaMax {
 		renewalDate := time.Unix(quotaRenews, 0)
@@ -305,7 +479,7 @@ func (l *SessionLimiter) RedisQuotaExceeded(r *http.Request, currentSession *use
 
 	// If this is a new Quota period, ensure we let the end user know
 	if qInt == 1 {
-		quotaRenews = time.Now().Unix() + quotaRenewalRate
+		quotaRenews = time.Now().Unix() + ttl
 		ctxScheduleSessionUpdate(r)
 	}
 
@@ -334,3 +508,56 @@ func (l *SessionLimiter) RedisQuotaExceeded(r *http.Request, currentSession *use
 
 	return false
 }
+
+// ApplyQuotaCost adjusts a session's quota for the current request when a
+// post hook has declared a cost other than the gateway's default of 1 (see
+// tyk.set_quota_cost() / ReturnOverrides.QuotaCost), on top of the decrement
+// RedisQuotaExceeded already applied. Each unit of the adjustment is its own
+// atomic INCR/DECR against the same quota key, so the net effect is correct
+// even though it isn't a single round trip. Returns true if rejectOnOverflow
+// is set and applying cost would take the session's quota negative; in that
+// case no adjustment is made and the caller should fail the request.
+func (l *SessionLimiter) ApplyQuotaCost(currentSession *user.SessionState, apiID string, cost int64, store storage.Handler, rejectOnOverflow bool) bool {
+	if cost <= 1 {
+		return false
+	}
+
+	var apiLimit *user.APILimit
+	var allowanceScope string
+	if rights, ok := currentSession.AccessRights[apiID]; ok {
+		apiLimit = rights.Limit
+		allowanceScope = rights.AllowanceScope
+	}
+	if apiLimit == nil {
+		apiLimit = &user.APILimit{
+			QuotaMax:         currentSession.QuotaMax,
+			QuotaRenewalRate: currentSession.QuotaRenewalRate,
+		}
+	}
+	if apiLimit.QuotaMax == -1 || apiLimit.QuotaMax == 0 {
+		// No quota set, nothing to adjust
+		return false
+	}
+
+	quotaScope := ""
+	if allowanceScope != "" {
+		quotaScope = allowanceScope + "-"
+	}
+	rawKey := QuotaKeyPrefix + quotaScope + currentSession.KeyHash()
+
+	extra := cost - 1
+
+	if rejectOnOverflow {
+		rawVal, _ := store.GetKey(rawKey)
+		used, _ := strconv.ParseInt(rawVal, 10, 64)
+		if used+extra > apiLimit.QuotaMax {
+			return true
+		}
+	}
+
+	for i := int64(0); i < extra; i++ {
+		store.IncrememntWithExpire(rawKey, apiLimit.QuotaRenewalRate)
+	}
+
+	return false
+}
@@ -0,0 +1,110 @@
+package gateway
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/TykTechnologies/tyk/config"
+)
+
+func TestSigv4CanonicalRequest(t *testing.T) {
+	u, err := url.Parse("https://example.amazonaws.com/resource?b=2&a=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	headers := map[string]string{
+		"Host":       "example.amazonaws.com",
+		"X-Amz-Date": "20200101T000000Z",
+	}
+	bodyHash := sigv4Hash([]byte("payload"))
+
+	canonicalRequest, signedHeaders := sigv4CanonicalRequest("GET", u, headers, bodyHash)
+
+	if signedHeaders != "host;x-amz-date" {
+		t.Fatalf("expected headers to be lowercased and sorted, got %q", signedHeaders)
+	}
+
+	wantQuery := "a=1&b=2"
+	if !strings.Contains(canonicalRequest, wantQuery) {
+		t.Fatalf("expected canonical request to re-sort query params, got %q", canonicalRequest)
+	}
+
+	wantHeaderBlock := "host:example.amazonaws.com\nx-amz-date:20200101T000000Z\n"
+	if !strings.Contains(canonicalRequest, "host:example.amazonaws.com") {
+		t.Fatalf("expected canonical headers block in request, got %q (wanted something like %q)", canonicalRequest, wantHeaderBlock)
+	}
+
+	if !strings.HasSuffix(canonicalRequest, bodyHash) {
+		t.Fatalf("expected canonical request to end with the body hash, got %q", canonicalRequest)
+	}
+}
+
+func TestSigv4CanonicalRequest_EmptyPathDefaultsToSlash(t *testing.T) {
+	u, _ := url.Parse("https://example.amazonaws.com")
+	canonicalRequest, _ := sigv4CanonicalRequest("GET", u, map[string]string{"Host": "example.amazonaws.com"}, sigv4Hash(nil))
+
+	lines := strings.Split(canonicalRequest, "\n")
+	if lines[1] != "/" {
+		t.Fatalf("expected canonical URI to default to '/', got %q", lines[1])
+	}
+}
+
+func TestSigv4Sign(t *testing.T) {
+	defer ResetTestConfig()
+	globalConf := config.Global()
+	globalConf.PluginSecretsAllowlist = []string{"secrets://aws-creds"}
+	globalConf.Secrets = map[string]string{
+		"aws-creds": `{"access_key_id":"AKIDEXAMPLE","secret_access_key":"wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY"}`,
+	}
+	config.SetGlobal(globalConf)
+
+	headers, err := sigv4Sign("GET", "https://example.amazonaws.com/resource", nil, []byte("payload"), "secrets://aws-creds", "us-east-1", "execute-api")
+	if err != nil {
+		t.Fatalf("sigv4Sign returned an error: %s", err.Error())
+	}
+
+	for _, key := range []string{"Host", "X-Amz-Date", "X-Amz-Content-Sha256", "Authorization"} {
+		if headers[key] == "" {
+			t.Fatalf("expected %s to be set, got headers=%v", key, headers)
+		}
+	}
+
+	if !strings.HasPrefix(headers["Authorization"], "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") {
+		t.Fatalf("unexpected Authorization header: %s", headers["Authorization"])
+	}
+	if headers["X-Amz-Content-Sha256"] != sigv4Hash([]byte("payload")) {
+		t.Fatalf("expected X-Amz-Content-Sha256 to be the body hash")
+	}
+}
+
+func TestSigv4Sign_SessionToken(t *testing.T) {
+	defer ResetTestConfig()
+	globalConf := config.Global()
+	globalConf.PluginSecretsAllowlist = []string{"secrets://aws-sts-creds"}
+	globalConf.Secrets = map[string]string{
+		"aws-sts-creds": `{"access_key_id":"AKIDEXAMPLE","secret_access_key":"secret","session_token":"a-session-token"}`,
+	}
+	config.SetGlobal(globalConf)
+
+	headers, err := sigv4Sign("GET", "https://example.amazonaws.com/", nil, nil, "secrets://aws-sts-creds", "us-east-1", "execute-api")
+	if err != nil {
+		t.Fatalf("sigv4Sign returned an error: %s", err.Error())
+	}
+	if headers["X-Amz-Security-Token"] != "a-session-token" {
+		t.Fatalf("expected X-Amz-Security-Token to be forwarded, got %q", headers["X-Amz-Security-Token"])
+	}
+}
+
+func TestSigv4Sign_UnknownCredentialsRef(t *testing.T) {
+	defer ResetTestConfig()
+	globalConf := config.Global()
+	globalConf.PluginSecretsAllowlist = nil
+	config.SetGlobal(globalConf)
+
+	_, err := sigv4Sign("GET", "https://example.amazonaws.com/", nil, nil, "secrets://not-allowed", "us-east-1", "execute-api")
+	if err != errSigV4UnknownCredentialsRef {
+		t.Fatalf("expected errSigV4UnknownCredentialsRef, got %v", err)
+	}
+}
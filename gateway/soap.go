@@ -0,0 +1,109 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+)
+
+// soapEnvelope models just enough of a SOAP 1.1/1.2 envelope to hand a
+// plugin the contents of its Body, without the gateway needing to know the
+// upstream's concrete payload schema. InnerXML preserves the original body
+// content byte-for-byte (including any namespace declarations it carries),
+// since this isn't a full SOAP client - it only needs to pass whatever's
+// inside <Body> through to the REST side unchanged.
+type soapEnvelope struct {
+	Body struct {
+		InnerXML []byte     `xml:",innerxml"`
+		Fault    *soapFault `xml:"Fault"`
+	} `xml:"Body"`
+}
+
+// soapFault is a SOAP 1.1-style fault. encoding/xml matches struct tags by
+// local name regardless of namespace prefix, so this also picks up SOAP 1.2
+// faults (Code/Reason) close enough for the common case - it just won't
+// populate Code/String for those, leaving a fault that's still detected but
+// reported with an empty code.
+type soapFault struct {
+	Code   string `xml:"faultcode"`
+	String string `xml:"faultstring"`
+	Detail string `xml:"detail"`
+}
+
+func (f *soapFault) Error() string {
+	return fmt.Sprintf("SOAP fault %s: %s", f.Code, f.String)
+}
+
+// soapFaultHTTPStatus maps a SOAP 1.1 fault code's local part to the HTTP
+// status a response hook would typically want to surface it as. A code with
+// no namespace prefix is matched the same way. Unknown codes default to 500,
+// since a fault always means the upstream
+// failed to process the request as intended.
+func soapFaultHTTPStatus(code string) int {
+	switch localName(code) {
+	case "VersionMismatch", "MustUnderstand":
+		return http.StatusBadGateway
+	case "Client":
+		return http.StatusBadRequest
+	case "Server":
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func localName(qname string) string {
+	if i := strings.LastIndex(qname, ":"); i >= 0 {
+		return qname[i+1:]
+	}
+	return qname
+}
+
+// restToSOAPTemplateData is exposed to a rest_to_soap template as ".". Kept
+// as a named type, rather than passing the body string directly, so the
+// template can grow more fields later without breaking existing templates.
+type restToSOAPTemplateData struct {
+	Body string
+}
+
+// restToSOAP wraps body in a SOAP envelope using tmpl, a Go text/template
+// whose "." is a restToSOAPTemplateData, typically something like:
+//
+//	<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+//	  <soap:Body>{{.Body}}</soap:Body>
+//	</soap:Envelope>
+//
+// letting the caller control namespaces and any header elements the
+// upstream expects, rather than the gateway guessing at a schema.
+func restToSOAP(body, tmpl string) (string, error) {
+	t, err := template.New("rest_to_soap").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, restToSOAPTemplateData{Body: body}); err != nil {
+		return "", fmt.Errorf("couldn't render template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// soapToREST unwraps a SOAP envelope's Body, returning its inner content
+// as-is (namespaces and all) for use as a REST body. If the Body contains a
+// Fault, err wraps a *soapFault instead - callers can type-assert it (see
+// TykSOAPToREST) to map it to an HTTP status via soapFaultHTTPStatus.
+func soapToREST(body string) (string, error) {
+	var env soapEnvelope
+	if err := xml.Unmarshal([]byte(body), &env); err != nil {
+		return "", fmt.Errorf("invalid SOAP envelope: %w", err)
+	}
+
+	if env.Body.Fault != nil {
+		return "", env.Body.Fault
+	}
+
+	return strings.TrimSpace(string(env.Body.InnerXML)), nil
+}
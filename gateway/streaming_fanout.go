@@ -0,0 +1,86 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// StreamingFanoutHandler handles streaming fan-out requests on
+// /tyk/stream-batch for any API Definition that has the feature enabled.
+// It shares BatchRequestHandler's request decoding/construction/execution,
+// but rather than collecting every upstream's reply before responding, it
+// writes each BatchReplyUnit as a newline-delimited JSON object the moment
+// its own upstream call completes, flushing after every line - so a
+// dashboard aggregating several slow upstreams can render results
+// incrementally instead of waiting for the slowest one.
+type StreamingFanoutHandler struct {
+	API *APISpec
+}
+
+// HandleStreamingFanout is the http handler for a streaming fan-out request
+// on an API definition.
+func (s *StreamingFanoutHandler) HandleStreamingFanout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		return
+	}
+
+	batcher := &BatchRequestHandler{API: s.API}
+
+	batchRequest, err := batcher.DecodeBatchRequest(r)
+	if err != nil {
+		log.Error("Could not decode streaming fan-out request, decoding failed: ", err)
+		doJSONWrite(w, http.StatusBadRequest, apiError("Batch request malformed"))
+		return
+	}
+
+	requestSet, err := batcher.ConstructRequests(batchRequest, false)
+	if err != nil {
+		doJSONWrite(w, http.StatusBadRequest, apiError("Batch request creation failed, request structure malformed"))
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		doJSONWrite(w, http.StatusInternalServerError, apiError("Streaming unsupported by the current transport"))
+		return
+	}
+
+	// Cancelling ctx on return, whether from the client disconnecting or from
+	// us giving up early on a write failure, stops any upstream calls that
+	// are still in flight - doRequest's underlying http.Client.Do respects
+	// the request context it's given.
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	replies := make(chan BatchReplyUnit)
+	for i, req := range requestSet {
+		go func(i int, req *http.Request) {
+			reply := batcher.doRequest(req.WithContext(ctx), batchRequest.Requests[i].RelativeURL)
+			select {
+			case replies <- reply:
+			case <-ctx.Done():
+			}
+		}(i, req)
+	}
+
+	enc := json.NewEncoder(w)
+	for range requestSet {
+		select {
+		case reply := <-replies:
+			if err := enc.Encode(reply); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-ctx.Done():
+			// Client disconnected; the deferred cancel() above has already
+			// signalled the still-running goroutines above to stop waiting
+			// to send, and their in-flight requests will fail as soon as the
+			// context cancellation propagates to the HTTP transport.
+			return
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package gateway
+
+import (
+	"bufio"
+	"encoding/json"
+	"testing"
+
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestStreamingFanout(t *testing.T) {
+	ts := StartTest()
+	defer ts.Close()
+
+	BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/v1/"
+		spec.EnableStreamingFanoutSupport = true
+	})
+
+	ts.Run(t, []test.TestCase{
+		{Method: "POST", Path: "/v1/tyk/stream-batch/", Data: `{"requests":[]}`, Code: 200},
+		{Method: "POST", Path: "/v1/tyk/stream-batch/", Data: "malformed", Code: 400},
+	}...)
+
+	resp, err := ts.Do(test.TestCase{Method: "POST", Path: "/v1/tyk/stream-batch/", Data: testBatchRequest})
+	if err != nil {
+		t.Fatalf("request failed: %s", err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.Header.Get("Content-Type") != "application/x-ndjson" {
+		t.Errorf("expected an application/x-ndjson content type, got %q", resp.Header.Get("Content-Type"))
+	}
+
+	var lines int
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var reply BatchReplyUnit
+		if err := json.Unmarshal(scanner.Bytes(), &reply); err != nil {
+			t.Fatalf("couldn't decode streamed line as a BatchReplyUnit: %s", err.Error())
+		}
+		lines++
+	}
+
+	if lines != 3 {
+		t.Errorf("expected 3 streamed replies, got %d", lines)
+	}
+}
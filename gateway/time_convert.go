@@ -0,0 +1,105 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeConvertNamedLayouts maps the common format names hooks are likely to
+// reach for onto their Go time layouts, so a hook doesn't need to know (or
+// escape) the reference-time syntax for everyday formats. Anything not in
+// this map is treated as a literal Go layout string, so a hook can still
+// supply a fully custom one (e.g. "2006-01-02 15:04:05").
+var timeConvertNamedLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"ANSIC":       time.ANSIC,
+	"Kitchen":     time.Kitchen,
+	"DateOnly":    "2006-01-02",
+	"TimeOnly":    "15:04:05",
+}
+
+// timeConvertUnixFormats are the special-cased "format" values that mean
+// "this value is a Unix timestamp" rather than a layout string, for both
+// parsing and formatting.
+var timeConvertUnixFormats = map[string]bool{
+	"unix":       true,
+	"epoch":      true,
+	"unix_milli": true,
+	"unix_nano":  true,
+}
+
+// timeConvert parses value according to fromFormat, optionally shifts it
+// into tz (an IANA zone name; empty leaves it in whatever zone parsing
+// produced), and re-renders it according to toFormat. fromFormat/toFormat
+// are each either one of timeConvertNamedLayouts' keys, one of
+// timeConvertUnixFormats, or a literal Go reference-time layout.
+func timeConvert(value, fromFormat, toFormat, tz string) (string, error) {
+	t, err := parseTimeValue(value, fromFormat)
+	if err != nil {
+		return "", fmt.Errorf("couldn't parse %q as %q: %s", value, fromFormat, err.Error())
+	}
+
+	if tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return "", fmt.Errorf("invalid timezone %q: %s", tz, err.Error())
+		}
+		t = t.In(loc)
+	}
+
+	return formatTimeValue(t, toFormat)
+}
+
+func parseTimeValue(value, format string) (time.Time, error) {
+	if timeConvertUnixFormats[strings.ToLower(format)] {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, errors.New("not a valid integer Unix timestamp")
+		}
+		switch strings.ToLower(format) {
+		case "unix_milli":
+			return time.UnixMilli(n).UTC(), nil
+		case "unix_nano":
+			return time.Unix(0, n).UTC(), nil
+		default:
+			return time.Unix(n, 0).UTC(), nil
+		}
+	}
+
+	layout, ok := timeConvertNamedLayouts[format]
+	if !ok {
+		layout = format
+	}
+
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t, nil
+}
+
+func formatTimeValue(t time.Time, format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "unix", "epoch":
+		return strconv.FormatInt(t.Unix(), 10), nil
+	case "unix_milli":
+		return strconv.FormatInt(t.UnixMilli(), 10), nil
+	case "unix_nano":
+		return strconv.FormatInt(t.UnixNano(), 10), nil
+	}
+
+	layout, ok := timeConvertNamedLayouts[format]
+	if !ok {
+		layout = format
+	}
+
+	return t.Format(layout), nil
+}
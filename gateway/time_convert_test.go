@@ -0,0 +1,47 @@
+package gateway
+
+import "testing"
+
+func TestTimeConvert(t *testing.T) {
+	t.Run("RFC3339 to unix", func(t *testing.T) {
+		got, err := timeConvert("2024-01-02T15:04:05Z", "RFC3339", "unix", "")
+		if err != nil {
+			t.Fatalf("timeConvert returned an error: %s", err.Error())
+		}
+		if got != "1704207845" {
+			t.Errorf("expected 1704207845, got %s", got)
+		}
+	})
+
+	t.Run("unix to RFC3339 with timezone shift", func(t *testing.T) {
+		got, err := timeConvert("1704207845", "unix", "RFC3339", "America/New_York")
+		if err != nil {
+			t.Fatalf("timeConvert returned an error: %s", err.Error())
+		}
+		if got != "2024-01-02T10:04:05-05:00" {
+			t.Errorf("expected 2024-01-02T10:04:05-05:00, got %s", got)
+		}
+	})
+
+	t.Run("custom layout to custom layout", func(t *testing.T) {
+		got, err := timeConvert("02/01/2024", "02/01/2006", "2006-01-02", "")
+		if err != nil {
+			t.Fatalf("timeConvert returned an error: %s", err.Error())
+		}
+		if got != "2024-01-02" {
+			t.Errorf("expected 2024-01-02, got %s", got)
+		}
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		if _, err := timeConvert("not-a-time", "RFC3339", "unix", ""); err == nil {
+			t.Fatal("expected an error for an unparseable value")
+		}
+	})
+
+	t.Run("invalid timezone", func(t *testing.T) {
+		if _, err := timeConvert("2024-01-02T15:04:05Z", "RFC3339", "RFC3339", "Not/AZone"); err == nil {
+			t.Fatal("expected an error for an invalid timezone")
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package gateway
+
+import (
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+// upstreamAffinityTTL bounds how long a pinned upstream is remembered for a
+// given affinity key, so a key that falls out of use eventually stops
+// holding a backend pinned for no reason.
+const upstreamAffinityTTL = 3600
+
+// upstreamAffinityStore holds session->upstream pins (see
+// ReturnOverrides.AffinityKey) in the gateway's distributed store, so every
+// node in the cluster honours the same pin rather than each picking its own.
+var upstreamAffinityStore storage.Handler = &storage.RedisCluster{KeyPrefix: "lb-affinity-"}
+
+func affinityStorageKey(apiID, affinityKey string) string {
+	return apiID + "-" + affinityKey
+}
+
+// getUpstreamAffinity returns the upstream host pinned for affinityKey on
+// this API, or "" if there's no pin.
+func getUpstreamAffinity(apiID, affinityKey string) string {
+	if affinityKey == "" {
+		return ""
+	}
+	host, err := upstreamAffinityStore.GetKey(affinityStorageKey(apiID, affinityKey))
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
+// setUpstreamAffinity pins affinityKey to host for this API, so subsequent
+// requests carrying the same key land on the same upstream instance.
+func setUpstreamAffinity(apiID, affinityKey, host string) {
+	if affinityKey == "" {
+		return
+	}
+	upstreamAffinityStore.SetKey(affinityStorageKey(apiID, affinityKey), host, upstreamAffinityTTL)
+}
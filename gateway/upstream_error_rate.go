@@ -0,0 +1,78 @@
+package gateway
+
+import (
+	"sync"
+	"time"
+)
+
+// errorRateWindow is the sliding window the hook-exposed upstream error
+// rate is computed over: long enough to smooth out a single failed
+// request, short enough that a hook reacts to a real outage promptly.
+const errorRateWindow = 1 * time.Minute
+
+// errorRateMinSamples is the minimum number of responses the window needs
+// before Rate reports anything other than 0, so a cold API with a couple
+// of requests can't look either perfectly healthy or completely broken.
+const errorRateMinSamples = 5
+
+type errorRateSample struct {
+	at      time.Time
+	isError bool
+}
+
+// upstreamErrorRateTracker is a per-API sliding-window error counter. It's
+// updated once per proxied response, so Record is kept cheap: appending a
+// sample and evicting anything that's fallen outside the window.
+type upstreamErrorRateTracker struct {
+	mu      sync.Mutex
+	samples []errorRateSample
+}
+
+// Record adds a single upstream response outcome to the window. A nil
+// receiver is a no-op, so callers don't need to special-case an APISpec
+// that was built without going through MakeSpec (e.g. in tests).
+func (t *upstreamErrorRateTracker) Record(isError bool) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.samples = append(t.samples, errorRateSample{at: now, isError: isError})
+	t.evict(now)
+}
+
+// evict drops samples that have aged out of errorRateWindow. Must be
+// called with t.mu held.
+func (t *upstreamErrorRateTracker) evict(now time.Time) {
+	cutoff := now.Add(-errorRateWindow)
+	i := 0
+	for i < len(t.samples) && t.samples[i].at.Before(cutoff) {
+		i++
+	}
+	if i > 0 {
+		t.samples = t.samples[i:]
+	}
+}
+
+// Rate returns the fraction of responses recorded within errorRateWindow
+// that were errors, or 0 if fewer than errorRateMinSamples have landed (or
+// the receiver is nil, as with an APISpec built without MakeSpec).
+func (t *upstreamErrorRateTracker) Rate() float64 {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.evict(time.Now())
+	if len(t.samples) < errorRateMinSamples {
+		return 0
+	}
+	var errs int
+	for _, s := range t.samples {
+		if s.isError {
+			errs++
+		}
+	}
+	return float64(errs) / float64(len(t.samples))
+}
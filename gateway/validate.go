@@ -0,0 +1,75 @@
+package gateway
+
+import (
+	"net/mail"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/regexp"
+)
+
+// phoneRegionPrefixes maps a handful of common ISO 3166-1 alpha-2 region
+// codes to their E.164 calling code, for normalizing a phone number that
+// wasn't already given in international form. This is intentionally a small,
+// hand-maintained table rather than a full numbering-plan implementation -
+// there's no vendored phone-number library available to validate area codes,
+// number lengths per region, etc., so validatePhone only checks shape, not
+// that the number is actually assigned.
+var phoneRegionPrefixes = map[string]string{
+	"US": "1", "CA": "1", "GB": "44", "DE": "49", "FR": "33",
+	"ES": "34", "IT": "39", "AU": "61", "IN": "91", "JP": "81",
+	"BR": "55", "MX": "52", "NL": "31", "SE": "46", "CN": "86",
+}
+
+var phoneDigitsOnly = regexp.MustCompile(`[^0-9+]`)
+
+// emailValidationResult and phoneValidationResult are the structured results
+// a hook gets back from validate_email/validate_phone, returned as JSON
+// since the CoProcess API's cgo boundary only carries strings.
+type emailValidationResult struct {
+	Valid      bool   `json:"valid"`
+	Normalized string `json:"normalized"`
+}
+
+type phoneValidationResult struct {
+	Valid      bool   `json:"valid"`
+	Normalized string `json:"normalized"`
+}
+
+// validateEmail checks s is a single RFC 5322 address (no display name
+// required, but one is tolerated and stripped) and normalizes it to a
+// lowercase address with no surrounding whitespace.
+func validateEmail(s string) emailValidationResult {
+	addr, err := mail.ParseAddress(strings.TrimSpace(s))
+	if err != nil {
+		return emailValidationResult{}
+	}
+	return emailValidationResult{Valid: true, Normalized: strings.ToLower(addr.Address)}
+}
+
+// validatePhone normalizes s to E.164 form and reports whether it looks like
+// a plausible phone number: 8-15 digits after a leading "+". A number not
+// already in international form is qualified using region's calling code,
+// looked up in phoneRegionPrefixes; an unrecognised region with no leading
+// "+" on the number is invalid.
+func validatePhone(s, region string) phoneValidationResult {
+	cleaned := phoneDigitsOnly.ReplaceAllString(strings.TrimSpace(s), "")
+	if cleaned == "" {
+		return phoneValidationResult{}
+	}
+
+	if !strings.HasPrefix(cleaned, "+") {
+		prefix, ok := phoneRegionPrefixes[strings.ToUpper(region)]
+		if !ok {
+			return phoneValidationResult{}
+		}
+		cleaned = strings.TrimPrefix(cleaned, "0")
+		cleaned = "+" + prefix + cleaned
+	}
+
+	digits := cleaned[1:]
+	if len(digits) < 8 || len(digits) > 15 {
+		return phoneValidationResult{}
+	}
+
+	return phoneValidationResult{Valid: true, Normalized: cleaned}
+}
@@ -29,3 +29,26 @@ func GetHandler(path string, symbol string) (http.HandlerFunc, error) {
 
 	return pluginHandler, nil
 }
+
+// ScopeValidatorFunc is the symbol signature a plugin must export to be
+// loaded by GetScopeValidator.
+type ScopeValidatorFunc func(required, presented []string) (allowed bool, err error)
+
+func GetScopeValidator(path string, symbol string) (ScopeValidatorFunc, error) {
+	loadedPlugin, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	funcSymbol, err := loadedPlugin.Lookup(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	validator, ok := funcSymbol.(func(required, presented []string) (bool, error))
+	if !ok {
+		return nil, errors.New("could not cast function symbol to a scope validator func")
+	}
+
+	return validator, nil
+}
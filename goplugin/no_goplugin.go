@@ -10,3 +10,11 @@ import (
 func GetHandler(path string, symbol string) (http.HandlerFunc, error) {
 	return nil, fmt.Errorf("goplugin.GetHandler is disabled, please disable build flag 'nogoplugin'")
 }
+
+// ScopeValidatorFunc is the symbol signature a plugin must export to be
+// loaded by GetScopeValidator.
+type ScopeValidatorFunc func(required, presented []string) (allowed bool, err error)
+
+func GetScopeValidator(path string, symbol string) (ScopeValidatorFunc, error) {
+	return nil, fmt.Errorf("goplugin.GetScopeValidator is disabled, please disable build flag 'nogoplugin'")
+}
@@ -0,0 +1,328 @@
+package storage
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cache "github.com/pmylund/go-cache"
+)
+
+// ------------------- IN-MEMORY STORAGE MANAGER -------------------------------
+
+// MemoryCache is a Handler implementation that keeps everything in the
+// process' own memory instead of talking to Redis. It exists for callers
+// that want a per-node cache (e.g. response caching where cross-node
+// consistency isn't required) without paying the network round-trip to
+// Redis. It is not shared across gateway nodes, so anything that needs to
+// be visible cluster-wide must keep using RedisCluster.
+type MemoryCache struct {
+	KeyPrefix string
+
+	once  sync.Once
+	cache *cache.Cache
+
+	setsMu sync.Mutex
+	sets   map[string]map[string]bool
+}
+
+func (m *MemoryCache) init() {
+	m.once.Do(func() {
+		m.cache = cache.New(cache.NoExpiration, time.Minute)
+		m.sets = make(map[string]map[string]bool)
+	})
+}
+
+// Connect is a no-op, kept to satisfy the Handler interface.
+func (m *MemoryCache) Connect() bool {
+	m.init()
+	return true
+}
+
+func (m *MemoryCache) GetKeyPrefix() string {
+	return m.KeyPrefix
+}
+
+func (m *MemoryCache) GetKey(key string) (string, error) {
+	return m.GetRawKey(m.fixKey(key))
+}
+
+func (m *MemoryCache) GetRawKey(key string) (string, error) {
+	m.init()
+	v, found := m.cache.Get(key)
+	if !found {
+		return "", ErrKeyNotFound
+	}
+	return v.(string), nil
+}
+
+// GetMultiKey returns one entry per requested key, in the same order, with
+// "" standing in for a miss - matching RedisCluster.GetMultiKey's contract,
+// which callers rely on to index the result positionally against keys. An
+// error is only returned when every key misses.
+func (m *MemoryCache) GetMultiKey(keys []string) ([]string, error) {
+	result := make([]string, len(keys))
+	found := false
+	for i, key := range keys {
+		if v, err := m.GetKey(key); err == nil {
+			result[i] = v
+			found = true
+		}
+	}
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+	return result, nil
+}
+
+func (m *MemoryCache) SetKey(key, value string, ttl int64) error {
+	return m.SetRawKey(m.fixKey(key), value, ttl)
+}
+
+func (m *MemoryCache) SetRawKey(key, value string, ttl int64) error {
+	m.init()
+	exp := cache.NoExpiration
+	if ttl > 0 {
+		exp = time.Duration(ttl) * time.Second
+	}
+	m.cache.Set(key, value, exp)
+	return nil
+}
+
+func (m *MemoryCache) SetExp(key string, ttl int64) error {
+	m.init()
+	v, found := m.cache.Get(m.fixKey(key))
+	if !found {
+		return ErrKeyNotFound
+	}
+	m.cache.Set(m.fixKey(key), v, time.Duration(ttl)*time.Second)
+	return nil
+}
+
+func (m *MemoryCache) GetExp(key string) (int64, error) {
+	m.init()
+	_, exp, found := m.cache.GetWithExpiration(m.fixKey(key))
+	if !found {
+		return 0, ErrKeyNotFound
+	}
+	if exp.IsZero() {
+		return -1, nil
+	}
+	return exp.Unix(), nil
+}
+
+func (m *MemoryCache) GetKeys(filter string) []string {
+	m.init()
+	var keys []string
+	for k := range m.cache.Items() {
+		key := strings.TrimPrefix(k, m.KeyPrefix)
+		if filter == "" || strings.Contains(key, filter) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (m *MemoryCache) DeleteKey(key string) bool {
+	return m.DeleteRawKey(m.fixKey(key))
+}
+
+func (m *MemoryCache) DeleteRawKey(key string) bool {
+	m.init()
+	m.cache.Delete(key)
+	return true
+}
+
+func (m *MemoryCache) DeleteAllKeys() bool {
+	m.init()
+	m.cache.Flush()
+	return true
+}
+
+func (m *MemoryCache) DeleteKeys(keys []string) bool {
+	for _, key := range keys {
+		m.DeleteKey(key)
+	}
+	return true
+}
+
+func (m *MemoryCache) DeleteScanMatch(pattern string) bool {
+	m.init()
+	pattern = strings.TrimSuffix(strings.TrimPrefix(pattern, "*"), "*")
+	for k := range m.cache.Items() {
+		if pattern == "" || strings.Contains(k, pattern) {
+			m.cache.Delete(k)
+		}
+	}
+	return true
+}
+
+func (m *MemoryCache) GetKeysAndValues() map[string]string {
+	m.init()
+	out := make(map[string]string)
+	for k, v := range m.cache.Items() {
+		out[strings.TrimPrefix(k, m.KeyPrefix)] = v.Object.(string)
+	}
+	return out
+}
+
+func (m *MemoryCache) GetKeysAndValuesWithFilter(filter string) map[string]string {
+	out := make(map[string]string)
+	for k, v := range m.GetKeysAndValues() {
+		if filter == "" || strings.Contains(k, filter) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func (m *MemoryCache) Decrement(key string) {
+	m.init()
+	v, err := m.GetRawKey(m.fixKey(key))
+	n := 0
+	if err == nil {
+		if parsed, convErr := strconv.Atoi(v); convErr == nil {
+			n = parsed
+		}
+	}
+	n--
+	m.SetRawKey(m.fixKey(key), strconv.Itoa(n), 0)
+}
+
+func (m *MemoryCache) IncrememntWithExpire(key string, ttl int64) int64 {
+	m.init()
+	v, err := m.GetRawKey(m.fixKey(key))
+	n := 0
+	if err == nil {
+		if parsed, convErr := strconv.Atoi(v); convErr == nil {
+			n = parsed
+		}
+	}
+	n++
+	m.SetRawKey(m.fixKey(key), strconv.Itoa(n), ttl)
+	return int64(n)
+}
+
+// SetRollingWindow and GetRollingWindow aren't meaningful without a shared
+// clock/store across nodes, so MemoryCache reports an always-empty window;
+// callers relying on precise rate limiting should use RedisCluster instead.
+func (m *MemoryCache) SetRollingWindow(key string, per int64, val string, pipeline bool) (int, []interface{}) {
+	return 0, nil
+}
+
+func (m *MemoryCache) GetRollingWindow(key string, per int64, pipeline bool) (int, []interface{}) {
+	return 0, nil
+}
+
+func (m *MemoryCache) GetSet(key string) (map[string]string, error) {
+	m.init()
+	m.setsMu.Lock()
+	defer m.setsMu.Unlock()
+	set, ok := m.sets[m.fixKey(key)]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	out := make(map[string]string, len(set))
+	for member := range set {
+		out[member] = member
+	}
+	return out, nil
+}
+
+func (m *MemoryCache) AddToSet(key, value string) {
+	m.init()
+	m.setsMu.Lock()
+	defer m.setsMu.Unlock()
+	key = m.fixKey(key)
+	if m.sets[key] == nil {
+		m.sets[key] = make(map[string]bool)
+	}
+	m.sets[key][value] = true
+}
+
+func (m *MemoryCache) AppendToSet(key, value string) {
+	m.AddToSet(key, value)
+}
+
+func (m *MemoryCache) GetAndDeleteSet(key string) []interface{} {
+	m.init()
+	m.setsMu.Lock()
+	defer m.setsMu.Unlock()
+	key = m.fixKey(key)
+	set := m.sets[key]
+	delete(m.sets, key)
+	out := make([]interface{}, 0, len(set))
+	for member := range set {
+		out = append(out, member)
+	}
+	return out
+}
+
+func (m *MemoryCache) RemoveFromSet(key, value string) {
+	m.init()
+	m.setsMu.Lock()
+	defer m.setsMu.Unlock()
+	key = m.fixKey(key)
+	delete(m.sets[key], value)
+}
+
+// AddToSortedSet, GetSortedSetRange and RemoveSortedSetRange are satisfied
+// using the same unordered set storage as AddToSet: members are kept but
+// their score isn't, since nothing in this codebase reads scores back from
+// a per-node cache. Scored ordering needs RedisCluster.
+func (m *MemoryCache) AddToSortedSet(key, value string, score float64) {
+	m.AddToSet(key, value)
+}
+
+func (m *MemoryCache) GetSortedSetRange(key, scoreFrom, scoreTo string) ([]string, []float64, error) {
+	set, err := m.GetSet(key)
+	if err != nil {
+		return nil, nil, nil
+	}
+	members := make([]string, 0, len(set))
+	for member := range set {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	scores := make([]float64, len(members))
+	return members, scores, nil
+}
+
+func (m *MemoryCache) RemoveSortedSetRange(key, scoreFrom, scoreTo string) error {
+	return nil
+}
+
+func (m *MemoryCache) GetListRange(key string, from, to int64) ([]string, error) {
+	set, err := m.GetSet(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(set))
+	for member := range set {
+		out = append(out, member)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func (m *MemoryCache) RemoveFromList(key, value string) error {
+	m.RemoveFromSet(key, value)
+	return nil
+}
+
+func (m *MemoryCache) Exists(key string) (bool, error) {
+	_, err := m.GetKey(key)
+	if err == ErrKeyNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (m *MemoryCache) fixKey(key string) string {
+	return m.KeyPrefix + key
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMemoryCacheGetMultiKey(t *testing.T) {
+	m := &MemoryCache{KeyPrefix: "test-memory-"}
+
+	if _, err := m.GetMultiKey([]string{"first", "second"}); err != ErrKeyNotFound {
+		t.Fatalf("expected %v for an all-miss lookup, got %v", ErrKeyNotFound, err)
+	}
+
+	if err := m.SetKey("first", "first-value", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := m.GetMultiKey([]string{"first", "second", "third"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"first-value", "", ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected one result per requested key in order with \"\" for misses, got %v want %v", got, want)
+	}
+}
+
+func TestMemoryCacheGetMultiKeyAllFound(t *testing.T) {
+	m := &MemoryCache{KeyPrefix: "test-memory-"}
+	if err := m.SetKey("a", "1", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetKey("b", "2", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := m.GetMultiKey([]string{"b", "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"2", "1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+}
+
+func TestMemoryCacheSetGetDeleteKey(t *testing.T) {
+	m := &MemoryCache{KeyPrefix: "test-memory-"}
+
+	if err := m.SetKey("foo", "bar", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := m.GetKey("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "bar" {
+		t.Fatalf("expected bar, got %s", v)
+	}
+
+	if !m.DeleteKey("foo") {
+		t.Fatal("expected DeleteKey to report success")
+	}
+
+	if _, err := m.GetKey("foo"); err != ErrKeyNotFound {
+		t.Fatalf("expected %v after delete, got %v", ErrKeyNotFound, err)
+	}
+}
+
+func TestMemoryCacheExists(t *testing.T) {
+	m := &MemoryCache{KeyPrefix: "test-memory-"}
+
+	if ok, err := m.Exists("missing"); err != nil || ok {
+		t.Fatalf("expected (false, nil) for a missing key, got (%v, %v)", ok, err)
+	}
+
+	if err := m.SetKey("present", "v", 0); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := m.Exists("present"); err != nil || !ok {
+		t.Fatalf("expected (true, nil) for a present key, got (%v, %v)", ok, err)
+	}
+}
+
+func TestMemoryCacheSets(t *testing.T) {
+	m := &MemoryCache{KeyPrefix: "test-memory-"}
+
+	m.AddToSet("myset", "a")
+	m.AddToSet("myset", "b")
+
+	set, err := m.GetSet("myset")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set) != 2 || set["a"] != "a" || set["b"] != "b" {
+		t.Fatalf("unexpected set contents: %v", set)
+	}
+
+	m.RemoveFromSet("myset", "a")
+	set, err = m.GetSet("myset")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set) != 1 {
+		t.Fatalf("expected one member after removal, got %v", set)
+	}
+}
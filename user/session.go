@@ -100,6 +100,17 @@ type SessionState struct {
 	LastUpdated             string                 `json:"last_updated" msg:"last_updated"`
 	IdExtractorDeadline     int64                  `json:"id_extractor_deadline" msg:"id_extractor_deadline"`
 	SessionLifetime         int64                  `bson:"session_lifetime" json:"session_lifetime"`
+	// RateLimitAlgorithm overrides, for this session only, which rate-limit
+	// algorithm the gateway's SessionLimiter uses in place of the
+	// gateway-wide default (sentinel/rolling-window/DRL, selected by
+	// config.Config's EnableSentinelRateLimiter/EnableRedisRollingLimiter).
+	// Two values are recognised: "sliding_window", which enforces Rate
+	// requests per Per seconds with no burst allowance - unlike the DRL
+	// token-bucket default, which lets a session spend a burst of saved-up
+	// allowance all at once - and "gcra", which admits Rate requests per Per
+	// seconds by tracking a single theoretical arrival time per key. Empty
+	// uses the gateway-wide default.
+	RateLimitAlgorithm string `json:"rate_algorithm" msg:"rate_algorithm"`
 
 	// Used to store token hash
 	keyHash string